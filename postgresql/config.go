@@ -2,33 +2,76 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/errwrap"
-	_ "github.com/lib/pq" //PostgreSQL db
+	"github.com/lib/pq"
 )
 
 type featureName uint
 
 const (
-	featureCreateRoleWith featureName = iota
+	featureAlterSystem featureName = iota
+	featureApplicationName
+	featureCreateRoleWith
 	featureDBAllowConnections
+	featureDBDatLocale
 	featureDBIsTemplate
-	featureFallbackApplicationName
+	featureDBLocaleProvider
+	featureDefaultPrivilegesSchemas
+	featureDefaultPrivilegesTypes
+	featureExtensionCreateCascade
+	featureExtensionCreateFrom
+	featureExtensionCreateIfNotExists
+	featureExtensionMembers
+	featureFileSettings
+	featureGrantedBy
+	featureLockTimeout
+	featureMaintainPrivilege
+	featureMaterializedView
+	featureMembershipOptions
+	featurePublicSchemaCreate
 	featureRLS
+	featureRLSRestrictive
 	featureReassignOwnedCurrentUser
+	featureRefreshMatViewConcurrently
+	featureReplicationSlots
+	featureRoleBypassRLS
+	featureRoleReplication
+	featureRoutines
 	featureSchemaCreateIfNotExist
 )
 
+// serverFlavor is the kind of server the provider talks to: PostgreSQL or a
+// server speaking its protocol with some differences.
+type serverFlavor uint
+
+const (
+	flavorPostgreSQL serverFlavor = iota
+	// Amazon Redshift reports an ancient PostgreSQL version and lacks some
+	// of its catalogs and role attributes.
+	flavorRedshift
+	// CockroachDB has no pg_shadow, default privileges and extensions.
+	flavorCockroachDB
+)
+
 type dbRegistryEntry struct {
 	db      *sql.DB
 	version semver.Version
+	flavor  serverFlavor
+	// host and port of the server selected among the configured ones.
+	host string
+	port int
 }
 
 var (
@@ -37,17 +80,68 @@ var (
 
 	// Mapping of feature flags to versions
 	featureSupported = map[featureName]semver.Range{
+		// ALTER SYSTEM
+		featureAlterSystem: semver.MustParseRange(">=9.4.0"),
+
 		// CREATE ROLE WITH
 		featureCreateRoleWith: semver.MustParseRange(">=8.1.0"),
 
 		// CREATE DATABASE has ALLOW_CONNECTIONS support
 		featureDBAllowConnections: semver.MustParseRange(">=9.5.0"),
 
+		// pg_database.daticulocale is renamed datlocale
+		featureDBDatLocale: semver.MustParseRange(">=17.0.0"),
+
 		// CREATE DATABASE has IS_TEMPLATE support
 		featureDBIsTemplate: semver.MustParseRange(">=9.5.0"),
 
+		// CREATE DATABASE has LOCALE_PROVIDER and ICU_LOCALE support
+		featureDBLocaleProvider: semver.MustParseRange(">=15.0.0"),
+
+		// ALTER DEFAULT PRIVILEGES ... ON SCHEMAS
+		featureDefaultPrivilegesSchemas: semver.MustParseRange(">=10.0.0"),
+
+		// ALTER DEFAULT PRIVILEGES ... ON TYPES
+		featureDefaultPrivilegesTypes: semver.MustParseRange(">=9.2.0"),
+
+		// CREATE EXTENSION ... CASCADE
+		featureExtensionCreateCascade: semver.MustParseRange(">=9.6.0"),
+
+		// CREATE EXTENSION ... FROM old_version, removed in PostgreSQL 13
+		featureExtensionCreateFrom: semver.MustParseRange(">=9.1.0 <13.0.0"),
+
+		// pg_file_settings view and pg_settings.pending_restart
+		featureFileSettings: semver.MustParseRange(">=9.5.0"),
+
+		// CREATE EXTENSION IF NOT EXISTS
+		featureExtensionCreateIfNotExists: semver.MustParseRange(">=9.1.0"),
+
+		// ALTER EXTENSION ... ADD/DROP, with the objects resolved by the
+		// to_regclass, to_regprocedure and to_regtype functions
+		featureExtensionMembers: semver.MustParseRange(">=9.4.0"),
+
+		// GRANT ... GRANTED BY role
+		featureGrantedBy: semver.MustParseRange(">=14.0.0"),
+
+		// lock_timeout run-time parameter
+		featureLockTimeout: semver.MustParseRange(">=9.3.0"),
+
+		// MAINTAIN privilege on tables
+		featureMaintainPrivilege: semver.MustParseRange(">=17.0.0"),
+
+		// CREATE MATERIALIZED VIEW
+		featureMaterializedView: semver.MustParseRange(">=9.3.0"),
+
+		// GRANT role ... WITH INHERIT/SET
+		featureMembershipOptions: semver.MustParseRange(">=16.0.0"),
+
+		// PUBLIC has the CREATE privilege on the public schema by default,
+		// revoked in PostgreSQL 15
+		featurePublicSchemaCreate: semver.MustParseRange("<15.0.0"),
+
+		// application_name connection parameter
 		// https://www.postgresql.org/docs/9.0/static/libpq-connect.html
-		featureFallbackApplicationName: semver.MustParseRange(">=9.0.0"),
+		featureApplicationName: semver.MustParseRange(">=9.0.0"),
 
 		// CREATE SCHEMA IF NOT EXISTS
 		featureSchemaCreateIfNotExist: semver.MustParseRange(">=9.3.0"),
@@ -55,8 +149,27 @@ var (
 		// REASSIGN OWNED BY { old_role | CURRENT_USER
 		featureReassignOwnedCurrentUser: semver.MustParseRange(">=9.5.0"),
 
+		// REFRESH MATERIALIZED VIEW CONCURRENTLY
+		featureRefreshMatViewConcurrently: semver.MustParseRange(">=9.4.0"),
+
+		// pg_roles.rolbypassrls and the BYPASSRLS role attribute
+		featureRoleBypassRLS: semver.MustParseRange(">=9.5.0"),
+
+		// pg_roles.rolreplication and the REPLICATION role attribute
+		featureRoleReplication: semver.MustParseRange(">=9.1.0"),
+
+		// GRANT ... ON ROUTINE and ALL ROUTINES IN SCHEMA, which include the
+		// procedures
+		featureRoutines: semver.MustParseRange(">=11.0.0"),
+
+		// pg_create_physical_replication_slot and pg_create_logical_replication_slot
+		featureReplicationSlots: semver.MustParseRange(">=9.4.0"),
+
 		// row-level security
 		featureRLS: semver.MustParseRange(">=9.5.0"),
+
+		// CREATE POLICY ... AS RESTRICTIVE
+		featureRLSRestrictive: semver.MustParseRange(">=10.0.0"),
 	}
 )
 
@@ -72,6 +185,37 @@ type Config struct {
 	ConnectTimeoutSec int
 	MaxConns          int
 	ExpectedVersion   semver.Version
+	SearchPath        []string
+	// StatementTimeoutMs and LockTimeoutMs are nil to keep the server
+	// default, so that the zero value of Config does not disable the
+	// timeouts.
+	StatementTimeoutMs *int
+	LockTimeoutMs      *int
+	AssumeRole         string
+	// PgBouncerMode makes the connections usable through PgBouncer in
+	// transaction pooling mode.
+	PgBouncerMode bool
+	// IsolationLevel of the transactions, empty for the server default.
+	IsolationLevel string
+	// DefaultOwner of the objects created without an owner, empty for the
+	// connection user.
+	DefaultOwner string
+	// Hosts tried in order instead of Host, as host or host:port.
+	Hosts []string
+	// TargetSessionAttrs is read-write to only connect to a server
+	// accepting read-write transactions, i.e. a primary.
+	TargetSessionAttrs string
+}
+
+const (
+	targetSessionAttrsAny       = "any"
+	targetSessionAttrsReadWrite = "read-write"
+)
+
+// serverAddress is the host and port of a PostgreSQL server.
+type serverAddress struct {
+	host string
+	port int
 }
 
 // Client struct holding connection string
@@ -89,12 +233,18 @@ type Client struct {
 	// output of `SELECT VERSION()`.x
 	version semver.Version
 
+	// flavor of the server, as determined from the same output.
+	flavor serverFlavor
+
 	// PostgreSQL lock on pg_catalog.  Many of the operations that Terraform
 	// performs are not permitted to be concurrent.  Unlike traditional
 	// PostgreSQL tables that use MVCC, many of the PostgreSQL system
 	// catalogs look like tables, but are not in-fact able to be
 	// concurrently updated.
 	catalogLock sync.RWMutex
+
+	// stopCtx is done when Terraform stops the provider.
+	stopCtx context.Context
 }
 
 // NewClient returns client config for the specified database.
@@ -105,7 +255,12 @@ func (c *Config) NewClient(database string) (*Client, error) {
 	dsn := c.connStr(database)
 	dbEntry, found := dbRegistry[dsn]
 	if !found {
-		db, err := sql.Open("postgres", dsn)
+		server, err := c.selectServer(database)
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := openDB(server.connStr(database))
 		if err != nil {
 			return nil, errwrap.Wrapf("Error connecting to PostgreSQL server: {{err}}", err)
 		}
@@ -116,39 +271,201 @@ func (c *Config) NewClient(database string) (*Client, error) {
 		db.SetMaxIdleConns(0)
 		db.SetMaxOpenConns(c.MaxConns)
 
-		version, err := fingerprintCapabilities(db)
+		if err := server.ping(db); err != nil {
+			db.Close()
+			// Before PostgreSQL 9.3, the lock_timeout sent when connecting
+			// is rejected.
+			if c.LockTimeoutMs != nil && isUnrecognizedParameterError(err, "lock_timeout") {
+				return nil, errwrap.Wrapf("lock_timeout_ms requires PostgreSQL 9.3 or newer: {{err}}", err)
+			}
+			return nil, err
+		}
+
+		version, flavor, err := fingerprintCapabilities(db)
 		if err != nil {
 			db.Close()
 			return nil, errwrap.Wrapf("error detecting capabilities: {{err}}", err)
 		}
 
+		// In PgBouncer mode, lock_timeout is not sent when connecting but set
+		// locally at the beginning of every transaction by beginTransaction,
+		// so an older server is only detected here, from its version.
+		if c.LockTimeoutMs != nil && !isFeatureSupported(*version, featureLockTimeout) {
+			db.Close()
+			return nil, fmt.Errorf("lock_timeout_ms requires PostgreSQL 9.3 or newer, the server runs %s", version)
+		}
+
 		dbEntry = dbRegistryEntry{
 			db:      db,
 			version: *version,
+			flavor:  flavor,
+			host:    server.Host,
+			port:    server.Port,
 		}
 		dbRegistry[dsn] = dbEntry
 	}
 
+	// The other connections of the client, e.g. with the credentials of a
+	// resource, go to the selected server.
+	config := *c
+	config.Host = dbEntry.host
+	config.Port = dbEntry.port
+	config.Hosts = nil
+	config.TargetSessionAttrs = ""
+
 	client := Client{
-		config:       *c,
+		config:       config,
 		databaseName: database,
 		db:           dbEntry.db,
+		version:      dbEntry.version,
+		flavor:       dbEntry.flavor,
 	}
 
 	return &client, nil
 }
 
+// openDB opens the connection pool of dsn.  lib/pq discards the notices and
+// the warnings sent by the server with the results of a statement (e.g.
+// "extension ... already exists, skipping"), they are logged by logNotice.
+func openDB(dsn string) (*sql.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(pq.ConnectorWithNoticeHandler(connector, logNotice)), nil
+}
+
+// logNotice logs a message sent by the server, at the WARN level for a
+// WARNING, INFO for a NOTICE and DEBUG for the other severities.
+func logNotice(notice *pq.Error) {
+	level := "DEBUG"
+	switch notice.Severity {
+	case "WARNING":
+		level = "WARN"
+	case "NOTICE":
+		level = "INFO"
+	}
+	log.Printf("[%s] PostgreSQL %s: %s", level, notice.Severity, notice.Message)
+}
+
+// selectServer returns the configuration connecting to a single server.
+// lib/pq supports neither the multiple hosts nor the target_session_attrs of
+// libpq, so the hosts are tried in order as libpq does, skipping with
+// read-write the servers which only accept read-only transactions (e.g. the
+// standbys).
+func (c *Config) selectServer(database string) (Config, error) {
+	addresses := c.addresses()
+	readWrite := c.TargetSessionAttrs == targetSessionAttrsReadWrite
+
+	server := *c
+	server.Hosts = nil
+	server.TargetSessionAttrs = ""
+	if len(addresses) == 1 && !readWrite {
+		server.Host = addresses[0].host
+		server.Port = addresses[0].port
+		return server, nil
+	}
+
+	var errs []string
+	for _, address := range addresses {
+		server.Host = address.host
+		server.Port = address.port
+
+		err := server.checkServer(database, readWrite)
+		if err == nil {
+			return server, nil
+		}
+		log.Printf("[WARN] skipping PostgreSQL server %s:%d: %v", address.host, address.port, err)
+		errs = append(errs, err.Error())
+	}
+
+	return Config{}, fmt.Errorf("could not connect to any of the PostgreSQL servers: %s", strings.Join(errs, "; "))
+}
+
+// checkServer checks the server of the configuration can be reached and, if
+// readWrite, accepts read-write transactions.
+func (c *Config) checkServer(database string, readWrite bool) error {
+	db, err := openDB(c.connStr(database))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := c.ping(db); err != nil {
+		return err
+	}
+	if !readWrite {
+		return nil
+	}
+
+	var readOnly string
+	if err := db.QueryRow("SHOW transaction_read_only").Scan(&readOnly); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not check if the PostgreSQL server at %s:%d is read-only: {{err}}", c.Host, c.Port), err)
+	}
+	if readOnly == "on" {
+		return fmt.Errorf("the PostgreSQL server at %s:%d only accepts read-only transactions", c.Host, c.Port)
+	}
+	return nil
+}
+
+// addresses returns the addresses of the servers to connect to, the hosts
+// without a port using Port.
+func (c *Config) addresses() []serverAddress {
+	if len(c.Hosts) == 0 {
+		return []serverAddress{{c.Host, c.Port}}
+	}
+
+	addresses := make([]serverAddress, 0, len(c.Hosts))
+	for _, h := range c.Hosts {
+		address := serverAddress{h, c.Port}
+		if host, port, err := net.SplitHostPort(h); err == nil {
+			if p, err := strconv.Atoi(port); err == nil {
+				address = serverAddress{host, p}
+			}
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// ping checks the server can be reached, within the connect timeout, so that
+// an unreachable server fails the configuration of the provider with an error
+// naming the server rather than the first operation of a resource.
+func (c *Config) ping(db *sql.DB) error {
+	ctx := context.Background()
+	if c.ConnectTimeoutSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.ConnectTimeoutSec)*time.Second)
+		defer cancel()
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf(
+			"could not reach the PostgreSQL server at %s:%d (sslmode=%s): {{err}}", c.Host, c.Port, c.SSLMode,
+		), err)
+	}
+	return nil
+}
+
 // featureSupported returns true if a given feature is supported or not.  This
 // is slightly different from Client's featureSupported in that here we're
 // evaluating against the expected version, not the fingerprinted version.
 func (c *Config) featureSupported(name featureName) bool {
+	return isFeatureSupported(c.ExpectedVersion, name)
+}
+
+// isFeatureSupported returns true if the feature is supported by the given
+// version of PostgreSQL.  All the version checks go through the
+// featureSupported mapping, so that resources branch on a feature rather than
+// on a version.
+func isFeatureSupported(version semver.Version, name featureName) bool {
 	fn, found := featureSupported[name]
 	if !found {
 		// panic'ing because this is a provider-only bug
 		panic(fmt.Sprintf("unknown feature flag %v", name))
 	}
 
-	return fn(c.ExpectedVersion)
+	return fn(version)
 }
 
 func (c *Config) connStr(database string) string {
@@ -158,7 +475,7 @@ func (c *Config) connStr(database string) string {
 	{
 		dsnFmtParts := []string{
 			"host=%s",
-			"port=%d",
+			"port=%s",
 			"dbname=%s",
 			"user=%s",
 			"password=%s",
@@ -166,8 +483,23 @@ func (c *Config) connStr(database string) string {
 			"connect_timeout=%d",
 		}
 
-		if c.featureSupported(featureFallbackApplicationName) {
-			dsnFmtParts = append(dsnFmtParts, "fallback_application_name=%s")
+		if c.featureSupported(featureApplicationName) {
+			dsnFmtParts = append(dsnFmtParts, "application_name=%s")
+		}
+
+		if c.TargetSessionAttrs == targetSessionAttrsReadWrite {
+			dsnFmtParts = append(dsnFmtParts, "target_session_attrs=%s")
+		}
+
+		for _, param := range c.startupParams() {
+			dsnFmtParts = append(dsnFmtParts, param.name+"=%s")
+		}
+
+		if c.PgBouncerMode {
+			// Send the parameters along with the query instead of
+			// preparing a statement, which may be run on another server
+			// connection by PgBouncer.
+			dsnFmtParts = append(dsnFmtParts, "binary_parameters=yes")
 		}
 
 		dsnFmt = strings.Join(dsnFmtParts, " ")
@@ -202,19 +534,38 @@ func (c *Config) connStr(database string) string {
 		return str[1 : len(str)-1]
 	}
 
+	// Several hosts are listed comma-separated, as in libpq.
+	var hosts, ports string
+	{
+		hostList := []string{}
+		portList := []string{}
+		for _, address := range c.addresses() {
+			hostList = append(hostList, address.host)
+			portList = append(portList, strconv.Itoa(address.port))
+		}
+		hosts = strings.Join(hostList, ",")
+		ports = strings.Join(portList, ",")
+	}
+
 	{
 		logValues := []interface{}{
-			quote(c.Host),
-			c.Port,
+			quote(hosts),
+			ports,
 			quote(database),
 			quote(c.Username),
 			quote("<redacted>"),
 			quote(c.SSLMode),
 			c.ConnectTimeoutSec,
 		}
-		if c.featureSupported(featureFallbackApplicationName) {
+		if c.featureSupported(featureApplicationName) {
 			logValues = append(logValues, quote(c.ApplicationName))
 		}
+		if c.TargetSessionAttrs == targetSessionAttrsReadWrite {
+			logValues = append(logValues, c.TargetSessionAttrs)
+		}
+		for _, param := range c.startupParams() {
+			logValues = append(logValues, quote(param.value))
+		}
 
 		logDSN := fmt.Sprintf(dsnFmt, logValues...)
 		log.Printf("[INFO] PostgreSQL DSN: `%s`", logDSN)
@@ -223,23 +574,74 @@ func (c *Config) connStr(database string) string {
 	var connStr string
 	{
 		connValues := []interface{}{
-			quote(c.Host),
-			c.Port,
+			quote(hosts),
+			ports,
 			quote(database),
 			quote(c.Username),
 			quote(c.Password),
 			quote(c.SSLMode),
 			c.ConnectTimeoutSec,
 		}
-		if c.featureSupported(featureFallbackApplicationName) {
+		if c.featureSupported(featureApplicationName) {
 			connValues = append(connValues, quote(c.ApplicationName))
 		}
+		if c.TargetSessionAttrs == targetSessionAttrsReadWrite {
+			connValues = append(connValues, c.TargetSessionAttrs)
+		}
+		for _, param := range c.startupParams() {
+			connValues = append(connValues, quote(param.value))
+		}
 		connStr = fmt.Sprintf(dsnFmt, connValues...)
 	}
 
 	return connStr
 }
 
+// runtimeParam is a run-time parameter (GUC) sent by lib/pq when establishing
+// every new connection.
+type runtimeParam struct {
+	name  string
+	value string
+}
+
+// runtimeParams returns the run-time parameters to set on every connection
+// according to the provider configuration.
+func (c *Config) runtimeParams() []runtimeParam {
+	params := []runtimeParam{}
+
+	if len(c.SearchPath) > 0 {
+		params = append(params, runtimeParam{"search_path", c.searchPath()})
+	}
+
+	if c.StatementTimeoutMs != nil {
+		params = append(params, runtimeParam{"statement_timeout", strconv.Itoa(*c.StatementTimeoutMs)})
+	}
+
+	if c.LockTimeoutMs != nil {
+		params = append(params, runtimeParam{"lock_timeout", strconv.Itoa(*c.LockTimeoutMs)})
+	}
+
+	return params
+}
+
+// startupParams returns the run-time parameters sent when establishing a
+// connection.  PgBouncer rejects most of them, so they are set at the
+// beginning of every transaction instead in PgBouncer mode.
+func (c *Config) startupParams() []runtimeParam {
+	if c.PgBouncerMode {
+		return nil
+	}
+	return c.runtimeParams()
+}
+
+func (c *Config) searchPath() string {
+	schemas := make([]string, 0, len(c.SearchPath))
+	for _, s := range c.SearchPath {
+		schemas = append(schemas, pq.QuoteIdentifier(s))
+	}
+	return strings.Join(schemas, ", ")
+}
+
 // DB returns a copy to an sql.Open()'ed database connection.  Callers must
 // return their database resources.  Use of QueryRow() or Exec() is encouraged.
 // Query() must have their rows.Close()'ed.
@@ -249,39 +651,67 @@ func (c *Client) DB() *sql.DB {
 
 // fingerprintCapabilities queries PostgreSQL to populate a local catalog of
 // capabilities.  This is only run once per Client.
-func fingerprintCapabilities(db *sql.DB) (*semver.Version, error) {
+func fingerprintCapabilities(db *sql.DB) (*semver.Version, serverFlavor, error) {
 	var pgVersion string
 	err := db.QueryRow(`SELECT VERSION()`).Scan(&pgVersion)
 	if err != nil {
-		return nil, errwrap.Wrapf("error PostgreSQL version: {{err}}", err)
+		return nil, flavorPostgreSQL, errwrap.Wrapf("error PostgreSQL version: {{err}}", err)
+	}
+
+	version, flavor, err := parseServerVersion(pgVersion)
+	if err != nil || flavor != flavorCockroachDB {
+		return version, flavor, err
 	}
 
+	// The version of CockroachDB is not a PostgreSQL version, the features
+	// are the ones of the PostgreSQL version it is compatible with.
+	if err := db.QueryRow(`SHOW server_version`).Scan(&pgVersion); err != nil {
+		return nil, flavor, errwrap.Wrapf("error reading CockroachDB server_version: {{err}}", err)
+	}
+	compatVersion, err := semver.ParseTolerant(pgVersion)
+	if err != nil {
+		return nil, flavor, errwrap.Wrapf("error parsing version: {{err}}", err)
+	}
+
+	return &compatVersion, flavor, nil
+}
+
+// parseServerVersion parses the output of `SELECT VERSION()` and returns the
+// version and the flavor of the server.  The version is nil for CockroachDB,
+// whose version is not a PostgreSQL version.
+func parseServerVersion(pgVersion string) (*semver.Version, serverFlavor, error) {
 	// PostgreSQL 9.2.21 on x86_64-apple-darwin16.5.0, compiled by Apple LLVM version 8.1.0 (clang-802.0.42), 64-bit
 	// PostgreSQL 9.6.7, compiled by Visual C++ build 1800, 64-bit
+	// PostgreSQL 8.0.2 on i686-pc-linux-gnu, compiled by GCC gcc (GCC) 3.4.2 20041017 (Red Hat 3.4.2-6.fc3), Redshift 1.0.12103
+	// CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)
 	fields := strings.FieldsFunc(pgVersion, func(c rune) bool {
 		return unicode.IsSpace(c) || c == ','
 	})
 	if len(fields) < 2 {
-		return nil, fmt.Errorf("error determining the server version: %q", pgVersion)
+		return nil, flavorPostgreSQL, fmt.Errorf("error determining the server version: %q", pgVersion)
+	}
+
+	if fields[0] == "CockroachDB" {
+		return nil, flavorCockroachDB, nil
 	}
 
 	version, err := semver.ParseTolerant(fields[1])
 	if err != nil {
-		return nil, errwrap.Wrapf("error parsing version: {{err}}", err)
+		return nil, flavorPostgreSQL, errwrap.Wrapf("error parsing version: {{err}}", err)
+	}
+
+	for _, field := range fields[2:] {
+		if field == "Redshift" {
+			return &version, flavorRedshift, nil
+		}
 	}
 
-	return &version, nil
+	return &version, flavorPostgreSQL, nil
 }
 
 // featureSupported returns true if a given feature is supported or not. This is
 // slightly different from Config's featureSupported in that here we're
 // evaluating against the fingerprinted version, not the expected version.
 func (c *Client) featureSupported(name featureName) bool {
-	fn, found := featureSupported[name]
-	if !found {
-		// panic'ing because this is a provider-only bug
-		panic(fmt.Sprintf("unknown feature flag %v", name))
-	}
-
-	return fn(c.version)
+	return isFeatureSupported(c.version, name)
 }
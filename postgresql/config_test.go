@@ -0,0 +1,231 @@
+package postgresql
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestConfigConnStrPgBouncerMode(t *testing.T) {
+	config := Config{
+		Host:              "localhost",
+		Port:              6432,
+		Username:          "postgres",
+		Password:          "secret",
+		SSLMode:           "disable",
+		ApplicationName:   "Terraform provider",
+		ConnectTimeoutSec: 15,
+		ExpectedVersion:   semver.MustParse("9.6.0"),
+		SearchPath:        []string{"public"},
+	}
+	statementTimeoutMs := 1000
+	config.StatementTimeoutMs = &statementTimeoutMs
+
+	expected := "host=localhost port=6432 dbname=postgres user=postgres password=secret sslmode=disable " +
+		"connect_timeout=15 application_name='Terraform provider' search_path=\"public\" statement_timeout=1000"
+	if dsn := config.connStr("postgres"); dsn != expected {
+		t.Errorf("unexpected DSN: %q, expected %q", dsn, expected)
+	}
+
+	// The run-time parameters are set in each transaction instead.
+	config.PgBouncerMode = true
+	expected = "host=localhost port=6432 dbname=postgres user=postgres password=secret sslmode=disable " +
+		"connect_timeout=15 application_name='Terraform provider' binary_parameters=yes"
+	if dsn := config.connStr("postgres"); dsn != expected {
+		t.Errorf("unexpected DSN in PgBouncer mode: %q, expected %q", dsn, expected)
+	}
+}
+
+func TestAccConfigNewClientReusesPool(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// The clients of other databases come from the same registry.
+	first, err := databaseClient(client, dbName)
+	if err != nil {
+		t.Fatalf("could not create client for %s: %v", dbName, err)
+	}
+	second, err := databaseClient(client, dbName)
+	if err != nil {
+		t.Fatalf("could not create client for %s: %v", dbName, err)
+	}
+
+	if first.DB() != second.DB() {
+		t.Errorf("expected the clients of database %s to share the same connection pool", dbName)
+	}
+	if first.DB() == client.DB() {
+		t.Errorf("expected the clients of databases postgres and %s to have their own connection pool", dbName)
+	}
+
+	same, err := databaseClient(client, "postgres")
+	if err != nil {
+		t.Fatalf("could not create client for postgres: %v", err)
+	}
+	if same != client {
+		t.Errorf("expected the client of the connected database to be returned as is")
+	}
+}
+
+func TestConfigConnStrHosts(t *testing.T) {
+	config := Config{
+		Hosts:              []string{"pg1.example.com", "pg2.example.com:5433"},
+		Port:               5432,
+		Username:           "postgres",
+		Password:           "secret",
+		SSLMode:            "disable",
+		ApplicationName:    "Terraform provider",
+		ConnectTimeoutSec:  15,
+		ExpectedVersion:    semver.MustParse("9.6.0"),
+		TargetSessionAttrs: targetSessionAttrsReadWrite,
+	}
+
+	expected := "host=pg1.example.com,pg2.example.com port=5432,5433 dbname=postgres user=postgres password=secret " +
+		"sslmode=disable connect_timeout=15 application_name='Terraform provider' target_session_attrs=read-write"
+	if dsn := config.connStr("postgres"); dsn != expected {
+		t.Errorf("unexpected DSN: %q, expected %q", dsn, expected)
+	}
+
+	// lib/pq connects to the selected server with a single host DSN.
+	config.Hosts = nil
+	config.Host = "pg2.example.com"
+	config.Port = 5433
+	config.TargetSessionAttrs = ""
+	expected = "host=pg2.example.com port=5433 dbname=postgres user=postgres password=secret " +
+		"sslmode=disable connect_timeout=15 application_name='Terraform provider'"
+	if dsn := config.connStr("postgres"); dsn != expected {
+		t.Errorf("unexpected single host DSN: %q, expected %q", dsn, expected)
+	}
+}
+
+func TestConfigRuntimeParamsTimeouts(t *testing.T) {
+	// The statement_timeout and lock_timeout of the server are kept unless
+	// they are set.
+	config := Config{}
+	if params := config.runtimeParams(); len(params) != 0 {
+		t.Errorf("unexpected run-time parameters: %v", params)
+	}
+
+	disabled := 0
+	config.StatementTimeoutMs = &disabled
+	config.LockTimeoutMs = &disabled
+	expected := []runtimeParam{{"statement_timeout", "0"}, {"lock_timeout", "0"}}
+	if params := config.runtimeParams(); !reflect.DeepEqual(params, expected) {
+		t.Errorf("unexpected run-time parameters: %v, expected %v", params, expected)
+	}
+}
+
+func TestConfigNewClientUnreachable(t *testing.T) {
+	// Nothing listens on port 1.
+	config := Config{
+		Host:              "127.0.0.1",
+		Port:              1,
+		Username:          "postgres",
+		Password:          "not-a-password",
+		SSLMode:           "disable",
+		ConnectTimeoutSec: 5,
+		MaxConns:          1,
+		ExpectedVersion:   semver.MustParse("9.6.0"),
+	}
+
+	_, err := config.NewClient("postgres")
+	if err == nil {
+		t.Fatal("expected an error connecting to an unreachable server")
+	}
+	if expected := "could not reach the PostgreSQL server at 127.0.0.1:1 (sslmode=disable)"; !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected the error to contain %q, got %q", expected, err)
+	}
+	if strings.Contains(err.Error(), config.Password) {
+		t.Errorf("expected the error not to contain the password, got %q", err)
+	}
+
+	// Each of the hosts is tried.
+	config.Hosts = []string{"127.0.0.1:1", "127.0.0.1:2"}
+	config.TargetSessionAttrs = targetSessionAttrsReadWrite
+	_, err = config.NewClient("postgres")
+	if err == nil {
+		t.Fatal("expected an error connecting to unreachable servers")
+	}
+	for _, expected := range []string{"could not connect to any of the PostgreSQL servers", "127.0.0.1:1", "127.0.0.1:2"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got %q", expected, err)
+		}
+	}
+}
+
+func TestIsFeatureSupported(t *testing.T) {
+	versions := []string{"9.2", "9.5", "10", "14", "16"}
+	cases := []struct {
+		feature  featureName
+		expected []bool
+	}{
+		{featureRoleReplication, []bool{true, true, true, true, true}},
+		{featureRoleBypassRLS, []bool{false, true, true, true, true}},
+		{featureDBAllowConnections, []bool{false, true, true, true, true}},
+		{featureRLSRestrictive, []bool{false, false, true, true, true}},
+		{featureExtensionCreateFrom, []bool{true, true, true, false, false}},
+		{featureGrantedBy, []bool{false, false, false, true, true}},
+		{featurePublicSchemaCreate, []bool{true, true, true, true, false}},
+		{featureMembershipOptions, []bool{false, false, false, false, true}},
+	}
+
+	for _, c := range cases {
+		for i, v := range versions {
+			version, err := semver.ParseTolerant(v)
+			if err != nil {
+				t.Fatalf("could not parse version %s: %v", v, err)
+			}
+			if supported := isFeatureSupported(version, c.feature); supported != c.expected[i] {
+				t.Errorf("feature %v supported by PostgreSQL %s = %t, expected %t", c.feature, v, supported, c.expected[i])
+			}
+		}
+	}
+}
+
+func TestParseServerVersion(t *testing.T) {
+	cases := []struct {
+		pgVersion string
+		version   string
+		flavor    serverFlavor
+	}{
+		{"PostgreSQL 9.2.21 on x86_64-apple-darwin16.5.0, compiled by Apple LLVM version 8.1.0 (clang-802.0.42), 64-bit", "9.2.21", flavorPostgreSQL},
+		{"PostgreSQL 9.6.7, compiled by Visual C++ build 1800, 64-bit", "9.6.7", flavorPostgreSQL},
+		{"PostgreSQL 16.2 (Debian 16.2-1.pgdg120+2) on x86_64-pc-linux-gnu, compiled by gcc (Debian 12.2.0-14) 12.2.0, 64-bit", "16.2.0", flavorPostgreSQL},
+		{"PostgreSQL 8.0.2 on i686-pc-linux-gnu, compiled by GCC gcc (GCC) 3.4.2 20041017 (Red Hat 3.4.2-6.fc3), Redshift 1.0.12103", "8.0.2", flavorRedshift},
+		// The version of CockroachDB is read from server_version.
+		{"CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)", "", flavorCockroachDB},
+	}
+
+	for _, c := range cases {
+		version, flavor, err := parseServerVersion(c.pgVersion)
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", c.pgVersion, err)
+		}
+		parsed := ""
+		if version != nil {
+			parsed = version.String()
+		}
+		if parsed != c.version || flavor != c.flavor {
+			t.Errorf("%q parsed as version %q, flavor %v, expected %q, %v", c.pgVersion, parsed, flavor, c.version, c.flavor)
+		}
+	}
+
+	if _, _, err := parseServerVersion("Redshift"); err == nil {
+		t.Errorf("expected an error parsing an invalid version")
+	}
+}
@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"database/sql"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	availableExtensionsDatabaseAttr   = "database"
+	availableExtensionsExtensionsAttr = "extensions"
+
+	availableExtensionNameAttr             = "name"
+	availableExtensionDefaultVersionAttr   = "default_version"
+	availableExtensionInstalledVersionAttr = "installed_version"
+	availableExtensionCommentAttr          = "comment"
+)
+
+func dataSourcePostgreSQLAvailableExtensions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLAvailableExtensionsRead,
+
+		Schema: map[string]*schema.Schema{
+			availableExtensionsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database to list the available extensions of",
+			},
+			availableExtensionsExtensionsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						availableExtensionNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the extension",
+						},
+						availableExtensionDefaultVersionAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The version installed by default",
+						},
+						availableExtensionInstalledVersionAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The installed version of the extension, empty if it is not installed",
+						},
+						availableExtensionCommentAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The comment of the extension",
+						},
+					},
+				},
+				Description: "The extensions which can be installed in the database",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLAvailableExtensionsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := d.Get(availableExtensionsDatabaseAttr).(string)
+
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	rows, err := txn.Query(
+		`SELECT name, default_version, installed_version, comment ` +
+			`FROM pg_catalog.pg_available_extensions ` +
+			`ORDER BY name`,
+	)
+	if err != nil {
+		return errwrap.Wrapf("could not list available extensions: {{err}}", err)
+	}
+	defer rows.Close()
+
+	extensions := make([]interface{}, 0)
+	for rows.Next() {
+		var name string
+		var defaultVersion, installedVersion, comment sql.NullString
+		if err := rows.Scan(&name, &defaultVersion, &installedVersion, &comment); err != nil {
+			return errwrap.Wrapf("could not scan available extension: {{err}}", err)
+		}
+
+		extensions = append(extensions, map[string]interface{}{
+			availableExtensionNameAttr:             name,
+			availableExtensionDefaultVersionAttr:   defaultVersion.String,
+			availableExtensionInstalledVersionAttr: installedVersion.String,
+			availableExtensionCommentAttr:          comment.String,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("could not list available extensions: {{err}}", err)
+	}
+
+	d.Set(availableExtensionsExtensionsAttr, extensions)
+	d.SetId(database)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlDataSourceAvailableExtensions(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testDataSourceAvailableExtensions = fmt.Sprintf(`
+	data "postgresql_available_extensions" "all" {
+		database = "%s"
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceAvailableExtensions,
+				Check: resource.ComposeTestCheckFunc(
+					// plpgsql is installed in every database.
+					testAccCheckAvailableExtension("data.postgresql_available_extensions.all", "plpgsql", true),
+					testAccCheckAvailableExtension("data.postgresql_available_extensions.all", "pg_trgm", false),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckAvailableExtension checks that the extension is listed by the
+// data source with a default version, and whether it is installed.
+func testAccCheckAvailableExtension(n, extName string, installed bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Data source not found: %s", n)
+		}
+
+		attrs := rs.Primary.Attributes
+		for i := 0; attrs[fmt.Sprintf("extensions.%d.name", i)] != ""; i++ {
+			if attrs[fmt.Sprintf("extensions.%d.name", i)] != extName {
+				continue
+			}
+			if attrs[fmt.Sprintf("extensions.%d.default_version", i)] == "" {
+				return fmt.Errorf("expected a default version for extension %s", extName)
+			}
+			if isInstalled := attrs[fmt.Sprintf("extensions.%d.installed_version", i)] != ""; isInstalled != installed {
+				return fmt.Errorf("expected extension %s installed to be %t, got %t", extName, installed, isInstalled)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("extension %s not listed by %s", extName, n)
+	}
+}
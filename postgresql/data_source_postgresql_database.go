@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourcePostgreSQLDatabase() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLDatabaseRead,
+
+		Schema: map[string]*schema.Schema{
+			dbNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the database",
+			},
+			dbOwnerAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The role name of the user who owns the database",
+			},
+			dbEncodingAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Character set encoding of the database",
+			},
+			dbCollationAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Collation order (LC_COLLATE) of the database",
+			},
+			dbCTypeAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Character classification (LC_CTYPE) of the database",
+			},
+			dbTablespaceAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the tablespace of the database",
+			},
+			dbConnLimitAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "How many concurrent connections can be made to the database",
+			},
+			dbAllowConnsAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the database accepts connections",
+			},
+			dbIsTemplateAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the database is a template",
+			},
+			dbLocProvAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The locale provider of the database, PostgreSQL 15+",
+			},
+			dbICULocaleAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ICU locale of the database, PostgreSQL 15+",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLDatabaseRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	dbName := d.Get(dbNameAttr).(string)
+	d.SetId(dbName)
+
+	if err := readDatabase(c, d); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return fmt.Errorf("PostgreSQL database %q not found", dbName)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceDatabase(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testDataSourceDatabase = fmt.Sprintf(`
+	data "postgresql_database" "test" {
+		name = "%s"
+	}
+
+	data "postgresql_database" "template" {
+		name = "template1"
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceDatabase,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_database.test", "name", dbName),
+					resource.TestCheckResourceAttrSet("data.postgresql_database.test", "owner"),
+					resource.TestCheckResourceAttrSet("data.postgresql_database.test", "encoding"),
+					resource.TestCheckResourceAttrSet("data.postgresql_database.test", "lc_collate"),
+					resource.TestCheckResourceAttr("data.postgresql_database.test", "tablespace_name", "pg_default"),
+					resource.TestCheckResourceAttr("data.postgresql_database.test", "connection_limit", "-1"),
+					resource.TestCheckResourceAttr("data.postgresql_database.test", "allow_connections", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_database.test", "is_template", "false"),
+
+					resource.TestCheckResourceAttr("data.postgresql_database.template", "is_template", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDataSourceDatabase_NotFound(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+				data "postgresql_database" "missing" {
+					name = "tf_tests_missing_db"
+				}
+				`,
+				ExpectError: regexp.MustCompile(`PostgreSQL database "tf_tests_missing_db" not found`),
+			},
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package postgresql
+
+import (
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	extensionsDatabaseAttr   = "database"
+	extensionsExtensionsAttr = "extensions"
+
+	extensionsExtensionNameAttr    = "name"
+	extensionsExtensionVersionAttr = "version"
+	extensionsExtensionSchemaAttr  = "schema"
+)
+
+func dataSourcePostgreSQLExtensions() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLExtensionsRead,
+
+		Schema: map[string]*schema.Schema{
+			extensionsDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database to list the extensions from",
+			},
+			extensionsExtensionsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						extensionsExtensionNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the extension",
+						},
+						extensionsExtensionVersionAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The installed version of the extension",
+						},
+						extensionsExtensionSchemaAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The schema containing the objects of the extension",
+						},
+					},
+				},
+				Description: "The extensions installed in the database",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLExtensionsRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := d.Get(extensionsDatabaseAttr).(string)
+
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	rows, err := txn.Query(
+		`SELECT e.extname, e.extversion, n.nspname ` +
+			`FROM pg_catalog.pg_extension e ` +
+			`JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace ` +
+			`ORDER BY e.extname`,
+	)
+	if err != nil {
+		return errwrap.Wrapf("could not list extensions: {{err}}", err)
+	}
+	defer rows.Close()
+
+	extensions := make([]interface{}, 0)
+	for rows.Next() {
+		var extName, extVersion, extSchema string
+		if err := rows.Scan(&extName, &extVersion, &extSchema); err != nil {
+			return errwrap.Wrapf("could not scan extension: {{err}}", err)
+		}
+
+		extensions = append(extensions, map[string]interface{}{
+			extensionsExtensionNameAttr:    extName,
+			extensionsExtensionVersionAttr: extVersion,
+			extensionsExtensionSchemaAttr:  extSchema,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("could not list extensions: {{err}}", err)
+	}
+
+	d.Set(extensionsExtensionsAttr, extensions)
+	d.SetId(database)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlDataSourceExtensions(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE EXTENSION pg_trgm")
+
+	var testDataSourceExtensions = fmt.Sprintf(`
+	data "postgresql_extensions" "all" {
+		database = "%s"
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceExtensions,
+				Check:  testAccCheckDataSourceExtension("data.postgresql_extensions.all", "pg_trgm", "public"),
+			},
+		},
+	})
+}
+
+// testAccCheckDataSourceExtension checks that the extension is listed by the
+// data source, in the given schema and with a version.
+func testAccCheckDataSourceExtension(n, extName, extSchema string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Data source not found: %s", n)
+		}
+
+		attrs := rs.Primary.Attributes
+		for i := 0; attrs[fmt.Sprintf("extensions.%d.name", i)] != ""; i++ {
+			if attrs[fmt.Sprintf("extensions.%d.name", i)] != extName {
+				continue
+			}
+			if schema := attrs[fmt.Sprintf("extensions.%d.schema", i)]; schema != extSchema {
+				return fmt.Errorf("expected extension %s in schema %s, got %s", extName, extSchema, schema)
+			}
+			if attrs[fmt.Sprintf("extensions.%d.version", i)] == "" {
+				return fmt.Errorf("expected a version for extension %s", extName)
+			}
+			return nil
+		}
+
+		return fmt.Errorf("extension %s not listed by %s", extName, n)
+	}
+}
@@ -0,0 +1,268 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/sean-/postgresql-acl"
+)
+
+const (
+	grantDatabaseAttr   = "database"
+	grantSchemaAttr     = "schema"
+	grantObjectTypeAttr = "object_type"
+	grantObjectNameAttr = "object_name"
+	grantGrantsAttr     = "grants"
+
+	grantGrantObjectNameAttr = "object_name"
+	grantGrantRoleAttr       = "role"
+	grantGrantPrivilegesAttr = "privileges"
+	grantGrantWithGrantAttr  = "with_grant"
+)
+
+// aclDefaultTypes maps the object types of the postgresql_grant data source
+// to the type argument of acldefault(), which returns the privileges of an
+// object without ACL (i.e. whose privileges were never granted nor revoked).
+var aclDefaultTypes = map[string]string{
+	"schema":   "n",
+	"table":    "r",
+	"sequence": "s",
+}
+
+// aclPrivileges is the list of the privileges of an aclitem with their name
+// in a GRANT statement.
+var aclPrivileges = []struct {
+	privilege acl.Privileges
+	name      string
+}{
+	{acl.Select, "SELECT"},
+	{acl.Insert, "INSERT"},
+	{acl.Update, "UPDATE"},
+	{acl.Delete, "DELETE"},
+	{acl.Truncate, "TRUNCATE"},
+	{acl.References, "REFERENCES"},
+	{acl.Trigger, "TRIGGER"},
+	{acl.Execute, "EXECUTE"},
+	{acl.Usage, "USAGE"},
+	{acl.Create, "CREATE"},
+	{acl.Temporary, "TEMPORARY"},
+	{acl.Connect, "CONNECT"},
+}
+
+func dataSourcePostgreSQLGrant() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLGrantRead,
+
+		Schema: map[string]*schema.Schema{
+			grantDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database of the objects",
+			},
+			grantSchemaAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database schema of the objects, or the schema itself for the schema object type",
+			},
+			grantObjectTypeAttr: {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"schema",
+					"table",
+					"sequence",
+				}, false),
+				Description: "The PostgreSQL object type to read the privileges of (one of: schema, table, sequence)",
+			},
+			grantObjectNameAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of the object to read the privileges of, instead of all the objects of the schema",
+			},
+			grantGrantsAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						grantGrantObjectNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the object",
+						},
+						grantGrantRoleAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The role the privileges are granted to (or PUBLIC)",
+						},
+						grantGrantPrivilegesAttr: {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The privileges granted to the role",
+						},
+						grantGrantWithGrantAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the role can grant these privileges to other roles",
+						},
+					},
+				},
+				Description: "The privileges granted on the objects",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLGrantRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := d.Get(grantDatabaseAttr).(string)
+	pgSchema := d.Get(grantSchemaAttr).(string)
+	objectType := d.Get(grantObjectTypeAttr).(string)
+	objectName := d.Get(grantObjectNameAttr).(string)
+
+	var query string
+	var args []interface{}
+	if objectType == "schema" {
+		query = `SELECT n.nspname, ` +
+			`unnest(COALESCE(n.nspacl, pg_catalog.acldefault('n', n.nspowner)))::text ` +
+			`FROM pg_catalog.pg_namespace n ` +
+			`WHERE n.nspname = $1`
+		args = []interface{}{pgSchema}
+	} else {
+		query = `SELECT c.relname, ` +
+			`unnest(COALESCE(c.relacl, pg_catalog.acldefault($1, c.relowner)))::text ` +
+			`FROM pg_catalog.pg_class c ` +
+			`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+			`WHERE n.nspname = $2 AND c.relkind = $3 AND ($4 = '' OR c.relname = $4) ` +
+			`ORDER BY c.relname`
+		args = []interface{}{aclDefaultTypes[objectType], pgSchema, objectTypes[objectType], objectName}
+	}
+
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	rows, err := txn.Query(query, args...)
+	if err != nil {
+		return errwrap.Wrapf("could not read privileges: {{err}}", err)
+	}
+	defer rows.Close()
+
+	grants := make([]interface{}, 0)
+	for rows.Next() {
+		var objName, aclStr string
+		if err := rows.Scan(&objName, &aclStr); err != nil {
+			return errwrap.Wrapf("could not scan privileges: {{err}}", err)
+		}
+
+		aclItem, extraPrivileges, err := parseACL(aclStr)
+		if err != nil {
+			return errwrap.Wrapf("Error parsing aclitem: {{err}}", err)
+		}
+
+		grants = append(grants, aclItemGrants(objName, aclItem, extraPrivileges)...)
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("could not read privileges: {{err}}", err)
+	}
+
+	d.Set(grantGrantsAttr, grants)
+	d.SetId(strings.Join([]string{database, pgSchema, objectType, objectName}, "_"))
+
+	return nil
+}
+
+// parseACL parses an aclitem.  The privileges added after the aclitem parser
+// was written (i.e. MAINTAIN in PostgreSQL 17) are removed from the aclitem
+// before parsing it, they are returned separately with their grant option.
+func parseACL(aclStr string) (acl.ACL, map[string]bool, error) {
+	extraPrivileges := make(map[string]bool)
+
+	// The privileges are between the = following the grantee and the /
+	// preceding the grantor, as in acl.Parse.
+	start := strings.IndexByte(aclStr, '=')
+	if start == -1 {
+		return acl.ACL{}, nil, fmt.Errorf("invalid aclitem format: %+q", aclStr)
+	}
+	end := strings.IndexByte(aclStr[start:], '/')
+	if end == -1 {
+		end = len(aclStr)
+	} else {
+		end += start
+	}
+
+	privs := aclStr[start+1 : end]
+	if i := strings.IndexByte(privs, 'm'); i != -1 {
+		withGrant := i+1 < len(privs) && privs[i+1] == '*'
+		extraPrivileges["MAINTAIN"] = withGrant
+		if withGrant {
+			privs = privs[:i] + privs[i+2:]
+		} else {
+			privs = privs[:i] + privs[i+1:]
+		}
+	}
+
+	aclItem, err := acl.Parse(aclStr[:start+1] + privs + aclStr[end:])
+	if err != nil {
+		return acl.ACL{}, nil, err
+	}
+
+	return aclItem, extraPrivileges, nil
+}
+
+// aclItemGrants returns the grants of an aclitem: the privileges granted with
+// the grant option and the others are returned separately.
+func aclItemGrants(objName string, aclItem acl.ACL, extraPrivileges map[string]bool) []interface{} {
+	// In an aclitem, the PUBLIC pseudo-role is represented by an empty role
+	// name, and the role names which are not simple identifiers are quoted.
+	role := aclItem.Role
+	switch {
+	case role == "":
+		role = publicRole
+	case len(role) > 1 && strings.HasPrefix(role, `"`) && strings.HasSuffix(role, `"`):
+		role = strings.Replace(role[1:len(role)-1], `""`, `"`, -1)
+	}
+
+	var privileges, withGrant []interface{}
+	for _, priv := range aclPrivileges {
+		switch {
+		case aclItem.GetGrantOption(priv.privilege):
+			withGrant = append(withGrant, priv.name)
+		case aclItem.GetPrivilege(priv.privilege):
+			privileges = append(privileges, priv.name)
+		}
+	}
+	for name, grantOption := range extraPrivileges {
+		if grantOption {
+			withGrant = append(withGrant, name)
+		} else {
+			privileges = append(privileges, name)
+		}
+	}
+
+	grants := make([]interface{}, 0, 2)
+	for _, grant := range []struct {
+		privileges []interface{}
+		withGrant  bool
+	}{
+		{privileges, false},
+		{withGrant, true},
+	} {
+		if len(grant.privileges) == 0 {
+			continue
+		}
+		grants = append(grants, map[string]interface{}{
+			grantGrantObjectNameAttr: objName,
+			grantGrantRoleAttr:       role,
+			grantGrantPrivilegesAttr: grant.privileges,
+			grantGrantWithGrantAttr:  grant.withGrant,
+		})
+	}
+
+	return grants
+}
@@ -0,0 +1,173 @@
+package postgresql
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlDataSourceGrant(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "GRANT SELECT ON test_table TO PUBLIC")
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT INSERT ON test_table TO %s WITH GRANT OPTION", roleName))
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT USAGE ON SCHEMA public TO %s", roleName))
+
+	var testDataSourceGrant = fmt.Sprintf(`
+	data "postgresql_grant" "table" {
+		database    = "%s"
+		schema      = "public"
+		object_type = "table"
+		object_name = "test_table"
+	}
+
+	data "postgresql_grant" "schema" {
+		database    = "%s"
+		schema      = "public"
+		object_type = "schema"
+	}
+	`, dbName, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceGrant,
+				Check: resource.ComposeTestCheckFunc(
+					// The owner of the table is listed first.
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.#", "3"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.0.object_name", "test_table"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.0.role", config.Username),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.1.role", "PUBLIC"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.1.privileges.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.1.privileges.0", "SELECT"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.1.with_grant", "false"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.2.role", roleName),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.2.privileges.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.2.privileges.0", "INSERT"),
+					resource.TestCheckResourceAttr("data.postgresql_grant.table", "grants.2.with_grant", "true"),
+
+					testAccCheckDataSourceGrantContains("data.postgresql_grant.schema", roleName, "USAGE"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckDataSourceGrantContains checks that the grants of the data
+// source include the privilege for the role, without depending on the
+// default privileges of the object.
+func testAccCheckDataSourceGrantContains(name, role, privilege string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Data source not found: %s", name)
+		}
+
+		attrs := rs.Primary.Attributes
+		count, _ := strconv.Atoi(attrs["grants.#"])
+		for i := 0; i < count; i++ {
+			if attrs[fmt.Sprintf("grants.%d.role", i)] != role {
+				continue
+			}
+			privileges, _ := strconv.Atoi(attrs[fmt.Sprintf("grants.%d.privileges.#", i)])
+			for j := 0; j < privileges; j++ {
+				if attrs[fmt.Sprintf("grants.%d.privileges.%d", i, j)] == privilege {
+					return nil
+				}
+			}
+		}
+
+		return fmt.Errorf("%s not granted to %s in %s", privilege, role, name)
+	}
+}
+
+func TestParseACL(t *testing.T) {
+	tests := []struct {
+		aclStr     string
+		expected   []interface{}
+		shouldFail bool
+	}{
+		{
+			aclStr: "=r/postgres",
+			expected: []interface{}{
+				map[string]interface{}{
+					"object_name": "obj",
+					"role":        "PUBLIC",
+					"privileges":  []interface{}{"SELECT"},
+					"with_grant":  false,
+				},
+			},
+		},
+		{
+			aclStr: "foo=a*rw/postgres",
+			expected: []interface{}{
+				map[string]interface{}{
+					"object_name": "obj",
+					"role":        "foo",
+					"privileges":  []interface{}{"SELECT", "UPDATE"},
+					"with_grant":  false,
+				},
+				map[string]interface{}{
+					"object_name": "obj",
+					"role":        "foo",
+					"privileges":  []interface{}{"INSERT"},
+					"with_grant":  true,
+				},
+			},
+		},
+		{
+			aclStr: `"foo ""bar"""=U/postgres`,
+			expected: []interface{}{
+				map[string]interface{}{
+					"object_name": "obj",
+					"role":        `foo "bar"`,
+					"privileges":  []interface{}{"USAGE"},
+					"with_grant":  false,
+				},
+			},
+		},
+		{
+			// MAINTAIN was added in PostgreSQL 17.
+			aclStr: "postgres=arwdDxtm/postgres",
+			expected: []interface{}{
+				map[string]interface{}{
+					"object_name": "obj",
+					"role":        "postgres",
+					"privileges":  []interface{}{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"},
+					"with_grant":  false,
+				},
+			},
+		},
+		{
+			aclStr:     "postgres",
+			shouldFail: true,
+		},
+	}
+
+	for _, test := range tests {
+		aclItem, extraPrivileges, err := parseACL(test.aclStr)
+		if test.shouldFail {
+			if err == nil {
+				t.Fatalf("expected parseACL(%q) to fail", test.aclStr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", test.aclStr, err)
+		}
+
+		grants := aclItemGrants("obj", aclItem, extraPrivileges)
+		if !reflect.DeepEqual(grants, test.expected) {
+			t.Fatalf("wrong grants for %q, expected %v, got %v", test.aclStr, test.expected, grants)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	roleMembersRoleAttr    = "role"
+	roleMembersMembersAttr = "members"
+
+	roleMembersMemberAttr = "member"
+	roleMembersAdminAttr  = "admin"
+)
+
+func dataSourcePostgreSQLRoleMembers() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLRoleMembersRead,
+
+		Schema: map[string]*schema.Schema{
+			roleMembersRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The role to list the direct members of",
+			},
+			roleMembersMembersAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleMembersMemberAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the member",
+						},
+						roleMembersAdminAttr: {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the member can grant the role to others (WITH ADMIN OPTION)",
+						},
+					},
+				},
+				Description: "The roles the role has been directly granted to",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLRoleMembersRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	roleName := d.Get(roleMembersRoleAttr).(string)
+
+	var exists bool
+	if err := c.DB().QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = $1)", roleName).Scan(&exists); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not read role %s: {{err}}", roleName), err)
+	}
+	if !exists {
+		return fmt.Errorf("PostgreSQL role %q not found", roleName)
+	}
+
+	// Since PostgreSQL 16 a member can be granted the role several times by
+	// different grantors, with different options.
+	rows, err := c.DB().Query(
+		`SELECT u.rolname, bool_or(m.admin_option) FROM pg_catalog.pg_auth_members m `+
+			`JOIN pg_catalog.pg_roles r ON r.oid = m.roleid `+
+			`JOIN pg_catalog.pg_roles u ON u.oid = m.member `+
+			`WHERE r.rolname = $1 `+
+			`GROUP BY u.rolname ORDER BY u.rolname`,
+		roleName,
+	)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not get members of role %s: {{err}}", roleName), err)
+	}
+	defer rows.Close()
+
+	members := make([]interface{}, 0)
+	for rows.Next() {
+		var member string
+		var admin bool
+		if err := rows.Scan(&member, &admin); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not scan member of role %s: {{err}}", roleName), err)
+		}
+
+		members = append(members, map[string]interface{}{
+			roleMembersMemberAttr: member,
+			roleMembersAdminAttr:  admin,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not get members of role %s: {{err}}", roleName), err)
+	}
+
+	d.Set(roleMembersMembersAttr, members)
+	d.SetId(roleName)
+
+	return nil
+}
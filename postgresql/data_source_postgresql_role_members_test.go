@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceRoleMembers(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlDataSourceRoleMembersConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.member", "tf_tests_members_admin"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.0.admin", "true"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.1.member", "tf_tests_members_user"),
+					resource.TestCheckResourceAttr("data.postgresql_role_members.group", "members.1.admin", "false"),
+				),
+			},
+			{
+				Config: `
+				data "postgresql_role_members" "missing" {
+					role = "tf_tests_members_missing"
+				}
+				`,
+				ExpectError: regexp.MustCompile("PostgreSQL role \"tf_tests_members_missing\" not found"),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlDataSourceRoleMembersConfig = `
+resource "postgresql_role" "group" {
+  name = "tf_tests_members_group"
+}
+
+resource "postgresql_role" "admin" {
+  name = "tf_tests_members_admin"
+
+  membership {
+    role  = "${postgresql_role.group.name}"
+    admin = true
+  }
+}
+
+resource "postgresql_role" "user" {
+  name  = "tf_tests_members_user"
+  roles = ["${postgresql_role.group.name}"]
+}
+
+data "postgresql_role_members" "group" {
+  role       = "${postgresql_role.group.name}"
+  depends_on = ["postgresql_role.admin", "postgresql_role.user"]
+}
+`
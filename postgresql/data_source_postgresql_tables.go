@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	tablesDatabaseAttr = "database"
+	tablesSchemaAttr   = "schema"
+	tablesLikeAnyAttr  = "like_any"
+	tablesRegexAttr    = "regex"
+	tablesTablesAttr   = "tables"
+
+	tablesTableNameAttr       = "name"
+	tablesTableObjectTypeAttr = "object_type"
+)
+
+// tableRelKinds maps the pg_class relkind of table-like relations to the
+// object type exposed by the postgresql_tables data source.
+var tableRelKinds = map[string]string{
+	"r": "table",
+	"v": "view",
+	"m": "matview",
+}
+
+func dataSourcePostgreSQLTables() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourcePostgreSQLTablesRead,
+
+		Schema: map[string]*schema.Schema{
+			tablesDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database to list the tables from",
+			},
+			tablesSchemaAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The database schema to list the tables from",
+			},
+			tablesLikeAnyAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only return the tables whose name matches any of these LIKE patterns",
+			},
+			tablesRegexAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the tables whose name matches this POSIX regular expression",
+			},
+			tablesTablesAttr: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						tablesTableNameAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the table",
+						},
+						tablesTableObjectTypeAttr: {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the table (one of: table, view, matview)",
+						},
+					},
+				},
+				Description: "The tables of the schema matching the filters",
+			},
+		},
+	}
+}
+
+func dataSourcePostgreSQLTablesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	database := d.Get(tablesDatabaseAttr).(string)
+	pgSchema := d.Get(tablesSchemaAttr).(string)
+
+	relKinds := make([]string, 0, len(tableRelKinds))
+	for relKind := range tableRelKinds {
+		relKinds = append(relKinds, relKind)
+	}
+
+	b := bytes.NewBufferString(`SELECT c.relname, c.relkind::text ` +
+		`FROM pg_catalog.pg_class c ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relkind::text = ANY($2)`)
+	args := []interface{}{pgSchema, pq.Array(relKinds)}
+
+	if v, ok := d.GetOk(tablesLikeAnyAttr); ok {
+		patterns := make([]string, 0, len(v.([]interface{})))
+		for _, pattern := range v.([]interface{}) {
+			patterns = append(patterns, pattern.(string))
+		}
+		args = append(args, pq.Array(patterns))
+		fmt.Fprintf(b, " AND c.relname LIKE ANY($%d)", len(args))
+	}
+
+	if v, ok := d.GetOk(tablesRegexAttr); ok {
+		args = append(args, v.(string))
+		fmt.Fprintf(b, " AND c.relname ~ $%d", len(args))
+	}
+
+	fmt.Fprint(b, " ORDER BY c.relname")
+
+	txn, err := startTransaction(client, database)
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	rows, err := txn.Query(b.String(), args...)
+	if err != nil {
+		return errwrap.Wrapf("could not list tables: {{err}}", err)
+	}
+	defer rows.Close()
+
+	tables := make([]interface{}, 0)
+	for rows.Next() {
+		var tableName, relKind string
+		if err := rows.Scan(&tableName, &relKind); err != nil {
+			return errwrap.Wrapf("could not scan table: {{err}}", err)
+		}
+
+		tables = append(tables, map[string]interface{}{
+			tablesTableNameAttr:       tableName,
+			tablesTableObjectTypeAttr: tableRelKinds[relKind],
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("could not list tables: {{err}}", err)
+	}
+
+	d.Set(tablesTablesAttr, tables)
+	d.SetId(strings.Join([]string{database, pgSchema}, "_"))
+
+	return nil
+}
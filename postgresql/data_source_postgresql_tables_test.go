@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+)
+
+func TestAccPostgresqlDataSourceTables(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE VIEW test_view AS SELECT * FROM test_table")
+
+	var testDataSourceTables = fmt.Sprintf(`
+	data "postgresql_tables" "all" {
+		database = "%s"
+		schema   = "public"
+	}
+
+	data "postgresql_tables" "like_any" {
+		database = "%s"
+		schema   = "public"
+		like_any = ["%%_view"]
+	}
+
+	data "postgresql_tables" "regex" {
+		database = "%s"
+		schema   = "public"
+		regex    = "^test_t"
+	}
+	`, dbName, dbName, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceTables,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.postgresql_tables.all", "tables.#", "2"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.all", "tables.0.name", "test_table"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.all", "tables.0.object_type", "table"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.all", "tables.1.name", "test_view"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.all", "tables.1.object_type", "view"),
+
+					resource.TestCheckResourceAttr("data.postgresql_tables.like_any", "tables.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.like_any", "tables.0.name", "test_view"),
+
+					resource.TestCheckResourceAttr("data.postgresql_tables.regex", "tables.#", "1"),
+					resource.TestCheckResourceAttr("data.postgresql_tables.regex", "tables.0.name", "test_table"),
+				),
+			},
+		},
+	})
+}
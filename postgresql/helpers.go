@@ -1,9 +1,18 @@
 package postgresql
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -20,6 +29,20 @@ func pqQuoteLiteral(in string) string {
 	return in
 }
 
+// isPublicRole returns true if the role is the PUBLIC pseudo-role.
+func isPublicRole(role string) bool {
+	return strings.ToUpper(role) == publicRole
+}
+
+// quoteRoleName quotes a role name for inclusion in a GRANT or REVOKE
+// statement, leaving the PUBLIC pseudo-role unquoted.
+func quoteRoleName(role string) string {
+	if isPublicRole(role) {
+		return publicRole
+	}
+	return pq.QuoteIdentifier(role)
+}
+
 func validateConnLimit(v interface{}, key string) (warnings []string, errors []error) {
 	value := v.(int)
 	if value < -1 {
@@ -28,6 +51,53 @@ func validateConnLimit(v interface{}, key string) (warnings []string, errors []e
 	return
 }
 
+// validateBoolString validates a bool held by a string attribute, e.g. to
+// tell an unset attribute apart from false.  Terraform turns a bool of the
+// configuration into "1" or "0" in a string attribute.
+func validateBoolString(v interface{}, key string) (warnings []string, errors []error) {
+	if _, err := strconv.ParseBool(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%s must be true or false, got %q", key, v.(string)))
+	}
+	return
+}
+
+// normalizeBoolString stores a bool held by a string attribute as "true" or
+// "false".
+func normalizeBoolString(v interface{}) string {
+	value, err := strconv.ParseBool(v.(string))
+	if err != nil {
+		return v.(string)
+	}
+	return strconv.FormatBool(value)
+}
+
+// maxIdentifierLength is the maximum length in bytes of an identifier
+// (NAMEDATALEN - 1), longer identifiers are silently truncated by PostgreSQL.
+const maxIdentifierLength = 63
+
+func validateIdentifier(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(string)
+	switch {
+	case value == "":
+		errors = append(errors, fmt.Errorf("%s can not be an empty string", key))
+	case strings.ContainsRune(value, 0):
+		errors = append(errors, fmt.Errorf("%s can not contain a NUL character", key))
+	case len(value) > maxIdentifierLength:
+		errors = append(errors, fmt.Errorf("%s can not be longer than %d bytes, got %d bytes: %s", key, maxIdentifierLength, len(value), value))
+	}
+	return
+}
+
+// validateSchemaName validates the name of a schema, which can not start with
+// pg_ as it is reserved for the system schemas.
+func validateSchemaName(v interface{}, key string) (warnings []string, errors []error) {
+	warnings, errors = validateIdentifier(v, key)
+	if value := v.(string); strings.HasPrefix(value, "pg_") {
+		errors = append(errors, fmt.Errorf("%s can not start with pg_ (reserved for system schemas), got: %s", key, value))
+	}
+	return
+}
+
 func sliceContainsStr(haystack []string, needle string) bool {
 	for _, s := range haystack {
 		if s == needle {
@@ -39,26 +109,95 @@ func sliceContainsStr(haystack []string, needle string) bool {
 
 // allowedPrivileges is the list of privileges allowed per object types in Postgres.
 // see: https://www.postgresql.org/docs/current/sql-grant.html
+// The privileges added in later versions are removed by objectTypePrivileges.
 var allowedPrivileges = map[string][]string{
-	"table":    []string{"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"},
+	"table":    []string{"ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"},
 	"sequence": []string{"ALL", "USAGE", "SELECT", "UPDATE"},
+	"function": []string{"ALL", "EXECUTE"},
+	"schema":   []string{"ALL", "USAGE", "CREATE"},
+	"type":     []string{"ALL", "USAGE"},
+
+	"large_object":         []string{"ALL", "SELECT", "UPDATE"},
+	"foreign_data_wrapper": []string{"ALL", "USAGE"},
+	"foreign_server":       []string{"ALL", "USAGE"},
 }
 
-// validatePrivileges checks that privileges to apply are allowed for this object type.
-func validatePrivileges(objectType string, privileges []interface{}) error {
+// objectTypePrivileges returns the privileges allowed for the object type by
+// the server, i.e. MAINTAIN only since PostgreSQL 17.
+func objectTypePrivileges(c *Client, objectType string) ([]string, bool) {
 	allowed, ok := allowedPrivileges[objectType]
+	if !ok || c.featureSupported(featureMaintainPrivilege) {
+		return allowed, ok
+	}
+
+	privileges := make([]string, 0, len(allowed))
+	for _, priv := range allowed {
+		if priv != "MAINTAIN" {
+			privileges = append(privileges, priv)
+		}
+	}
+	return privileges, true
+}
+
+// validatePrivileges checks that privileges to apply are allowed for this object type.
+func validatePrivileges(c *Client, objectType string, privileges []interface{}) error {
+	allowed, ok := objectTypePrivileges(c, objectType)
 	if !ok {
 		return fmt.Errorf("unknown object type %s", objectType)
 	}
 
 	for _, priv := range privileges {
 		if !sliceContainsStr(allowed, priv.(string)) {
-			return fmt.Errorf("%s is not an allowed privilege for object type %s", priv, objectType)
+			return fmt.Errorf("%s is not an allowed privilege for object type %s on PostgreSQL %s", priv, objectType, c.version)
 		}
 	}
 	return nil
 }
 
+// checkNotCockroachDB returns an error if the client talks to CockroachDB,
+// which does not support what the resource manages.
+func checkNotCockroachDB(c *Client, what string) error {
+	if c.flavor == flavorCockroachDB {
+		return fmt.Errorf("PostgreSQL client is talking with CockroachDB (compatible with PostgreSQL %q), which does not support %s", c.version.String(), what)
+	}
+	return nil
+}
+
+// normalizePrivileges returns ALL if the privileges include every privilege
+// of the object type, as when ALL has been granted and the privileges are read
+// back from an aclitem, otherwise the privileges unchanged.
+func normalizePrivileges(c *Client, objectType string, privileges *schema.Set) *schema.Set {
+	if privileges.Contains("ALL") {
+		return schema.NewSet(schema.HashString, []interface{}{"ALL"})
+	}
+
+	allowed, ok := objectTypePrivileges(c, objectType)
+	if !ok {
+		return privileges
+	}
+	for _, priv := range allowed {
+		if priv != "ALL" && !privileges.Contains(priv) {
+			return privileges
+		}
+	}
+	return schema.NewSet(schema.HashString, []interface{}{"ALL"})
+}
+
+// privilegesEqual returns whether both lists of privileges are the same on the
+// object type, ALL being equivalent to the list of all its privileges on the
+// server.
+func privilegesEqual(c *Client, objectType string, a, b *schema.Set) bool {
+	return normalizePrivileges(c, objectType, a).Equal(normalizePrivileges(c, objectType, b))
+}
+
+func stringsToInterfaces(in []string) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, v := range in {
+		out[i] = v
+	}
+	return out
+}
+
 func pgArrayToSet(arr pq.ByteaArray) *schema.Set {
 	s := make([]interface{}, len(arr))
 	for i, v := range arr {
@@ -67,26 +206,300 @@ func pgArrayToSet(arr pq.ByteaArray) *schema.Set {
 	return schema.NewSet(schema.HashString, s)
 }
 
-// startTransaction starts a new DB transaction on the specified database.
-// If the database is specified and different from the one configured in the provider,
-// it will create a new connection pool if needed.
-func startTransaction(client *Client, database string) (*sql.Tx, error) {
+// databaseClient returns a client connected to the given database, the
+// client itself if it is already connected to it or if database is empty.
+func databaseClient(client *Client, database string) (*Client, error) {
 	if database != "" && database != client.databaseName {
-		var err error
-		client, err = client.config.NewClient(database)
+		dbClient, err := client.config.NewClient(database)
 		if err != nil {
-			return nil, err
+			return nil, connectionError(client.config.Username, database, err)
+		}
+		dbClient.stopCtx = client.stopCtx
+		return dbClient, nil
+	}
+	return client, nil
+}
+
+// connectionError returns err, raised when connecting to database as
+// username, with a message naming them and the likely cause.  The connections
+// are only opened by the first statement run on them, so it wraps the errors
+// of the statements opening a transaction as well.
+func connectionError(username, database string, err error) error {
+	msg := fmt.Sprintf("cannot connect to database %s as %s", database, username)
+
+	pqErr, _ := errwrap.GetType(err, &pq.Error{}).(*pq.Error)
+	switch {
+	case pqErr == nil:
+	case pqErr.Code == sqlStateInvalidCatalogName:
+		msg += ", the database does not exist"
+	case pqErr.Code == sqlStateInvalidAuthSpec || pqErr.Code == sqlStateInvalidPassword:
+		msg += ", the server rejected the authentication"
+	case pqErrorType(err) == ErrInsufficientPrivilege:
+		msg += ", the role lacks the CONNECT privilege on the database"
+	}
+
+	return errwrap.Wrapf(msg+": {{err}}", err)
+}
+
+const (
+	connectionAttr         = "connection_override"
+	connectionUsernameAttr = "username"
+	connectionPasswordAttr = "password"
+)
+
+const sessionSettingsAttr = "session_settings"
+
+// sessionSettingsSchema returns the schema of the run-time parameters set in
+// the transaction of the operations of a resource.
+func sessionSettingsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Elem:        &schema.Schema{Type: schema.TypeString},
+		Description: "Run-time parameters set in the transaction of each operation of this resource (e.g. maintenance_work_mem)",
+	}
+}
+
+// setSessionSettings sets the session settings of the resource for the rest
+// of the transaction.  set_config(..., true) is SET LOCAL with the name and
+// value passed as parameters.
+func setSessionSettings(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	settings := d.Get(sessionSettingsAttr).(map[string]interface{})
+	for _, name := range sortedKeys(settings) {
+		if _, err := txn.ExecContext(ctx, "SELECT pg_catalog.set_config($1, $2, true)", name, settings[name].(string)); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not set %s: {{err}}", name), err)
 		}
 	}
+	return nil
+}
+
+// resourceOwner returns the owner of the object created by a resource: its
+// owner attribute, or the default_owner of the provider when it is not set.
+// It is empty when neither is set.
+func resourceOwner(client *Client, d *schema.ResourceData, ownerAttr string) string {
+	if v, ok := d.GetOk(ownerAttr); ok {
+		return v.(string)
+	}
+	return client.config.DefaultOwner
+}
+
+// connectionSchema returns the schema of the connection block of a resource,
+// which overrides the credentials of the provider for this resource.
+func connectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				connectionUsernameAttr: {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "PostgreSQL user name to connect as for this resource",
+				},
+				connectionPasswordAttr: {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Password of the PostgreSQL user to connect as for this resource",
+				},
+			},
+		},
+		Description: "Connect as another user than the one of the provider for this resource",
+	}
+}
+
+// connectionClient returns a client connecting with the credentials of the
+// connection block of the resource, or client if the resource has none.  The
+// connections of the new client are not shared with other resources: they are
+// closed by the returned function once the operation is done.
+func connectionClient(client *Client, d *schema.ResourceData) (*Client, func(), error) {
+	connections := d.Get(connectionAttr).([]interface{})
+	if len(connections) == 0 || connections[0] == nil {
+		return client, func() {}, nil
+	}
+	connection := connections[0].(map[string]interface{})
+
+	config := client.config
+	config.Username = connection[connectionUsernameAttr].(string)
+	config.Password = connection[connectionPasswordAttr].(string)
+	// The transaction runs as the overriding user, not as the role assumed
+	// by the provider.
+	config.AssumeRole = ""
+
+	db, err := openDB(config.connStr(client.databaseName))
+	if err != nil {
+		return nil, nil, errwrap.Wrapf(fmt.Sprintf("Error connecting to PostgreSQL server as %s: {{err}}", config.Username), err)
+	}
+	db.SetMaxIdleConns(0)
+	db.SetMaxOpenConns(1)
+
+	connClient := &Client{
+		config:       config,
+		databaseName: client.databaseName,
+		db:           db,
+		version:      client.version,
+		flavor:       client.flavor,
+		stopCtx:      client.stopCtx,
+	}
+
+	return connClient, func() { db.Close() }, nil
+}
+
+// operationContext returns the context of a resource operation.  It is done
+// when the timeout of the operation expires or when Terraform stops the
+// provider (e.g. on Ctrl-C).
+func operationContext(client *Client, d *schema.ResourceData, timeoutKey string) (context.Context, context.CancelFunc) {
+	ctx := client.stopCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, d.Timeout(timeoutKey))
+}
+
+// operationError returns err with a clear message when it was caused by the
+// timeout of the operation or by Terraform stopping the provider, to tell them
+// apart from an SQL failure.
+func operationError(ctx context.Context, timeoutKey string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return errwrap.Wrapf(fmt.Sprintf("the %s timeout was exceeded, it can be increased in the timeouts block of the resource: {{err}}", timeoutKey), err)
+	case context.Canceled:
+		return errwrap.Wrapf(fmt.Sprintf("the %s operation was canceled: {{err}}", timeoutKey), err)
+	}
+
+	return err
+}
+
+// startTransaction starts a transaction in the given database.
+// If the provider is configured to assume a role, the transaction runs as this role.
+func startTransaction(client *Client, database string) (*sql.Tx, error) {
+	return startTransactionContext(context.Background(), client, database)
+}
+
+// startTransactionContext starts a transaction in the given database which
+// is rolled back as soon as ctx is done.  lib/pq cannot interrupt a running
+// query, so if ctx has a deadline the statement_timeout of the transaction is
+// lowered to make the server abort the statements still running past it.
+func startTransactionContext(ctx context.Context, client *Client, database string) (*sql.Tx, error) {
+	return beginTransaction(ctx, client, database, true)
+}
+
+// startConnectionUserTransactionContext is startTransactionContext without
+// the role assumed by the provider: the statements run as the connection
+// user.  Role DDL needs it as the CREATEROLE attribute of the connection user
+// is not inherited through SET ROLE.
+func startConnectionUserTransactionContext(ctx context.Context, client *Client, database string) (*sql.Tx, error) {
+	return beginTransaction(ctx, client, database, false)
+}
+
+// beginTransaction starts the transaction of startTransactionContext, running
+// as the role assumed by the provider if assumeRole is set.
+func beginTransaction(ctx context.Context, client *Client, database string, assumeRole bool) (*sql.Tx, error) {
+	client, err := databaseClient(client, database)
+	if err != nil {
+		return nil, err
+	}
 	db := client.DB()
-	txn, err := db.Begin()
+	txn, err := db.BeginTx(ctx, nil)
 	if err != nil {
+		if ctx.Err() == nil {
+			err = connectionError(client.config.Username, client.databaseName, err)
+		}
 		return nil, errwrap.Wrapf("could not start transaction: {{err}}", err)
 	}
 
+	// lib/pq does not support the isolation level of sql.TxOptions, it has
+	// to be set before any other statement of the transaction.
+	if level := client.config.IsolationLevel; level != "" {
+		if _, err := txn.ExecContext(ctx, fmt.Sprintf("SET TRANSACTION ISOLATION LEVEL %s", strings.ToUpper(level))); err != nil {
+			txn.Rollback()
+			return nil, errwrap.Wrapf(fmt.Sprintf("could not set isolation level %s: {{err}}", level), err)
+		}
+	}
+
+	// SET LOCAL only lasts until the end of the transaction so the settings
+	// are reset before the connection goes back to the pool.  set_config(...,
+	// true) is SET LOCAL with the value passed as a string, as in the startup
+	// packet, e.g. a list for search_path or a number with a unit.
+	if client.config.PgBouncerMode {
+		for _, param := range client.config.runtimeParams() {
+			if _, err := txn.ExecContext(ctx, "SELECT pg_catalog.set_config($1, $2, true)", param.name, param.value); err != nil {
+				txn.Rollback()
+				return nil, errwrap.Wrapf(fmt.Sprintf("could not set %s: {{err}}", param.name), err)
+			}
+		}
+	}
+
+	if role := client.config.AssumeRole; assumeRole && role != "" {
+		if _, err := txn.ExecContext(ctx, fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(role))); err != nil {
+			txn.Rollback()
+			return nil, errwrap.Wrapf(fmt.Sprintf("could not assume role %s: {{err}}", role), err)
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		timeoutMs := int(time.Until(deadline) / time.Millisecond)
+		if timeoutMs <= 0 {
+			txn.Rollback()
+			return nil, errwrap.Wrapf("could not start transaction: {{err}}", context.DeadlineExceeded)
+		}
+		if configured := client.config.StatementTimeoutMs; configured == nil || *configured == 0 || timeoutMs < *configured {
+			if _, err := txn.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeoutMs)); err != nil {
+				txn.Rollback()
+				return nil, errwrap.Wrapf("could not set statement_timeout: {{err}}", err)
+			}
+		}
+	}
+
 	return txn, nil
 }
 
+// execNonTransactional runs a statement which cannot be executed inside a
+// transaction block (e.g. CREATE INDEX CONCURRENTLY, VACUUM or ALTER SYSTEM)
+// in the given database, on a connection of its own and outside of any
+// transaction.  Unlike startTransactionContext, it does not assume the role
+// of the provider.  If ctx has a deadline, the statement_timeout of the
+// connection is lowered for the statement, except in PgBouncer mode where a
+// session setting would outlive it.
+func execNonTransactional(ctx context.Context, client *Client, database, query string, args ...interface{}) error {
+	client, err := databaseClient(client, database)
+	if err != nil {
+		return err
+	}
+
+	conn, err := client.DB().Conn(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			err = connectionError(client.config.Username, client.databaseName, err)
+		}
+		return errwrap.Wrapf("could not get a connection: {{err}}", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok && !client.config.PgBouncerMode {
+		timeoutMs := int(time.Until(deadline) / time.Millisecond)
+		if timeoutMs <= 0 {
+			return context.DeadlineExceeded
+		}
+		if configured := client.config.StatementTimeoutMs; configured == nil || *configured == 0 || timeoutMs < *configured {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET statement_timeout = %d", timeoutMs)); err != nil {
+				return errwrap.Wrapf("could not set statement_timeout: {{err}}", err)
+			}
+			// The connections are not kept idle in the pool, the setting is
+			// reset anyway in case they ever are.
+			defer conn.ExecContext(context.Background(), "RESET statement_timeout")
+		}
+	}
+
+	_, err = conn.ExecContext(ctx, query, args...)
+	return err
+}
+
 func dbExists(txn *sql.Tx, dbname string) (bool, error) {
 	err := txn.QueryRow("SELECT datname FROM pg_database WHERE datname=$1", dbname).Scan(&dbname)
 	switch {
@@ -111,6 +524,24 @@ func roleExists(txn *sql.Tx, rolname string) (bool, error) {
 	return true, nil
 }
 
+// checkTablespaceExists returns an error if the tablespace does not exist.
+// DEFAULT, the default tablespace of the database, always does.
+func checkTablespaceExists(q queryer, tablespace string) error {
+	if tablespace == "" || strings.ToUpper(tablespace) == "DEFAULT" {
+		return nil
+	}
+
+	err := q.QueryRow("SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = $1", tablespace).Scan(new(int))
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("tablespace %q does not exist", tablespace)
+	case err != nil:
+		return errwrap.Wrapf("could not check if tablespace exists: {{err}}", err)
+	}
+
+	return nil
+}
+
 func schemaExists(txn *sql.Tx, schemaname string) (bool, error) {
 	err := txn.QueryRow("SELECT 1 FROM pg_namespace WHERE nspname=$1", schemaname).Scan(&schemaname)
 	switch {
@@ -122,3 +553,457 @@ func schemaExists(txn *sql.Tx, schemaname string) (bool, error) {
 
 	return true, nil
 }
+
+// sortedKeys returns the keys of the map in order, to generate stable statements.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseOptions parses the options of a foreign object, stored in the catalogs
+// as "key=value" strings.
+func parseOptions(options []string) (map[string]interface{}, error) {
+	parsed := make(map[string]interface{}, len(options))
+	for i, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		if len(parts) != 2 {
+			// The option is not part of the error as it may hold a password.
+			return nil, fmt.Errorf("could not parse option %d, expected key=value", i)
+		}
+		parsed[parts[0]] = parts[1]
+	}
+	return parsed, nil
+}
+
+// createOptions returns the options of a foreign object ready to be used in
+// the OPTIONS clause of a CREATE statement.
+func createOptions(options map[string]interface{}) string {
+	opts := make([]string, 0, len(options))
+	for _, key := range sortedKeys(options) {
+		opts = append(opts, fmt.Sprintf("%s '%s'", pq.QuoteIdentifier(key), pqQuoteLiteral(options[key].(string))))
+	}
+	return strings.Join(opts, ", ")
+}
+
+// alterOptions returns the ADD, SET and DROP actions of the OPTIONS clause of
+// an ALTER statement changing the options of a foreign object from
+// oldOptions to newOptions.
+func alterOptions(oldOptions, newOptions map[string]interface{}) []string {
+	opts := []string{}
+	for _, key := range sortedKeys(oldOptions) {
+		if _, ok := newOptions[key]; !ok {
+			opts = append(opts, fmt.Sprintf("DROP %s", pq.QuoteIdentifier(key)))
+		}
+	}
+	for _, key := range sortedKeys(newOptions) {
+		value := newOptions[key].(string)
+		oldValue, ok := oldOptions[key]
+		switch {
+		case !ok:
+			opts = append(opts, fmt.Sprintf("ADD %s '%s'", pq.QuoteIdentifier(key), pqQuoteLiteral(value)))
+		case oldValue.(string) != value:
+			opts = append(opts, fmt.Sprintf("SET %s '%s'", pq.QuoteIdentifier(key), pqQuoteLiteral(value)))
+		}
+	}
+	return opts
+}
+
+// redacted replaces the sensitive values in the logs and error messages.
+const redacted = "<redacted>"
+
+// sensitiveOptions are the options whose value is redacted, e.g. the
+// PASSWORD of a role or the password option of a user mapping.
+var sensitiveOptions = []string{"password", "passfile", "sslpassword"}
+
+var (
+	// sensitiveLiteralRe matches the string literal of a sensitive option of
+	// a statement, e.g. PASSWORD 'secret' in CREATE ROLE or
+	// "password" 'secret' in the OPTIONS of a user mapping.
+	sensitiveLiteralRe = regexp.MustCompile(`(?i)\b(` + strings.Join(sensitiveOptions, "|") + `)("?\s*=?\s*)'(?:[^']|'')*'`)
+
+	// sensitiveConninfoRe matches the password of a conninfo string, e.g.
+	// password=secret in the dsn option of a foreign server.
+	sensitiveConninfoRe = regexp.MustCompile(`(?i)\b(password\s*=\s*)[^\s',)]+`)
+)
+
+// redactSQL returns query with the passwords it holds redacted, so that it
+// can be logged.
+func redactSQL(query string) string {
+	query = sensitiveLiteralRe.ReplaceAllString(query, "${1}${2}'"+redacted+"'")
+	return sensitiveConninfoRe.ReplaceAllString(query, "${1}"+redacted)
+}
+
+// logSQL logs a statement run by the provider with its passwords redacted.
+// Statements must never be logged without it.
+func logSQL(query string) {
+	log.Printf("[DEBUG] executing SQL: %s", redactSQL(query))
+}
+
+// recordSQL logs a statement run by a resource and appends it, with its
+// passwords redacted, to the list of statements stored in attr.
+func recordSQL(d *schema.ResourceData, attr, query string) {
+	logSQL(query)
+	statements := d.Get(attr).([]interface{})
+	d.Set(attr, append(statements, redactSQL(query)))
+}
+
+// redactedError is an error whose message has been redacted.  It keeps the
+// original error so that its type (e.g. *pq.Error for isRetriableError) can
+// still be found with errwrap.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) WrappedErrors() []error {
+	return []error{e.err}
+}
+
+// redactError returns err with the given sensitive values, and the passwords
+// of the statement it may quote, redacted from its message.  The server may
+// quote a part of the statement in its error, e.g. on a syntax error, so only
+// the quoted occurrences of the values are redacted.
+func redactError(err error, values ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := redactSQL(err.Error())
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		msg = strings.Replace(msg, "'"+pqQuoteLiteral(value)+"'", "'"+redacted+"'", -1)
+		msg = strings.Replace(msg, "'"+value+"'", "'"+redacted+"'", -1)
+		msg = strings.Replace(msg, `"`+value+`"`, `"`+redacted+`"`, -1)
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, msg: msg}
+}
+
+// sensitiveOptionValues returns the values of the sensitive options of a
+// foreign object (e.g. the password of a user mapping) to redact them.
+func sensitiveOptionValues(options map[string]interface{}) []string {
+	values := []string{}
+	for key, value := range options {
+		if sliceContainsStr(sensitiveOptions, strings.ToLower(key)) {
+			values = append(values, value.(string))
+		}
+	}
+	return values
+}
+
+// sqlSeparators are the characters around which whitespace is not
+// significant in normalized SQL.
+const sqlSeparators = "(),;=<>"
+
+// normalizeSQL returns the canonical form of an SQL query or expression used
+// to compare it with the version deparsed by PostgreSQL (e.g. by
+// pg_get_viewdef or pg_get_expr): comments are removed, whitespace is
+// collapsed, unquoted text is lowercased and the trailing semicolons as well as
+// the parentheses enclosing the whole statement are removed.  Quoted literals,
+// identifiers and dollar-quoted strings are kept as is.
+func normalizeSQL(query string) string {
+	tokens := sqlTokens(query)
+
+	for {
+		for len(tokens) > 0 && (tokens[len(tokens)-1] == " " || tokens[len(tokens)-1] == ";") {
+			tokens = tokens[:len(tokens)-1]
+		}
+		for len(tokens) > 0 && tokens[0] == " " {
+			tokens = tokens[1:]
+		}
+		if !enclosedInParens(tokens) {
+			break
+		}
+		tokens = tokens[1 : len(tokens)-1]
+	}
+
+	b := &bytes.Buffer{}
+	for i, token := range tokens {
+		// The whitespace next to a separator is dropped, so that "a=b" and
+		// "a = b" are the same.
+		if token == " " && (i == 0 || i == len(tokens)-1 ||
+			strings.Contains(sqlSeparators, tokens[i-1]) || strings.Contains(sqlSeparators, tokens[i+1])) {
+			continue
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// sqlTokens splits an SQL query into tokens for normalizeSQL: each quoted
+// string is a single token, any run of whitespace and comments is a single
+// space and any other character is a lowercased token of its own.
+func sqlTokens(query string) []string {
+	var tokens []string
+	space := func() {
+		if len(tokens) > 0 && tokens[len(tokens)-1] != " " {
+			tokens = append(tokens, " ")
+		}
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space()
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				end = len(query) - i
+			}
+			space()
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			space()
+			i += end
+		case c == '\'' || c == '"':
+			// An escape string (E'...') can hold backslash escaped quotes.
+			escapes := c == '\'' && len(tokens) > 0 && tokens[len(tokens)-1] == "e" &&
+				(len(tokens) == 1 || !isIdentifierToken(tokens[len(tokens)-2]))
+			end := quotedEnd(query[i:], c, escapes)
+			tokens = append(tokens, query[i:i+end])
+			i += end
+		case c == '$':
+			tag := dollarQuoteTag(query[i:])
+			if tag == "" {
+				tokens = append(tokens, "$")
+				i++
+				break
+			}
+			end := strings.Index(query[i+len(tag):], tag)
+			if end == -1 {
+				end = len(query) - i
+			} else {
+				end += 2 * len(tag)
+			}
+			tokens = append(tokens, query[i:i+end])
+			i += end
+		default:
+			tokens = append(tokens, strings.ToLower(query[i:i+1]))
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// quotedEnd returns the length of the quoted string at the start of s, quote
+// being its delimiter which is escaped by doubling it (and by a backslash if
+// escapes is true).
+func quotedEnd(s string, quote byte, escapes bool) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escapes && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// dollarQuoteTag returns the tag (e.g. $body$ or $$) of the dollar-quoted
+// string at the start of s, or "" if s starts with a parameter ($1).
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentifierToken returns whether the token is part of an unquoted
+// identifier or keyword.
+func isIdentifierToken(token string) bool {
+	c := token[0]
+	return len(token) == 1 && (c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c >= 0x80)
+}
+
+// enclosedInParens returns whether the tokens are enclosed in a single pair
+// of parentheses, e.g. "(a) AND (b)" is not.
+func enclosedInParens(tokens []string) bool {
+	if len(tokens) < 2 || tokens[0] != "(" || tokens[len(tokens)-1] != ")" {
+		return false
+	}
+	depth := 0
+	for i, token := range tokens {
+		switch token {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 && i != len(tokens)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// suppressEquivalentSQL suppresses the differences between SQL queries or
+// expressions which only differ by their formatting, see normalizeSQL.
+func suppressEquivalentSQL(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeSQL(old) == normalizeSQL(new)
+}
+
+const (
+	// txnMaxRetries is the number of times a transaction is run again after
+	// a deadlock or a serialization failure.
+	txnMaxRetries = 5
+
+	sqlStateSerializationFailure  = "40001"
+	sqlStateDeadlockDetected      = "40P01"
+	sqlStateInvalidCatalogName    = "3D000"
+	sqlStateInvalidAuthSpec       = "28000"
+	sqlStateInvalidPassword       = "28P01"
+	sqlStateUndefinedObject       = "42704"
+	sqlStateInvalidSchemaName     = "3F000"
+	sqlStateUndefinedFunction     = "42883"
+	sqlStateUndefinedTable        = "42P01"
+	sqlStateInsufficientPrivilege = "42501"
+)
+
+// Transaction isolation levels of the isolation_level provider setting.
+const (
+	isolationReadCommitted  = "read committed"
+	isolationRepeatableRead = "repeatable read"
+	isolationSerializable   = "serializable"
+)
+
+// txnRetryBaseDelay is the delay before the first retry of a transaction,
+// doubled on each retry.
+var txnRetryBaseDelay = 100 * time.Millisecond
+
+// withTxnRetry runs fn, which is expected to run and commit a whole
+// transaction, again when it fails on a deadlock or a serialization failure
+// caused by concurrent catalog updates.
+func withTxnRetry(fn func() error) error {
+	delay := txnRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= txnMaxRetries || !isRetriableError(err) {
+			return err
+		}
+
+		// Add some jitter so concurrent transactions do not retry in lockstep.
+		sleep := delay
+		if delay > 0 {
+			sleep += time.Duration(rand.Int63n(int64(delay)))
+		}
+		log.Printf("[WARN] transaction failed, retrying in %s: %v", sleep, err)
+		time.Sleep(sleep)
+		delay *= 2
+	}
+}
+
+// retrySerializationFailures makes the create, update and delete operations
+// of the resource run again on a serialization failure or a deadlock when the
+// provider uses the serializable isolation level, as expected by PostgreSQL.
+// A failed operation is run again as a whole, so the resource has to run each
+// operation in a single transaction, which has been rolled back, and must not
+// change its ID before the commit.
+func retrySerializationFailures(r *schema.Resource) {
+	wrap := func(fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+		if fn == nil {
+			return nil
+		}
+		return func(d *schema.ResourceData, meta interface{}) error {
+			if meta.(*Client).config.IsolationLevel != isolationSerializable {
+				return fn(d, meta)
+			}
+			return withTxnRetry(func() error {
+				return fn(d, meta)
+			})
+		}
+	}
+
+	r.Create = wrap(r.Create)
+	r.Update = wrap(r.Update)
+	r.Delete = wrap(r.Delete)
+}
+
+// isRetriableError returns true if err, or one of the errors it wraps, is a
+// PostgreSQL deadlock or serialization failure.
+func isRetriableError(err error) bool {
+	pqErr, ok := errwrap.GetType(err, &pq.Error{}).(*pq.Error)
+	if !ok {
+		return false
+	}
+	return pqErr.Code == sqlStateSerializationFailure || pqErr.Code == sqlStateDeadlockDetected
+}
+
+// isDatabaseNotFoundError returns true if err, or one of the errors it wraps,
+// is raised because the database to connect to does not exist.
+func isDatabaseNotFoundError(err error) bool {
+	pqErr, ok := errwrap.GetType(err, &pq.Error{}).(*pq.Error)
+	return ok && pqErr.Code == sqlStateInvalidCatalogName
+}
+
+// isUnrecognizedParameterError returns true if err, or one of the errors it
+// wraps, is raised because the server does not know the run-time parameter
+// name, e.g. when it is sent when connecting to an older server.
+func isUnrecognizedParameterError(err error, name string) bool {
+	pqErr, ok := errwrap.GetType(err, &pq.Error{}).(*pq.Error)
+	return ok && pqErr.Code == sqlStateUndefinedObject && strings.Contains(pqErr.Message, fmt.Sprintf("%q", name))
+}
+
+var (
+	// ErrObjectNotFound is the type of the PostgreSQL errors raised when the
+	// object a statement refers to does not exist.
+	ErrObjectNotFound = errors.New("object not found")
+
+	// ErrInsufficientPrivilege is the type of the PostgreSQL errors raised
+	// when the connection user lacks a privilege.
+	ErrInsufficientPrivilege = errors.New("insufficient privilege")
+)
+
+// sqlStateErrors maps the SQLSTATE codes of PostgreSQL errors to their type.
+var sqlStateErrors = map[pq.ErrorCode]error{
+	sqlStateInvalidCatalogName: ErrObjectNotFound,
+	sqlStateInvalidSchemaName:  ErrObjectNotFound,
+	sqlStateUndefinedObject:    ErrObjectNotFound,
+	sqlStateUndefinedFunction:  ErrObjectNotFound,
+	sqlStateUndefinedTable:     ErrObjectNotFound,
+
+	sqlStateInsufficientPrivilege: ErrInsufficientPrivilege,
+}
+
+// pqErrorType returns the type of err (ErrObjectNotFound or
+// ErrInsufficientPrivilege) if it, or one of the errors it wraps, is a
+// PostgreSQL error of one of these types, nil otherwise.
+func pqErrorType(err error) error {
+	pqErr, ok := errwrap.GetType(err, &pq.Error{}).(*pq.Error)
+	if !ok {
+		return nil
+	}
+	return sqlStateErrors[pqErr.Code]
+}
@@ -0,0 +1,523 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{"role", true},
+		{"", false},
+		{"ro\x00le", false},
+		{strings.Repeat("a", 63), true},
+		{strings.Repeat("a", 64), false},
+		// é is 2 bytes long in UTF-8.
+		{strings.Repeat("é", 31) + "a", true},
+		{strings.Repeat("é", 32), false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateIdentifier(c.name, "name")
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("validateIdentifier(%q) valid = %t, expected %t: %v", c.name, valid, c.valid, errs)
+		}
+	}
+}
+
+func TestValidateBoolString(t *testing.T) {
+	for value, expected := range map[string]string{"true": "true", "1": "true", "false": "false", "0": "false"} {
+		if _, errs := validateBoolString(value, "attr"); len(errs) != 0 {
+			t.Errorf("validateBoolString(%q) returned %v", value, errs)
+		}
+		if normalized := normalizeBoolString(value); normalized != expected {
+			t.Errorf("normalizeBoolString(%q) = %q, expected %q", value, normalized, expected)
+		}
+	}
+
+	if _, errs := validateBoolString("yes", "attr"); len(errs) == 0 {
+		t.Errorf("validateBoolString(%q) should return an error", "yes")
+	}
+}
+
+func TestValidateSchemaName(t *testing.T) {
+	cases := []struct {
+		name  string
+		valid bool
+	}{
+		{"public", true},
+		{"my_schema", true},
+		{"PG_schema", true},
+		{"schema_pg_", true},
+		{"", false},
+		{"pg_", false},
+		{"pg_catalog", false},
+		{"pg_my_schema", false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateSchemaName(c.name, "name")
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("validateSchemaName(%q) valid = %t, expected %t: %v", c.name, valid, c.valid, errs)
+		}
+	}
+}
+
+func TestWithTxnRetry(t *testing.T) {
+	txnRetryBaseDelay = 0
+
+	cases := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		expectErr     bool
+	}{
+		{
+			name:          "success",
+			errs:          []error{nil},
+			expectedCalls: 1,
+		},
+		{
+			name: "deadlock then success",
+			errs: []error{
+				&pq.Error{Code: sqlStateDeadlockDetected},
+				errwrap.Wrapf("could not commit transaction: {{err}}", &pq.Error{Code: sqlStateSerializationFailure}),
+				nil,
+			},
+			expectedCalls: 3,
+		},
+		{
+			name:          "not retriable",
+			errs:          []error{&pq.Error{Code: "42501"}},
+			expectedCalls: 1,
+			expectErr:     true,
+		},
+		{
+			name:          "not a PostgreSQL error",
+			errs:          []error{errors.New("connection refused")},
+			expectedCalls: 1,
+			expectErr:     true,
+		},
+	}
+
+	for _, c := range cases {
+		calls := 0
+		err := withTxnRetry(func() error {
+			err := c.errs[calls]
+			calls++
+			return err
+		})
+
+		if calls != c.expectedCalls {
+			t.Errorf("%s: expected %d calls, got %d", c.name, c.expectedCalls, calls)
+		}
+		if (err != nil) != c.expectErr {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		}
+	}
+}
+
+func TestWithTxnRetryGivesUp(t *testing.T) {
+	txnRetryBaseDelay = 0
+
+	calls := 0
+	err := withTxnRetry(func() error {
+		calls++
+		return &pq.Error{Code: sqlStateDeadlockDetected}
+	})
+
+	if err == nil {
+		t.Fatal("expected the deadlock error to be returned")
+	}
+	if calls != txnMaxRetries+1 {
+		t.Errorf("expected %d calls, got %d", txnMaxRetries+1, calls)
+	}
+}
+
+func TestIsDatabaseNotFoundError(t *testing.T) {
+	err := errwrap.Wrapf("error detecting capabilities: {{err}}", &pq.Error{Code: "3D000"})
+	if !isDatabaseNotFoundError(err) {
+		t.Errorf("isDatabaseNotFoundError(%v) = false, expected true", err)
+	}
+
+	err = errwrap.Wrapf("Error reading type: {{err}}", &pq.Error{Code: "42P01"})
+	if isDatabaseNotFoundError(err) {
+		t.Errorf("isDatabaseNotFoundError(%v) = true, expected false", err)
+	}
+}
+
+func TestIsUnrecognizedParameterError(t *testing.T) {
+	err := errwrap.Wrapf("could not reach the PostgreSQL server: {{err}}", &pq.Error{
+		Code: "42704", Message: `unrecognized configuration parameter "lock_timeout"`,
+	})
+	if !isUnrecognizedParameterError(err, "lock_timeout") {
+		t.Errorf("isUnrecognizedParameterError(%v) = false, expected true", err)
+	}
+	if isUnrecognizedParameterError(err, "statement_timeout") {
+		t.Errorf("isUnrecognizedParameterError(%v, statement_timeout) = true, expected false", err)
+	}
+
+	err = &pq.Error{Code: "42P01", Message: `relation "lock_timeout" does not exist`}
+	if isUnrecognizedParameterError(err, "lock_timeout") {
+		t.Errorf("isUnrecognizedParameterError(%v) = true, expected false", err)
+	}
+}
+
+func TestPQErrorType(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected error
+	}{
+		{&pq.Error{Code: "3D000"}, ErrObjectNotFound},
+		{&pq.Error{Code: "3F000"}, ErrObjectNotFound},
+		{&pq.Error{Code: "42704"}, ErrObjectNotFound},
+		{&pq.Error{Code: "42883"}, ErrObjectNotFound},
+		{&pq.Error{Code: "42P01"}, ErrObjectNotFound},
+		{&pq.Error{Code: "42501"}, ErrInsufficientPrivilege},
+		{errwrap.Wrapf("Error reading role: {{err}}", &pq.Error{Code: "42501"}), ErrInsufficientPrivilege},
+		{&pq.Error{Code: "40P01"}, nil},
+		{errors.New("42P01"), nil},
+	}
+
+	for _, c := range cases {
+		if got := pqErrorType(c.err); got != c.expected {
+			t.Errorf("pqErrorType(%v) = %v, expected %v", c.err, got, c.expected)
+		}
+	}
+}
+
+func TestOperationError(t *testing.T) {
+	err := errors.New("pq: canceling statement due to statement timeout")
+
+	if got := operationError(context.Background(), "create", err); got != err {
+		t.Errorf("expected the error to be returned unchanged, got %v", got)
+	}
+
+	if got := operationError(context.Background(), "create", nil); got != nil {
+		t.Errorf("expected no error, got %v", got)
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	if got := operationError(expired, "create", err); !strings.Contains(got.Error(), "create timeout was exceeded") {
+		t.Errorf("expected a timeout error, got %v", got)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if got := operationError(canceled, "delete", err); !strings.Contains(got.Error(), "delete operation was canceled") {
+		t.Errorf("expected a cancellation error, got %v", got)
+	}
+}
+
+func TestAccStartTransactionContext(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	config := getTestConfig(t)
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	// A slow query is aborted once the deadline of the context is exceeded.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	txn, err := startTransactionContext(ctx, client, "")
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer txn.Rollback()
+
+	start := time.Now()
+	if _, err := txn.ExecContext(ctx, "SELECT pg_sleep(30)"); err == nil {
+		t.Fatal("expected the query to be aborted")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the query to be aborted after about 1s, it took %s", elapsed)
+	}
+
+	// No transaction is started with a canceled context.
+	canceledCtx, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	if txn, err := startTransactionContext(canceledCtx, client, ""); err == nil {
+		txn.Rollback()
+		t.Error("expected an error starting a transaction with a canceled context")
+	}
+}
+
+func TestAccExecNonTransactional(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+	config := getTestConfig(t)
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	const query = "CREATE INDEX CONCURRENTLY test_table_val_idx ON test_table (val)"
+
+	// The statement cannot run in a transaction block.
+	txn, err := startTransaction(client, dbName)
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	_, err = txn.Exec(query)
+	txn.Rollback()
+	if err == nil || !strings.Contains(err.Error(), "cannot run inside a transaction block") {
+		t.Fatalf("expected an error running %q in a transaction, got %v", query, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := execNonTransactional(ctx, client, dbName, query); err != nil {
+		t.Fatalf("could not run %q: %v", query, err)
+	}
+	if err := execNonTransactional(ctx, client, dbName, "VACUUM test_table"); err != nil {
+		t.Fatalf("could not vacuum: %v", err)
+	}
+
+	var exists bool
+	txn, err = startTransaction(client, dbName)
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer txn.Rollback()
+	if err := txn.QueryRow("SELECT to_regclass('test_table_val_idx') IS NOT NULL").Scan(&exists); err != nil {
+		t.Fatalf("could not check the index: %v", err)
+	}
+	if !exists {
+		t.Error("expected index test_table_val_idx to be created")
+	}
+}
+
+func TestAccStartTransactionIsolationLevel(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	config := getTestConfig(t)
+	config.IsolationLevel = isolationSerializable
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	txn, err := startTransaction(client, "")
+	if err != nil {
+		t.Fatalf("could not start transaction: %v", err)
+	}
+	defer txn.Rollback()
+
+	var level string
+	if err := txn.QueryRow("SHOW transaction_isolation").Scan(&level); err != nil {
+		t.Fatalf("could not read isolation level: %v", err)
+	}
+	if level != isolationSerializable {
+		t.Errorf("expected transaction isolation level %q, got %q", isolationSerializable, level)
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	cases := []struct {
+		input    string
+		stored   string
+		expected bool
+	}{
+		// Expressions as deparsed by pg_get_expr.
+		{"val = current_user", "(val = CURRENT_USER)", true},
+		{"(val='test'::text)", "(val = 'test'::text)", true},
+		{"(a) AND (b)", "a) AND (b", false},
+		{"((a > 1))", "(a > 1)", true},
+		{"val = 'Test'", "(val = 'test')", false},
+		{`"Val" = 1`, "(val = 1)", false},
+		// Queries as deparsed by pg_get_viewdef.
+		{
+			"SELECT val, count(*) FROM test_table GROUP BY val;",
+			" SELECT val,\n    count(*)\n   FROM test_table\n  GROUP BY val;",
+			true,
+		},
+		{"select val from test_table -- all rows\n", " SELECT val\n   FROM test_table;", true},
+		{"SELECT /* values */ val FROM test_table", " SELECT val\n   FROM test_table;", true},
+		{"SELECT 'a  b'", "SELECT 'a b'", false},
+		{"SELECT 'it''s  ok'", " SELECT 'it''s  ok';", true},
+		{`SELECT E'it\'s  ok' ;`, ` SELECT E'it\'s  ok';`, true},
+		{"SELECT $1", "select $1", true},
+		// Function bodies.
+		{
+			"CREATE FUNCTION f() RETURNS int AS $body$ SELECT  1 $body$ LANGUAGE sql;",
+			"create function f() returns int as $body$ SELECT  1 $body$ language sql",
+			true,
+		},
+		{"SELECT $$a  b$$", "SELECT $$a b$$", false},
+	}
+
+	for _, c := range cases {
+		if equivalent := suppressEquivalentSQL("query", c.input, c.stored, nil); equivalent != c.expected {
+			t.Errorf("%q and %q equivalent = %t, expected %t (normalized: %q, %q)",
+				c.input, c.stored, equivalent, c.expected, normalizeSQL(c.input), normalizeSQL(c.stored))
+		}
+	}
+}
+
+func TestPrivilegesEqual(t *testing.T) {
+	cases := []struct {
+		version    string
+		objectType string
+		a          []string
+		b          []string
+		expected   bool
+	}{
+		{"16.0.0", "table", []string{"ALL"}, []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}, true},
+		// MAINTAIN is also granted by ALL since PostgreSQL 17.
+		{"17.0.0", "table", []string{"ALL"}, []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER", "MAINTAIN"}, true},
+		{"17.0.0", "table", []string{"ALL"}, []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER"}, false},
+		{"16.0.0", "table", []string{"ALL"}, []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES"}, false},
+		{"16.0.0", "table", []string{"SELECT", "INSERT"}, []string{"INSERT", "SELECT"}, true},
+		{"16.0.0", "table", []string{"SELECT"}, []string{"SELECT", "INSERT"}, false},
+		{"16.0.0", "sequence", []string{"USAGE", "SELECT", "UPDATE"}, []string{"ALL"}, true},
+		{"16.0.0", "sequence", []string{"USAGE", "SELECT"}, []string{"ALL"}, false},
+		{"16.0.0", "function", []string{"EXECUTE"}, []string{"ALL"}, true},
+	}
+
+	for _, c := range cases {
+		client := &Client{version: semver.MustParse(c.version)}
+		a := schema.NewSet(schema.HashString, stringsToInterfaces(c.a))
+		b := schema.NewSet(schema.HashString, stringsToInterfaces(c.b))
+		if equal := privilegesEqual(client, c.objectType, a, b); equal != c.expected {
+			t.Errorf("%s privileges %v and %v equal = %t on PostgreSQL %s, expected %t", c.objectType, c.a, c.b, equal, c.version, c.expected)
+		}
+	}
+}
+
+func TestValidatePrivilegesMaintain(t *testing.T) {
+	privileges := []interface{}{"SELECT", "MAINTAIN"}
+	if err := validatePrivileges(&Client{version: semver.MustParse("16.0.0")}, "table", privileges); err == nil {
+		t.Error("MAINTAIN should not be allowed before PostgreSQL 17")
+	}
+	if err := validatePrivileges(&Client{version: semver.MustParse("17.0.0")}, "table", privileges); err != nil {
+		t.Errorf("MAINTAIN should be allowed since PostgreSQL 17: %v", err)
+	}
+}
+
+func TestRedactSQL(t *testing.T) {
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"CREATE ROLE \"r\" LOGIN ENCRYPTED PASSWORD 'secret'",
+			"CREATE ROLE \"r\" LOGIN ENCRYPTED PASSWORD '<redacted>'",
+		},
+		{
+			"ALTER ROLE \"r\" PASSWORD 'it''s secret' VALID UNTIL 'infinity'",
+			"ALTER ROLE \"r\" PASSWORD '<redacted>' VALID UNTIL 'infinity'",
+		},
+		{"ALTER ROLE \"r\" PASSWORD NULL", "ALTER ROLE \"r\" PASSWORD NULL"},
+		{
+			`CREATE USER MAPPING FOR "r" SERVER "s" OPTIONS ("password" 'secret', "user" 'u')`,
+			`CREATE USER MAPPING FOR "r" SERVER "s" OPTIONS ("password" '<redacted>', "user" 'u')`,
+		},
+		{
+			`ALTER USER MAPPING FOR "r" SERVER "s" OPTIONS (SET "password" 'secret', ADD "passfile" '/p')`,
+			`ALTER USER MAPPING FOR "r" SERVER "s" OPTIONS (SET "password" '<redacted>', ADD "passfile" '<redacted>')`,
+		},
+		{
+			`CREATE SERVER "s" FOREIGN DATA WRAPPER "dblink_fdw" OPTIONS ("dsn" 'host=h password=secret')`,
+			`CREATE SERVER "s" FOREIGN DATA WRAPPER "dblink_fdw" OPTIONS ("dsn" 'host=h password=<redacted>')`,
+		},
+	}
+
+	for _, c := range cases {
+		if redactedSQL := redactSQL(c.query); redactedSQL != c.expected {
+			t.Errorf("redactSQL(%q) = %q, expected %q", c.query, redactedSQL, c.expected)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	err := errors.New(`pq: syntax error at or near "secret"`)
+	if redactedErr := redactError(err, "secret"); redactedErr.Error() != `pq: syntax error at or near "<redacted>"` {
+		t.Errorf("redactError(%q) = %q", err, redactedErr)
+	}
+
+	err = errors.New(`pq: password "secret" is too short, secretary`)
+	if redactedErr := redactError(err, "secret"); redactedErr.Error() != `pq: password "<redacted>" is too short, secretary` {
+		t.Errorf("redactError(%q) = %q, expected only the quoted value redacted", err, redactedErr)
+	}
+
+	err = &pq.Error{Code: sqlStateSerializationFailure, Message: `could not serialize access near 'secret'`}
+	redactedErr := redactError(err, "secret")
+	if redactedErr.Error() != `pq: could not serialize access near '<redacted>'` {
+		t.Errorf("redactError(%q) = %q", err, redactedErr)
+	}
+	if !isRetriableError(errwrap.Wrapf("error creating role: {{err}}", redactedErr)) {
+		t.Errorf("redactError(%q) should keep the *pq.Error for isRetriableError", err)
+	}
+
+	err = errors.New("pq: role already exists")
+	if redactedErr := redactError(err, "secret", ""); redactedErr != err {
+		t.Errorf("redactError(%q) = %q, expected the error unchanged", err, redactedErr)
+	}
+
+	if redactError(nil, "secret") != nil {
+		t.Errorf("redactError(nil) should be nil")
+	}
+}
+
+func TestConnectionError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{
+			&pq.Error{Code: "42501", Message: `permission denied for database "db"`},
+			`cannot connect to database db as user, the role lacks the CONNECT privilege on the database: pq: permission denied for database "db"`,
+		},
+		{
+			&pq.Error{Code: "3D000", Message: `database "db" does not exist`},
+			`cannot connect to database db as user, the database does not exist: pq: database "db" does not exist`,
+		},
+		{
+			errwrap.Wrapf("error detecting capabilities: {{err}}", &pq.Error{Code: "28P01", Message: `password authentication failed for user "user"`}),
+			`cannot connect to database db as user, the server rejected the authentication: error detecting capabilities: pq: password authentication failed for user "user"`,
+		},
+		{
+			errors.New("dial tcp: connection refused"),
+			"cannot connect to database db as user: dial tcp: connection refused",
+		},
+	}
+
+	for _, c := range cases {
+		err := connectionError("user", "db", c.err)
+		if err.Error() != c.expected {
+			t.Errorf("connectionError(%v) = %q, expected %q", c.err, err, c.expected)
+		}
+		if isDatabaseNotFoundError(c.err) != isDatabaseNotFoundError(err) {
+			t.Errorf("connectionError(%v) does not wrap the original error", c.err)
+		}
+	}
+}
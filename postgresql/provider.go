@@ -2,10 +2,12 @@ package postgresql
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -14,9 +16,13 @@ const (
 	defaultExpectedPostgreSQLVersion  = "9.0.0"
 )
 
+// providerVersion is the version of this provider.  It is overridden at build
+// time with: -ldflags "-X github.com/terraform-providers/terraform-provider-postgresql/postgresql.providerVersion=x.y.z"
+var providerVersion = "dev"
+
 // Provider returns a terraform.ResourceProvider.
 func Provider() terraform.ResourceProvider {
-	return &schema.Provider{
+	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"host": {
 				Type:        schema.TypeString,
@@ -24,6 +30,23 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc("PGHOST", nil),
 				Description: "Name of PostgreSQL server address to connect to",
 			},
+			"hosts": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"host"},
+				Description:   "The PostgreSQL servers (host or host:port) tried in order to connect to, instead of host",
+			},
+			"target_session_attrs": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  targetSessionAttrsAny,
+				ValidateFunc: validation.StringInSlice([]string{
+					targetSessionAttrsAny,
+					targetSessionAttrsReadWrite,
+				}, false),
+				Description: "Set to read-write to only connect to a server accepting read-write transactions, i.e. the primary",
+			},
 			"port": {
 				Type:        schema.TypeInt,
 				Optional:    true,
@@ -81,19 +104,123 @@ func Provider() terraform.ResourceProvider {
 				Description:  "Specify the expected version of PostgreSQL.",
 				ValidateFunc: validateExpectedVersion,
 			},
+			"application_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("PGAPPNAME", tfAppName()),
+				Description: "The application_name reported by the connections in pg_stat_activity",
+			},
+			"search_path": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateIdentifier,
+				},
+				Description: "The list of schemas set as the search_path of every connection",
+			},
+			"statement_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      -1,
+				Description:  "Abort any statement that takes more than the specified number of milliseconds. Zero disables the timeout, -1 keeps the server default.",
+				ValidateFunc: validateTimeoutMs,
+			},
+			"lock_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      -1,
+				Description:  "Abort any statement that waits longer than the specified number of milliseconds to acquire a lock. Zero disables the timeout, -1 keeps the server default.",
+				ValidateFunc: validateTimeoutMs,
+			},
+			"assume_role": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Role to SET ROLE to at the beginning of every transaction so created objects are owned by it",
+			},
+			"pgbouncer_mode": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Connect through PgBouncer in transaction pooling mode: do not use prepared statements nor session-level settings",
+			},
+			"isolation_level": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "",
+				ValidateFunc: validation.StringInSlice([]string{
+					"",
+					isolationReadCommitted,
+					isolationRepeatableRead,
+					isolationSerializable,
+				}, true),
+				Description: "The isolation level of the transactions run by the provider, the server default if not set",
+			},
+			"default_owner": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role owning the databases and schemas created without an owner, the connection user if not set",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"postgresql_database":           resourcePostgreSQLDatabase(),
-			"postgresql_extension":          resourcePostgreSQLExtension(),
-			"postgresql_schema":             resourcePostgreSQLSchema(),
-			"postgresql_role":               resourcePostgreSQLRole(),
-			"postgresql_grant":              resourcePostgreSQLGrant(),
-			"postgresql_default_privileges": resourcePostgreSQLDefaultPrivileges(),
+			"postgresql_database":            resourcePostgreSQLDatabase(),
+			"postgresql_database_grant":      resourcePostgreSQLDatabaseGrant(),
+			"postgresql_extension":           resourcePostgreSQLExtension(),
+			"postgresql_materialized_view":   resourcePostgreSQLMaterializedView(),
+			"postgresql_replication_slot":    resourcePostgreSQLReplicationSlot(),
+			"postgresql_schema":              resourcePostgreSQLSchema(),
+			"postgresql_server":              resourcePostgreSQLServer(),
+			"postgresql_setting":             resourcePostgreSQLSetting(),
+			"postgresql_table":               resourcePostgreSQLTable(),
+			"postgresql_table_rls":           resourcePostgreSQLTableRLS(),
+			"postgresql_type":                resourcePostgreSQLType(),
+			"postgresql_user_mapping":        resourcePostgreSQLUserMapping(),
+			"postgresql_role":                resourcePostgreSQLRole(),
+			"postgresql_roles":               resourcePostgreSQLRoles(),
+			"postgresql_row_security_policy": resourcePostgreSQLRowSecurityPolicy(),
+			"postgresql_grant":               resourcePostgreSQLGrant(),
+			"postgresql_default_privileges":  resourcePostgreSQLDefaultPrivileges(),
+		},
+
+		DataSourcesMap: map[string]*schema.Resource{
+			"postgresql_available_extensions": dataSourcePostgreSQLAvailableExtensions(),
+			"postgresql_database":             dataSourcePostgreSQLDatabase(),
+			"postgresql_extensions":           dataSourcePostgreSQLExtensions(),
+			"postgresql_grant":                dataSourcePostgreSQLGrant(),
+			"postgresql_role_members":         dataSourcePostgreSQLRoleMembers(),
+			"postgresql_tables":               dataSourcePostgreSQLTables(),
 		},
+	}
+
+	// Only the resources whose create, update and delete operations each run
+	// in a single transaction can be retried as a whole, the other ones would
+	// run again the statements already committed.  postgresql_grant and
+	// postgresql_default_privileges retry their transactions themselves.
+	for _, name := range []string{
+		"postgresql_database_grant",
+		"postgresql_extension",
+		"postgresql_materialized_view",
+		"postgresql_role",
+		"postgresql_roles",
+		"postgresql_row_security_policy",
+		"postgresql_schema",
+		"postgresql_table",
+		"postgresql_table_rls",
+	} {
+		retrySerializationFailures(p.ResourcesMap[name])
+	}
 
-		ConfigureFunc: providerConfigure,
+	p.ConfigureFunc = func(d *schema.ResourceData) (interface{}, error) {
+		client, err := providerConfigure(d)
+		if err != nil {
+			return nil, err
+		}
+		client.(*Client).stopCtx = p.StopContext()
+		return client, nil
 	}
+
+	return p
 }
 
 func validateConnTimeout(v interface{}, key string) (warnings []string, errors []error) {
@@ -104,6 +231,14 @@ func validateConnTimeout(v interface{}, key string) (warnings []string, errors [
 	return
 }
 
+func validateTimeoutMs(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(int)
+	if value < -1 {
+		errors = append(errors, fmt.Errorf("%s can not be less than -1", key))
+	}
+	return
+}
+
 func validateExpectedVersion(v interface{}, key string) (warnings []string, errors []error) {
 	if _, err := semver.Parse(v.(string)); err != nil {
 		errors = append(errors, fmt.Errorf("invalid version (%q): %v", v.(string), err))
@@ -133,25 +268,48 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	version, _ := semver.Parse(versionStr)
 
 	config := Config{
-		Host:              d.Get("host").(string),
-		Port:              d.Get("port").(int),
-		Username:          d.Get("username").(string),
-		Password:          d.Get("password").(string),
-		SSLMode:           sslMode,
-		ApplicationName:   tfAppName(),
-		ConnectTimeoutSec: d.Get("connect_timeout").(int),
-		MaxConns:          d.Get("max_connections").(int),
-		ExpectedVersion:   version,
+		Host:               d.Get("host").(string),
+		Port:               d.Get("port").(int),
+		Username:           d.Get("username").(string),
+		Password:           d.Get("password").(string),
+		SSLMode:            sslMode,
+		ApplicationName:    d.Get("application_name").(string),
+		ConnectTimeoutSec:  d.Get("connect_timeout").(int),
+		MaxConns:           d.Get("max_connections").(int),
+		ExpectedVersion:    version,
+		AssumeRole:         d.Get("assume_role").(string),
+		PgBouncerMode:      d.Get("pgbouncer_mode").(bool),
+		IsolationLevel:     strings.ToLower(d.Get("isolation_level").(string)),
+		DefaultOwner:       d.Get("default_owner").(string),
+		TargetSessionAttrs: d.Get("target_session_attrs").(string),
+	}
+
+	// The default, -1, sends no statement_timeout nor lock_timeout.
+	if timeoutMs := d.Get("statement_timeout_ms").(int); timeoutMs >= 0 {
+		config.StatementTimeoutMs = &timeoutMs
+	}
+	if timeoutMs := d.Get("lock_timeout_ms").(int); timeoutMs >= 0 {
+		// Checked against the version of the server by NewClient.
+		config.LockTimeoutMs = &timeoutMs
+	}
+
+	for _, h := range d.Get("hosts").([]interface{}) {
+		config.Hosts = append(config.Hosts, h.(string))
+	}
+
+	for _, s := range d.Get("search_path").([]interface{}) {
+		config.SearchPath = append(config.SearchPath, s.(string))
 	}
 
-	client, err := config.NewClient(d.Get("database").(string))
+	database := d.Get("database").(string)
+	client, err := config.NewClient(database)
 	if err != nil {
-		return nil, errwrap.Wrapf("Error initializing PostgreSQL client: {{err}}", err)
+		return nil, errwrap.Wrapf("Error initializing PostgreSQL client: {{err}}", connectionError(config.Username, database, err))
 	}
 
 	return client, nil
 }
 
 func tfAppName() string {
-	return fmt.Sprintf("Terraform v%s", terraform.VersionString())
+	return fmt.Sprintf("terraform-provider-postgresql %s (Terraform v%s)", providerVersion, terraform.VersionString())
 }
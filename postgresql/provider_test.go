@@ -1,9 +1,11 @@
 package postgresql
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -37,3 +39,152 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("PGUSER must be set for acceptance tests")
 	}
 }
+
+func TestAccPostgresqlProvider_SearchPath(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlProviderSearchPathConfig,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+
+						var searchPath string
+						if err := client.DB().QueryRow("SHOW search_path").Scan(&searchPath); err != nil {
+							return fmt.Errorf("could not read search_path: %v", err)
+						}
+						if searchPath != `"tf tests search path", public` {
+							return fmt.Errorf("unexpected search_path: %q", searchPath)
+						}
+						return nil
+					},
+					// The extension is created in the first schema of the search_path.
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.search_path", "schema", "tf tests search path"),
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlProviderSearchPathConfig = `
+provider "postgresql" {
+  search_path = ["tf tests search path", "public"]
+}
+
+resource "postgresql_schema" "search_path" {
+  name = "tf tests search path"
+}
+
+resource "postgresql_extension" "search_path" {
+  name       = "pg_trgm"
+  depends_on = ["postgresql_schema.search_path"]
+}
+`
+
+func TestAccPostgresqlProvider_ApplicationName(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlProviderApplicationNameConfig,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+
+						var appName string
+						if err := client.DB().QueryRow("SELECT current_setting('application_name')").Scan(&appName); err != nil {
+							return fmt.Errorf("could not read application_name: %v", err)
+						}
+						if appName != "tf-tests-app-name" {
+							return fmt.Errorf("unexpected application_name: %q", appName)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+var testAccPostgresqlProviderApplicationNameConfig = `
+provider "postgresql" {
+  application_name = "tf-tests-app-name"
+}
+
+resource "postgresql_schema" "application_name" {
+  name = "tf_tests_application_name"
+}
+`
+
+func TestAccPostgresqlProvider_StatementTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlProviderStatementTimeoutConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSessionSetting("statement_timeout", "1min"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlProvider_LockTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlProviderLockTimeoutConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSessionSetting("lock_timeout", "5s"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlSessionSetting(name, expected string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var value string
+		if err := client.DB().QueryRow("SELECT current_setting($1)", name).Scan(&value); err != nil {
+			return fmt.Errorf("could not read %s: %v", name, err)
+		}
+		if value != expected {
+			return fmt.Errorf("unexpected %s: expected %q, got %q", name, expected, value)
+		}
+		return nil
+	}
+}
+
+var testAccPostgresqlProviderStatementTimeoutConfig = `
+provider "postgresql" {
+  statement_timeout_ms = 60000
+}
+
+resource "postgresql_schema" "statement_timeout" {
+  name = "tf_tests_statement_timeout"
+}
+`
+
+var testAccPostgresqlProviderLockTimeoutConfig = `
+provider "postgresql" {
+  lock_timeout_ms = 5000
+}
+
+resource "postgresql_schema" "lock_timeout" {
+  name = "tf_tests_lock_timeout"
+}
+`
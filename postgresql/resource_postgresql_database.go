@@ -6,10 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/lib/pq"
 )
 
@@ -19,11 +22,15 @@ const (
 	dbCollationAttr  = "lc_collate"
 	dbConnLimitAttr  = "connection_limit"
 	dbEncodingAttr   = "encoding"
+	dbICULocaleAttr  = "icu_locale"
 	dbIsTemplateAttr = "is_template"
+	dbLocProvAttr    = "locale_provider"
 	dbNameAttr       = "name"
 	dbOwnerAttr      = "owner"
 	dbTablespaceAttr = "tablespace_name"
 	dbTemplateAttr   = "template"
+
+	dbTerminateTemplateConnsAttr = "terminate_template_connections"
 )
 
 func resourcePostgreSQLDatabase() *schema.Resource {
@@ -57,11 +64,13 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Description: "The name of the template from which to create the new database",
 			},
 			dbEncodingAttr: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Computed:    true,
-				ForceNew:    true,
-				Description: "Character set encoding to use in the new database",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDBEncoding,
+				DiffSuppressFunc: suppressEquivalentEncodings,
+				Description:      "Character set encoding to use in the new database",
 			},
 			dbCollationAttr: {
 				Type:        schema.TypeString,
@@ -77,6 +86,24 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				ForceNew:    true,
 				Description: "Character classification (LC_CTYPE) to use in the new database",
 			},
+			dbLocProvAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"libc",
+					"icu",
+				}, false),
+				Description: "Locale provider to use in the new database (one of: libc, icu), PostgreSQL 15+",
+			},
+			dbICULocaleAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "ICU locale to use in the new database with the icu locale provider, PostgreSQL 15+",
+			},
 			dbTablespaceAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -102,6 +129,12 @@ func resourcePostgreSQLDatabase() *schema.Resource {
 				Computed:    true,
 				Description: "If true, then this database can be cloned by any user with CREATEDB privileges",
 			},
+			dbTerminateTemplateConnsAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Terminate the sessions connected to the template when the database is created",
+			},
 		},
 	}
 }
@@ -112,35 +145,43 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
+	if err := checkDBLocale(c, d); err != nil {
+		return err
+	}
+
+	if err := checkTablespaceExists(c.DB(), d.Get(dbTablespaceAttr).(string)); err != nil {
+		return err
+	}
+
 	dbName := d.Get(dbNameAttr).(string)
 	b := bytes.NewBufferString("CREATE DATABASE ")
 	fmt.Fprint(b, pq.QuoteIdentifier(dbName))
 
+	owner := resourceOwner(c, d, dbOwnerAttr)
+
 	// Needed in order to set the owner of the db if the connection user is not a
 	// superuser
-	err := grantRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
+	err := grantRoleMembership(c.DB(), owner, c.config.Username)
 	if err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
+		return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, owner), err)
 	}
 	defer func() {
 		//undo the grant if the connection user is not a superuser
-		err = revokeRoleMembership(c.DB(), d.Get(dbOwnerAttr).(string), c.config.Username)
+		err = revokeRoleMembership(c.DB(), owner, c.config.Username)
 		if err != nil {
-			err = errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, d.Get(dbOwnerAttr).(string)), err)
+			err = errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, owner), err)
 		}
 	}()
 
 	// Handle each option individually and stream results into the query
 	// buffer.
 
-	switch v, ok := d.GetOk(dbOwnerAttr); {
-	case ok:
-		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(v.(string)))
-	default:
-		// No owner specified in the config, default to using
-		// the connecting username.
-		fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(c.config.Username))
+	if owner == "" {
+		// No owner specified in the config nor in the provider, default to
+		// using the connecting username.
+		owner = c.config.Username
 	}
+	fmt.Fprint(b, " OWNER ", pq.QuoteIdentifier(owner))
 
 	switch v, ok := d.GetOk(dbTemplateAttr); {
 	case ok && strings.ToUpper(v.(string)) == "DEFAULT":
@@ -185,6 +226,10 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
 	}
 
+	if err := createDBLocaleProvider(c, d, b); err != nil {
+		return err
+	}
+
 	if c.featureSupported(featureDBAllowConnections) {
 		val := d.Get(dbAllowConnsAttr).(bool)
 		fmt.Fprint(b, " ALLOW_CONNECTIONS ", val)
@@ -200,6 +245,10 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 		fmt.Fprint(b, " IS_TEMPLATE ", val)
 	}
 
+	if err := prepareDBTemplate(c, d); err != nil {
+		return err
+	}
+
 	sql := b.String()
 	if _, err := c.DB().Exec(sql); err != nil {
 		return errwrap.Wrapf(fmt.Sprintf("Error creating database %q: {{err}}", dbName), err)
@@ -213,6 +262,323 @@ func resourcePostgreSQLDatabaseCreate(d *schema.ResourceData, meta interface{})
 	return err
 }
 
+// dbTemplateTerminateTimeout is how long prepareDBTemplate waits for the
+// terminated sessions to exit.
+const dbTemplateTerminateTimeout = 5 * time.Second
+
+// prepareDBTemplate makes sure no session is connected to the template of the
+// database, as PostgreSQL cannot copy a database which is being accessed.  The
+// sessions are terminated if requested, otherwise they are returned in an
+// error.
+func prepareDBTemplate(c *Client, d *schema.ResourceData) error {
+	template := d.Get(dbTemplateAttr).(string)
+	switch {
+	case template == "":
+		template = "template0"
+	case strings.ToUpper(template) == "DEFAULT":
+		template = "template1"
+	}
+
+	if template == c.databaseName {
+		return fmt.Errorf("Error creating database from template %s: the provider is connected to it, set the database of the provider to another one", template)
+	}
+
+	if d.Get(dbTerminateTemplateConnsAttr).(bool) {
+		var terminated int
+		if err := c.DB().QueryRow(
+			"SELECT count(pg_catalog.pg_terminate_backend(pid)) FROM pg_catalog.pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+			template,
+		).Scan(&terminated); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error terminating the connections to template %s: {{err}}", template), err)
+		}
+		log.Printf("[INFO] terminated %d connection(s) to template %s", terminated, template)
+
+		// pg_terminate_backend does not wait for the sessions to exit.
+		deadline := time.Now().Add(dbTemplateTerminateTimeout)
+		for terminated > 0 && time.Now().Before(deadline) {
+			if err := c.DB().QueryRow(
+				"SELECT count(*) FROM pg_catalog.pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+				template,
+			).Scan(&terminated); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("Error counting the connections to template %s: {{err}}", template), err)
+			}
+			if terminated > 0 {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+	}
+
+	if err := checkDBConnections(c.DB(), template); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf(
+			"Error creating database from template %s, set %s to terminate its sessions: {{err}}",
+			template, dbTerminateTemplateConnsAttr,
+		), err)
+	}
+	return nil
+}
+
+// serverEncodings are the encodings a database can use, indexed by their name
+// cleaned by cleanEncodingName.  The client-only encodings (e.g. SJIS) cannot
+// be used by a database.
+var serverEncodings = map[string]string{
+	"sqlascii":     "SQL_ASCII",
+	"eucjp":        "EUC_JP",
+	"euccn":        "EUC_CN",
+	"euckr":        "EUC_KR",
+	"euctw":        "EUC_TW",
+	"eucjis2004":   "EUC_JIS_2004",
+	"utf8":         "UTF8",
+	"muleinternal": "MULE_INTERNAL",
+	"latin1":       "LATIN1",
+	"latin2":       "LATIN2",
+	"latin3":       "LATIN3",
+	"latin4":       "LATIN4",
+	"latin5":       "LATIN5",
+	"latin6":       "LATIN6",
+	"latin7":       "LATIN7",
+	"latin8":       "LATIN8",
+	"latin9":       "LATIN9",
+	"latin10":      "LATIN10",
+	"win1250":      "WIN1250",
+	"win1251":      "WIN1251",
+	"win1252":      "WIN1252",
+	"win1253":      "WIN1253",
+	"win1254":      "WIN1254",
+	"win1255":      "WIN1255",
+	"win1256":      "WIN1256",
+	"win1257":      "WIN1257",
+	"win1258":      "WIN1258",
+	"win866":       "WIN866",
+	"win874":       "WIN874",
+	"koi8r":        "KOI8R",
+	"koi8u":        "KOI8U",
+	"iso88595":     "ISO_8859_5",
+	"iso88596":     "ISO_8859_6",
+	"iso88597":     "ISO_8859_7",
+	"iso88598":     "ISO_8859_8",
+
+	// Aliases accepted by PostgreSQL, see pg_encname_tbl in encnames.c
+	"unicode":     "UTF8",
+	"iso88591":    "LATIN1",
+	"iso88592":    "LATIN2",
+	"iso88593":    "LATIN3",
+	"iso88594":    "LATIN4",
+	"iso88599":    "LATIN5",
+	"iso885910":   "LATIN6",
+	"iso885913":   "LATIN7",
+	"iso885914":   "LATIN8",
+	"iso885915":   "LATIN9",
+	"iso885916":   "LATIN10",
+	"koi8":        "KOI8R",
+	"alt":         "WIN866",
+	"win":         "WIN1251",
+	"windows1250": "WIN1250",
+	"windows1251": "WIN1251",
+	"windows1252": "WIN1252",
+	"windows1253": "WIN1253",
+	"windows1254": "WIN1254",
+	"windows1255": "WIN1255",
+	"windows1256": "WIN1256",
+	"windows1257": "WIN1257",
+	"windows1258": "WIN1258",
+	"windows866":  "WIN866",
+	"windows874":  "WIN874",
+	"tcvn":        "WIN1258",
+	"tcvn5712":    "WIN1258",
+	"vscii":       "WIN1258",
+	"abc":         "WIN1258",
+}
+
+// clientEncodings are the encodings PostgreSQL only supports on the client
+// side, indexed by their cleaned name.
+var clientEncodings = map[string]string{
+	"sjis":         "SJIS",
+	"mskanji":      "SJIS",
+	"shiftjis":     "SJIS",
+	"big5":         "BIG5",
+	"gbk":          "GBK",
+	"uhc":          "UHC",
+	"gb18030":      "GB18030",
+	"johab":        "JOHAB",
+	"shiftjis2004": "SHIFT_JIS_2004",
+}
+
+// cleanEncodingName lowers the case of an encoding name and removes its
+// non alphanumeric characters, as PostgreSQL does to look it up (e.g. UTF-8
+// is the same encoding as utf8).
+func cleanEncodingName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, name)
+}
+
+func validateDBEncoding(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(string)
+	if strings.ToUpper(value) == "DEFAULT" {
+		return
+	}
+
+	name := cleanEncodingName(value)
+	if encoding, ok := clientEncodings[name]; ok {
+		errors = append(errors, fmt.Errorf("%s %s is only supported by PostgreSQL as a client encoding, it cannot be used by a database", key, encoding))
+		return
+	}
+	if _, ok := serverEncodings[name]; !ok {
+		errors = append(errors, fmt.Errorf("%s must be DEFAULT or one of the PostgreSQL server encodings (e.g. UTF8, LATIN1, SQL_ASCII), got: %q", key, value))
+	}
+	return
+}
+
+// suppressEquivalentEncodings suppresses the diff between two names of the
+// same encoding, as the encoding is read back with its canonical name.
+func suppressEquivalentEncodings(k, old, new string, d *schema.ResourceData) bool {
+	oldEncoding, ok := serverEncodings[cleanEncodingName(old)]
+	if !ok {
+		return false
+	}
+	return oldEncoding == serverEncodings[cleanEncodingName(new)]
+}
+
+// localeEncoding returns the encoding of the codeset of a locale (e.g. UTF8
+// for en_US.UTF-8), or an empty string if the locale has no codeset, or an
+// unknown one.
+func localeEncoding(locale string) string {
+	idx := strings.IndexByte(locale, '.')
+	if idx == -1 {
+		return ""
+	}
+	codeset := locale[idx+1:]
+	if idx := strings.IndexByte(codeset, '@'); idx != -1 {
+		codeset = codeset[:idx]
+	}
+
+	name := cleanEncodingName(codeset)
+	// The codesets of the Windows locales are code page numbers (e.g.
+	// English_United States.1252).
+	if _, err := strconv.Atoi(name); err == nil {
+		name = "win" + name
+	}
+	return serverEncodings[name]
+}
+
+// isDefaultLocale returns true if the locale is compatible with every
+// encoding.
+func isDefaultLocale(locale string) bool {
+	switch strings.ToUpper(locale) {
+	case "", "C", "POSIX", "DEFAULT":
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(locale), "C.")
+}
+
+// localeProviders maps pg_database.datlocprovider to the name of the locale
+// provider in CREATE DATABASE.  The builtin provider of PostgreSQL 17 is not
+// supported, it is read as an empty locale_provider.
+var localeProviders = map[string]string{
+	"c": "libc",
+	"i": "icu",
+}
+
+// createDBLocaleProvider adds the LOCALE_PROVIDER and ICU_LOCALE clauses of
+// the CREATE DATABASE statement when they are set.
+func createDBLocaleProvider(c *Client, d *schema.ResourceData, b *bytes.Buffer) error {
+	locProv := d.Get(dbLocProvAttr).(string)
+	icuLocale := d.Get(dbICULocaleAttr).(string)
+	if locProv == "" && icuLocale == "" {
+		return nil
+	}
+
+	if !c.featureSupported(featureDBLocaleProvider) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support CREATE DATABASE ... LOCALE_PROVIDER (PostgreSQL 15+)", c.version.String())
+	}
+
+	switch {
+	case icuLocale != "" && locProv != "icu":
+		return fmt.Errorf("%s can only be set with the icu %s", dbICULocaleAttr, dbLocProvAttr)
+	case locProv == "icu" && icuLocale == "":
+		return fmt.Errorf("%s has to be set with the icu %s", dbICULocaleAttr, dbLocProvAttr)
+	}
+
+	fmt.Fprint(b, " LOCALE_PROVIDER ", locProv)
+	if icuLocale != "" {
+		fmt.Fprintf(b, " ICU_LOCALE '%s'", pqQuoteLiteral(icuLocale))
+	}
+
+	return nil
+}
+
+// checkDBLocale checks that the encoding and the locales of the database are
+// compatible, and that they match the ones of its template if it is not
+// template0.  These errors are otherwise only reported by CREATE DATABASE.
+func checkDBLocale(c *Client, d *schema.ResourceData) error {
+	// See the defaults of resourcePostgreSQLDatabaseCreate.
+	encoding := "UTF8"
+	if v, ok := d.GetOk(dbEncodingAttr); ok {
+		encoding = serverEncodings[cleanEncodingName(v.(string))]
+	}
+
+	locales := make(map[string]string)
+	for _, attr := range []string{dbCollationAttr, dbCTypeAttr} {
+		if v, ok := d.GetOk(attr); ok {
+			locales[attr] = v.(string)
+		}
+	}
+
+	// SQL_ASCII can be used with any locale, and the encoding of DEFAULT is
+	// the one of the template.
+	if encoding != "" && encoding != "SQL_ASCII" {
+		for _, attr := range []string{dbCollationAttr, dbCTypeAttr} {
+			locale := locales[attr]
+			if isDefaultLocale(locale) {
+				continue
+			}
+			if localeEncoding := localeEncoding(locale); localeEncoding != "" && localeEncoding != encoding {
+				return fmt.Errorf("%s %q of database %s requires the %s encoding, not %s", attr, locale, d.Get(dbNameAttr).(string), localeEncoding, encoding)
+			}
+		}
+	}
+
+	// Only template0 can be copied with another encoding or other locales.
+	template, ok := d.GetOk(dbTemplateAttr)
+	if !ok || template.(string) == "template0" || strings.ToUpper(template.(string)) == "DEFAULT" {
+		return nil
+	}
+
+	var templateEncoding, templateCollation, templateCType string
+	err := c.DB().QueryRow(
+		"SELECT pg_catalog.pg_encoding_to_char(encoding), datcollate, datctype FROM pg_catalog.pg_database WHERE datname = $1",
+		template.(string),
+	).Scan(&templateEncoding, &templateCollation, &templateCType)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("template database %s does not exist", template.(string))
+	case err != nil:
+		return errwrap.Wrapf("Error reading template database: {{err}}", err)
+	}
+
+	if encoding != "" && encoding != templateEncoding {
+		return fmt.Errorf("encoding %s of database %s differs from the encoding %s of template %s, only template0 can be used with another encoding", encoding, d.Get(dbNameAttr).(string), templateEncoding, template.(string))
+	}
+	for attr, templateLocale := range map[string]string{dbCollationAttr: templateCollation, dbCTypeAttr: templateCType} {
+		// Without a locale, the database uses C, see resourcePostgreSQLDatabaseCreate.
+		locale, ok := locales[attr]
+		if !ok {
+			locale = "C"
+		}
+		if strings.ToUpper(locale) != "DEFAULT" && locale != templateLocale {
+			return fmt.Errorf("%s %q of database %s differs from the one of template %s (%q), only template0 can be used with other locales", attr, locale, d.Get(dbNameAttr).(string), template.(string), templateLocale)
+		}
+	}
+
+	return nil
+}
+
 func resourcePostgreSQLDatabaseDelete(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
 	c.catalogLock.Lock()
@@ -281,8 +647,27 @@ func resourcePostgreSQLDatabaseRead(d *schema.ResourceData, meta interface{}) er
 }
 
 func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}) error {
-	c := meta.(*Client)
+	if err := readDatabase(meta.(*Client), d); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
 
+	dbTemplate := d.Get(dbTemplateAttr).(string)
+	if dbTemplate == "" {
+		dbTemplate = "template0"
+	}
+	d.Set(dbTemplateAttr, dbTemplate)
+	d.Set(dbTerminateTemplateConnsAttr, d.Get(dbTerminateTemplateConnsAttr).(bool))
+
+	return nil
+}
+
+// readDatabase reads the properties of the database whose name is the ID,
+// shared by the postgresql_database resource and data source.
+func readDatabase(c *Client, d *schema.ResourceData) error {
 	dbId := d.Id()
 	var dbName, ownerName string
 	err := c.DB().QueryRow("SELECT d.datname, pg_catalog.pg_get_userbyid(d.datdba) from pg_database d WHERE datname=$1", dbId).Scan(&dbName, &ownerName)
@@ -334,11 +719,6 @@ func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}
 	d.Set(dbCTypeAttr, dbCType)
 	d.Set(dbTablespaceAttr, dbTablespaceName)
 	d.Set(dbConnLimitAttr, dbConnLimit)
-	dbTemplate := d.Get(dbTemplateAttr).(string)
-	if dbTemplate == "" {
-		dbTemplate = "template0"
-	}
-	d.Set(dbTemplateAttr, dbTemplate)
 
 	if c.featureSupported(featureDBAllowConnections) {
 		var dbAllowConns bool
@@ -351,6 +731,28 @@ func resourcePostgreSQLDatabaseReadImpl(d *schema.ResourceData, meta interface{}
 		d.Set(dbAllowConnsAttr, dbAllowConns)
 	}
 
+	if c.featureSupported(featureDBLocaleProvider) {
+		// The ICU locale column is renamed in PostgreSQL 17, where it also
+		// holds the locale of the builtin provider, which is not kept.
+		localeColumn := "d.daticulocale"
+		if c.featureSupported(featureDBDatLocale) {
+			localeColumn = "d.datlocale"
+		}
+
+		var dbLocProv, dbICULocale string
+		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datlocprovider::text, COALESCE("+localeColumn+", '')")
+		err = c.DB().QueryRow(dbSQL, dbId).Scan(&dbLocProv, &dbICULocale)
+		if err != nil {
+			return errwrap.Wrapf("Error reading LOCALE_PROVIDER property for DATABASE: {{err}}", err)
+		}
+
+		if dbLocProv != "i" {
+			dbICULocale = ""
+		}
+		d.Set(dbLocProvAttr, localeProviders[dbLocProv])
+		d.Set(dbICULocaleAttr, dbICULocale)
+	}
+
 	if c.featureSupported(featureDBIsTemplate) {
 		var dbIsTemplate bool
 		dbSQL := fmt.Sprintf(dbSQLFmt, "d.datistemplate")
@@ -370,7 +772,7 @@ func resourcePostgreSQLDatabaseUpdate(d *schema.ResourceData, meta interface{})
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	if err := setDBName(c.DB(), d); err != nil {
+	if err := setDBName(c, d); err != nil {
 		return err
 	}
 
@@ -399,7 +801,9 @@ func resourcePostgreSQLDatabaseUpdate(d *schema.ResourceData, meta interface{})
 	return resourcePostgreSQLDatabaseReadImpl(d, meta)
 }
 
-func setDBName(db *sql.DB, d *schema.ResourceData) error {
+// setDBName renames the database.  PostgreSQL refuses to rename a database
+// with active connections, so they are checked before to return a clear error.
+func setDBName(c *Client, d *schema.ResourceData) error {
 	if !d.HasChange(dbNameAttr) {
 		return nil
 	}
@@ -411,8 +815,18 @@ func setDBName(db *sql.DB, d *schema.ResourceData) error {
 		return errors.New("Error setting database name to an empty string")
 	}
 
+	// The connections of the provider are opened on its database, which
+	// therefore cannot be renamed.
+	if o == c.databaseName {
+		return fmt.Errorf("Error renaming database %s: the provider is connected to it, set the database of the provider to another one to rename it", o)
+	}
+
+	if err := checkDBConnections(c.DB(), o); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error renaming database %s: {{err}}", o), err)
+	}
+
 	sql := fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
-	if _, err := db.Exec(sql); err != nil {
+	if _, err := c.DB().Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating database name: {{err}}", err)
 	}
 	d.SetId(n)
@@ -420,6 +834,37 @@ func setDBName(db *sql.DB, d *schema.ResourceData) error {
 	return nil
 }
 
+// checkDBConnections returns an error listing the sessions connected to the
+// database, if any.  The provider does not keep idle connections to the
+// databases it manages.
+func checkDBConnections(db *sql.DB, dbName string) error {
+	rows, err := db.Query(
+		"SELECT usename, application_name FROM pg_catalog.pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid()",
+		dbName,
+	)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not list the connections to database %s: {{err}}", dbName), err)
+	}
+	defer rows.Close()
+
+	sessions := []string{}
+	for rows.Next() {
+		var userName, applicationName sql.NullString
+		if err := rows.Scan(&userName, &applicationName); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not scan the connections to database %s: {{err}}", dbName), err)
+		}
+		sessions = append(sessions, fmt.Sprintf("%s (%s)", userName.String, applicationName.String))
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not list the connections to database %s: {{err}}", dbName), err)
+	}
+
+	if len(sessions) > 0 {
+		return fmt.Errorf("database %s has %d active connection(s), they must be closed first: %s", dbName, len(sessions), strings.Join(sessions, ", "))
+	}
+	return nil
+}
+
 func setDBOwner(c *Client, d *schema.ResourceData) error {
 	if !d.HasChange(dbOwnerAttr) {
 		return nil
@@ -458,6 +903,10 @@ func setDBTablespace(db *sql.DB, d *schema.ResourceData) error {
 	}
 
 	tbspName := d.Get(dbTablespaceAttr).(string)
+	if err := checkTablespaceExists(db, tbspName); err != nil {
+		return err
+	}
+
 	dbName := d.Get(dbNameAttr).(string)
 	var sql string
 	if tbspName == "" || strings.ToUpper(tbspName) == "DEFAULT" {
@@ -480,8 +929,9 @@ func setDBConnLimit(db *sql.DB, d *schema.ResourceData) error {
 
 	connLimit := d.Get(dbConnLimitAttr).(int)
 	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s CONNECTION LIMIT = $1", pq.QuoteIdentifier(dbName))
-	if _, err := db.Exec(sql, connLimit); err != nil {
+	// ALTER DATABASE does not accept parameters.
+	sql := fmt.Sprintf("ALTER DATABASE %s CONNECTION LIMIT %d", pq.QuoteIdentifier(dbName), connLimit)
+	if _, err := db.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating database CONNECTION LIMIT: {{err}}", err)
 	}
 
@@ -499,8 +949,8 @@ func setDBAllowConns(c *Client, d *schema.ResourceData) error {
 
 	allowConns := d.Get(dbAllowConnsAttr).(bool)
 	dbName := d.Get(dbNameAttr).(string)
-	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS $1", pq.QuoteIdentifier(dbName))
-	if _, err := c.DB().Exec(sql, allowConns); err != nil {
+	sql := fmt.Sprintf("ALTER DATABASE %s ALLOW_CONNECTIONS %t", pq.QuoteIdentifier(dbName), allowConns)
+	if _, err := c.DB().Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating database ALLOW_CONNECTIONS: {{err}}", err)
 	}
 
@@ -524,8 +974,8 @@ func doSetDBIsTemplate(c *Client, dbName string, isTemplate bool) error {
 		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support database IS_TEMPLATE", c.version.String())
 	}
 
-	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE $1", pq.QuoteIdentifier(dbName))
-	if _, err := c.DB().Exec(sql, isTemplate); err != nil {
+	sql := fmt.Sprintf("ALTER DATABASE %s IS_TEMPLATE %t", pq.QuoteIdentifier(dbName), isTemplate)
+	if _, err := c.DB().Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating database IS_TEMPLATE: {{err}}", err)
 	}
 
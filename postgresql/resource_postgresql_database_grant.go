@@ -0,0 +1,289 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/lib/pq"
+	"github.com/sean-/postgresql-acl"
+)
+
+const (
+	dbGrantDatabaseAttr        = "database"
+	dbGrantRoleAttr            = "role"
+	dbGrantPrivilegesAttr      = "privileges"
+	dbGrantWithGrantOptionAttr = "with_grant_option"
+
+	// publicRole is the name of the pseudo-role which represents all the roles.
+	publicRole = "PUBLIC"
+)
+
+// databasePrivileges maps the privileges which can be granted on a database
+// to their aclitem counterpart.
+var databasePrivileges = map[string]acl.Privileges{
+	"CONNECT":   acl.Connect,
+	"CREATE":    acl.Create,
+	"TEMPORARY": acl.Temporary,
+}
+
+func resourcePostgreSQLDatabaseGrant() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLDatabaseGrantCreate,
+		// As create revokes and grants we can use it to update too
+		Update: resourcePostgreSQLDatabaseGrantCreate,
+		Read:   resourcePostgreSQLDatabaseGrantRead,
+		Delete: resourcePostgreSQLDatabaseGrantDelete,
+
+		Schema: map[string]*schema.Schema{
+			dbGrantDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database to grant privileges on",
+			},
+			dbGrantRoleAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the role to grant privileges to (or PUBLIC)",
+			},
+			dbGrantPrivilegesAttr: {
+				Type:     schema.TypeSet,
+				Required: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+					ValidateFunc: validation.StringInSlice([]string{
+						"CONNECT",
+						"CREATE",
+						"TEMPORARY",
+					}, false),
+				},
+				Set:         schema.HashString,
+				MinItems:    1,
+				Description: "The list of privileges to grant (one of: CONNECT, CREATE, TEMPORARY)",
+			},
+			dbGrantWithGrantOptionAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the role to grant the same privileges to other roles",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLDatabaseGrantRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readDatabaseGrant(c, d)
+}
+
+func resourcePostgreSQLDatabaseGrantCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	database := d.Get(dbGrantDatabaseAttr).(string)
+
+	err := withDatabaseOwnerMembership(c, database, func(txn *sql.Tx) error {
+		// Revoke all privileges before granting otherwise reducing privileges will not work.
+		// We just have to revoke them in the same transaction so the role will not lost its
+		// privileges between the revoke and grant statements.
+		if err := revokeDatabasePrivileges(txn, d); err != nil {
+			return err
+		}
+
+		return grantDatabasePrivileges(txn, d)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(generateDatabaseGrantID(d))
+
+	return readDatabaseGrant(c, d)
+}
+
+func resourcePostgreSQLDatabaseGrantDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	database := d.Get(dbGrantDatabaseAttr).(string)
+
+	err := withDatabaseOwnerMembership(c, database, func(txn *sql.Tx) error {
+		return revokeDatabasePrivileges(txn, d)
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// withDatabaseOwnerMembership runs fn in a transaction in which the connection
+// user is a member of the owner of database: only the owner of the database
+// (or a superuser) can grant privileges on it.  The transaction runs as the
+// connection user, which is the role granted the membership, and not as the
+// role assumed by the provider.  The membership, when granted, is revoked
+// before the commit so it never outlives the transaction, even on a failure.
+func withDatabaseOwnerMembership(c *Client, database string, fn func(txn *sql.Tx) error) error {
+	txn, err := startConnectionUserTransactionContext(context.Background(), c, "")
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	var owner string
+	var isMember bool
+	err = txn.QueryRow(
+		"SELECT pg_catalog.pg_get_userbyid(datdba), pg_catalog.pg_has_role(datdba, 'MEMBER') FROM pg_catalog.pg_database WHERE datname = $1",
+		database,
+	).Scan(&owner, &isMember)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("database %q does not exist", database)
+	case err != nil:
+		return errwrap.Wrapf("could not read database owner: {{err}}", err)
+	}
+
+	if !isMember {
+		sql := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(owner), pq.QuoteIdentifier(c.config.Username))
+		if _, err := txn.Exec(sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error adding connection user (%q) to ROLE %q: {{err}}", c.config.Username, owner), err)
+		}
+	}
+
+	if err := fn(txn); err != nil {
+		return err
+	}
+
+	if !isMember {
+		sql := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(owner), pq.QuoteIdentifier(c.config.Username))
+		if _, err := txn.Exec(sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error removing connection user (%q) from ROLE %q: {{err}}", c.config.Username, owner), err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	return nil
+}
+
+func readDatabaseGrant(c *Client, d *schema.ResourceData) error {
+	database := d.Get(dbGrantDatabaseAttr).(string)
+	role := d.Get(dbGrantRoleAttr).(string)
+
+	var datACLs []string
+	// A NULL datacl means the default privileges: CONNECT and TEMPORARY for
+	// PUBLIC and all the privileges for the owner.
+	query := "SELECT COALESCE(datacl, pg_catalog.acldefault('d', datdba))::TEXT[] FROM pg_catalog.pg_database WHERE datname = $1"
+	err := c.DB().QueryRow(query, database).Scan(pq.Array(&datACLs))
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL database (%q) not found", database)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading database ACL: {{err}}", err)
+	}
+
+	// In an aclitem, the PUBLIC pseudo-role is represented by an empty role name.
+	aclRole := role
+	if isPublicRole(role) {
+		aclRole = ""
+	}
+
+	var dbACL acl.Database
+	for _, aclStr := range datACLs {
+		aclItem, err := acl.Parse(aclStr)
+		if err != nil {
+			return errwrap.Wrapf("Error parsing aclitem: {{err}}", err)
+		}
+
+		if aclItem.Role != aclRole {
+			continue
+		}
+
+		itemACL, err := acl.NewDatabase(aclItem)
+		if err != nil {
+			return errwrap.Wrapf("invalid perms for database: {{err}}", err)
+		}
+		dbACL.Privileges |= itemACL.Privileges
+		dbACL.GrantOptions |= itemACL.GrantOptions
+	}
+
+	if dbACL.Privileges == acl.NoPrivs {
+		log.Printf("[WARN] no privileges on database %q for role %q", database, role)
+		d.SetId("")
+		return nil
+	}
+
+	privileges := []interface{}{}
+	for priv, aclPriv := range databasePrivileges {
+		if dbACL.GetPrivilege(aclPriv) {
+			privileges = append(privileges, priv)
+		}
+	}
+
+	d.Set(dbGrantPrivilegesAttr, schema.NewSet(schema.HashString, privileges))
+	d.Set(dbGrantWithGrantOptionAttr, dbACL.GrantOptions != acl.NoPrivs && dbACL.GrantOptions == dbACL.Privileges)
+	d.SetId(generateDatabaseGrantID(d))
+
+	return nil
+}
+
+func grantDatabasePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	privileges := []string{}
+	for _, priv := range d.Get(dbGrantPrivilegesAttr).(*schema.Set).List() {
+		privileges = append(privileges, priv.(string))
+	}
+
+	query := fmt.Sprintf(
+		"GRANT %s ON DATABASE %s TO %s",
+		strings.Join(privileges, ","),
+		pq.QuoteIdentifier(d.Get(dbGrantDatabaseAttr).(string)),
+		quoteRoleName(d.Get(dbGrantRoleAttr).(string)),
+	)
+	if d.Get(dbGrantWithGrantOptionAttr).(bool) {
+		query += " WITH GRANT OPTION"
+	}
+
+	if _, err := txn.Exec(query); err != nil {
+		return errwrap.Wrapf("could not grant database privileges: {{err}}", err)
+	}
+
+	return nil
+}
+
+func revokeDatabasePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+	query := fmt.Sprintf(
+		"REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s",
+		pq.QuoteIdentifier(d.Get(dbGrantDatabaseAttr).(string)),
+		quoteRoleName(d.Get(dbGrantRoleAttr).(string)),
+	)
+
+	if _, err := txn.Exec(query); err != nil {
+		return errwrap.Wrapf("could not revoke database privileges: {{err}}", err)
+	}
+
+	return nil
+}
+
+func generateDatabaseGrantID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(dbGrantRoleAttr).(string), d.Get(dbGrantDatabaseAttr).(string),
+	}, "_")
+}
@@ -0,0 +1,119 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlDatabaseGrant(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+	var testDatabaseGrantConnect = fmt.Sprintf(`
+	resource "postgresql_database_grant" "test" {
+		database   = "%s"
+		role       = "%s"
+		privileges = ["CONNECT"]
+	}
+	`, dbName, roleName)
+
+	var testDatabaseGrantConnectTempWithGrant = fmt.Sprintf(`
+	resource "postgresql_database_grant" "test" {
+		database          = "%s"
+		role              = "%s"
+		privileges        = ["CONNECT", "TEMPORARY"]
+		with_grant_option = true
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDatabaseGrantConnect,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasePrivilege(dbName, roleName, "CONNECT", true),
+					testAccCheckDatabasePrivilege(dbName, roleName, "CREATE", false),
+					resource.TestCheckResourceAttr("postgresql_database_grant.test", "privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_database_grant.test", "with_grant_option", "false"),
+				),
+			},
+			{
+				Config: testDatabaseGrantConnectTempWithGrant,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckDatabasePrivilege(dbName, roleName, "CONNECT WITH GRANT OPTION", true),
+					testAccCheckDatabasePrivilege(dbName, roleName, "TEMPORARY WITH GRANT OPTION", true),
+					resource.TestCheckResourceAttr("postgresql_database_grant.test", "privileges.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_database_grant.test", "with_grant_option", "true"),
+				),
+			},
+		},
+	})
+}
+
+// A NULL datacl holds the default privileges of the database, e.g. CONNECT and
+// TEMPORARY for PUBLIC.
+func TestAccPostgresqlDatabaseGrant_DefaultACL(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not connect to the PostgreSQL server: %v", err)
+	}
+
+	// The ACL of a new database is NULL until a privilege is granted or
+	// revoked on it, which means CONNECT and TEMPORARY for PUBLIC.
+	var isNull bool
+	if err := client.DB().QueryRow("SELECT datacl IS NULL FROM pg_catalog.pg_database WHERE datname = $1", dbName).Scan(&isNull); err != nil {
+		t.Fatalf("could not read the ACL of database %s: %v", dbName, err)
+	}
+	if !isNull {
+		t.Fatalf("expected a NULL ACL for the new database %s", dbName)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDatabaseGrant().Schema, map[string]interface{}{
+		dbGrantDatabaseAttr:   dbName,
+		dbGrantRoleAttr:       "public",
+		dbGrantPrivilegesAttr: []interface{}{"CONNECT", "TEMPORARY"},
+	})
+	if err := readDatabaseGrant(client, d); err != nil {
+		t.Fatalf("could not read the database grant: %v", err)
+	}
+	if d.Id() == "" {
+		t.Fatalf("expected the default privileges of PUBLIC to be read from the NULL ACL")
+	}
+
+	privileges := d.Get(dbGrantPrivilegesAttr).(*schema.Set)
+	if privileges.Len() != 2 || !privileges.Contains("CONNECT") || !privileges.Contains("TEMPORARY") {
+		t.Errorf("expected the privileges CONNECT and TEMPORARY, got %v", privileges.List())
+	}
+}
+
+func testAccCheckDatabasePrivilege(dbName, roleName, privilege string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var hasPrivilege bool
+		err := client.DB().QueryRow(
+			"SELECT has_database_privilege($1, $2, $3)", roleName, dbName, privilege,
+		).Scan(&hasPrivilege)
+		if err != nil {
+			return fmt.Errorf("could not check %s privilege on database %s: %v", privilege, dbName, err)
+		}
+
+		if hasPrivilege != expected {
+			return fmt.Errorf("expected %s privilege on database %s for role %s to be %t", privilege, dbName, roleName, expected)
+		}
+		return nil
+	}
+}
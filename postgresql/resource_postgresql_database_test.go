@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -133,6 +134,268 @@ func TestAccPostgresqlDatabase_DefaultOwner(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlDatabase_ConnectionLimit(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseConnLimitConfig, 0),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.conn_limit"),
+					resource.TestCheckResourceAttr("postgresql_database.conn_limit", "connection_limit", "0"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseConnLimitConfig, -1),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.conn_limit", "connection_limit", "-1"),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseConnLimitConfig, 5),
+				Check:  resource.TestCheckResourceAttr("postgresql_database.conn_limit", "connection_limit", "5"),
+			},
+		},
+	})
+}
+
+// allow_connections and connection_limit are changed without recreating the
+// database.
+func TestAccPostgresqlDatabase_AllowConnections(t *testing.T) {
+	testCheckCompatibleVersion(t, featureDBAllowConnections)
+
+	var oid int
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseAllowConnsConfig, true, -1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.allow_conns"),
+					testAccCheckPostgresqlDatabaseOID("tf_tests_db_allow_conns", &oid),
+					resource.TestCheckResourceAttr("postgresql_database.allow_conns", "allow_connections", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseAllowConnsConfig, false, 3),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseOID("tf_tests_db_allow_conns", &oid),
+					testAccCheckPostgresqlDatabaseConnections("tf_tests_db_allow_conns", false, 3),
+					resource.TestCheckResourceAttr("postgresql_database.allow_conns", "allow_connections", "false"),
+					resource.TestCheckResourceAttr("postgresql_database.allow_conns", "connection_limit", "3"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseAllowConnsConfig, true, -1),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseOID("tf_tests_db_allow_conns", &oid),
+					testAccCheckPostgresqlDatabaseConnections("tf_tests_db_allow_conns", true, -1),
+					resource.TestCheckResourceAttr("postgresql_database.allow_conns", "allow_connections", "true"),
+					resource.TestCheckResourceAttr("postgresql_database.allow_conns", "connection_limit", "-1"),
+				),
+			},
+		},
+	})
+}
+
+// Changing the name renames the database instead of recreating it.
+func TestAccPostgresqlDatabase_Rename(t *testing.T) {
+	var oid int
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseRenameConfig, "tf_tests_db_rename"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.rename"),
+					testAccCheckPostgresqlDatabaseOID("tf_tests_db_rename", &oid),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseRenameConfig, "tf_tests_db_renamed"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.rename"),
+					testAccCheckPostgresqlDatabaseOID("tf_tests_db_renamed", &oid),
+					resource.TestCheckResourceAttr("postgresql_database.rename", "id", "tf_tests_db_renamed"),
+					resource.TestCheckResourceAttr("postgresql_database.rename", "name", "tf_tests_db_renamed"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Template(t *testing.T) {
+	config := getTestConfig(t)
+
+	// A session connected to the custom template prevents copying it.
+	var templateDB *sql.DB
+	defer func() {
+		if templateDB != nil {
+			templateDB.Close()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgreSQLDatabaseTemplateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.template"),
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.from_template0"),
+					resource.TestCheckResourceAttr("postgresql_database.from_template0", "template", "template0"),
+				),
+			},
+			{
+				PreConfig: func() {
+					var err error
+					if templateDB, err = sql.Open("postgres", config.connStr("tf_tests_db_template")); err != nil {
+						t.Fatalf("could not open connection pool for db tf_tests_db_template: %v", err)
+					}
+					if err := templateDB.Ping(); err != nil {
+						t.Fatalf("could not connect to db tf_tests_db_template: %v", err)
+					}
+				},
+				Config:      fmt.Sprintf(testAccPostgreSQLDatabaseFromTemplateConfig, false),
+				ExpectError: regexp.MustCompile("database tf_tests_db_template has 1 active connection"),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseFromTemplateConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.from_template"),
+					resource.TestCheckResourceAttr("postgresql_database.from_template", "template", "tf_tests_db_template"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_Tablespace(t *testing.T) {
+	tablespace, teardown := setupTestTablespace(t)
+	defer teardown()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgreSQLDatabaseTablespaceConfig, tablespace),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.tablespace"),
+					resource.TestCheckResourceAttr("postgresql_database.tablespace", "tablespace_name", tablespace),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(testAccPostgreSQLDatabaseTablespaceConfig, "tf_tests_missing_ts"),
+				ExpectError: regexp.MustCompile(`tablespace "tf_tests_missing_ts" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDatabase_ICULocale(t *testing.T) {
+	testCheckCompatibleVersion(t, featureDBLocaleProvider)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlDatabaseDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgreSQLDatabaseICUConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.icu"),
+					resource.TestCheckResourceAttr("postgresql_database.icu", "locale_provider", "icu"),
+					resource.TestCheckResourceAttr("postgresql_database.icu", "icu_locale", "en-US"),
+					testAccCheckPostgresqlDatabaseExists("postgresql_database.libc"),
+					resource.TestCheckResourceAttr("postgresql_database.libc", "locale_provider", "libc"),
+					resource.TestCheckResourceAttr("postgresql_database.libc", "icu_locale", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestValidateDBEncoding(t *testing.T) {
+	cases := []struct {
+		encoding string
+		valid    bool
+	}{
+		{"UTF8", true},
+		{"utf-8", true},
+		{"Unicode", true},
+		{"LATIN1", true},
+		{"ISO-8859-1", true},
+		{"SQL_ASCII", true},
+		{"WIN1252", true},
+		{"DEFAULT", true},
+		{"default", true},
+		{"", false},
+		{"UTF16", false},
+		{"LATIN11", false},
+		{"not an encoding", false},
+		// Client only encodings
+		{"SJIS", false},
+		{"BIG5", false},
+		{"GB18030", false},
+	}
+
+	for _, c := range cases {
+		_, errs := validateDBEncoding(c.encoding, "encoding")
+		if valid := len(errs) == 0; valid != c.valid {
+			t.Errorf("validateDBEncoding(%q) valid = %t, expected %t: %v", c.encoding, valid, c.valid, errs)
+		}
+	}
+}
+
+func TestLocaleEncoding(t *testing.T) {
+	cases := []struct {
+		locale   string
+		encoding string
+	}{
+		{"en_US.UTF-8", "UTF8"},
+		{"en_US.utf8", "UTF8"},
+		{"de_DE.ISO-8859-1", "LATIN1"},
+		{"de_DE.iso885915@euro", "LATIN9"},
+		{"English_United States.1252", "WIN1252"},
+		{"en_US", ""},
+		{"C", ""},
+		{"en_US.unknown", ""},
+	}
+
+	for _, c := range cases {
+		if encoding := localeEncoding(c.locale); encoding != c.encoding {
+			t.Errorf("localeEncoding(%q) = %q, expected %q", c.locale, encoding, c.encoding)
+		}
+	}
+}
+
+func TestSuppressEquivalentEncodings(t *testing.T) {
+	cases := []struct {
+		old, new string
+		suppress bool
+	}{
+		{"UTF8", "utf-8", true},
+		{"UTF8", "UNICODE", true},
+		{"LATIN1", "ISO_8859_1", true},
+		{"UTF8", "LATIN1", false},
+		{"", "UTF8", false},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentEncodings("encoding", c.old, c.new, nil); suppress != c.suppress {
+			t.Errorf("suppressEquivalentEncodings(%q, %q) = %t, expected %t", c.old, c.new, suppress, c.suppress)
+		}
+	}
+}
+
 func testAccCheckPostgresqlDatabaseDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -155,6 +418,51 @@ func testAccCheckPostgresqlDatabaseDestroy(s *terraform.State) error {
 	return nil
 }
 
+// testAccCheckPostgresqlDatabaseOID records the OID of the database on the
+// first call and checks it has not changed, i.e. the database has not been
+// recreated, on the next ones.
+func testAccCheckPostgresqlDatabaseOID(dbName string, oid *int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var currentOID int
+		if err := client.DB().QueryRow(
+			"SELECT oid FROM pg_catalog.pg_database WHERE datname = $1", dbName,
+		).Scan(&currentOID); err != nil {
+			return fmt.Errorf("could not read the OID of database %s: %v", dbName, err)
+		}
+
+		if *oid == 0 {
+			*oid = currentOID
+		} else if currentOID != *oid {
+			return fmt.Errorf("database %s has been recreated: OID %d, expected %d", dbName, currentOID, *oid)
+		}
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlDatabaseConnections(dbName string, allowConns bool, connLimit int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var datAllowConn bool
+		var datConnLimit int
+		if err := client.DB().QueryRow(
+			"SELECT datallowconn, datconnlimit FROM pg_catalog.pg_database WHERE datname = $1", dbName,
+		).Scan(&datAllowConn, &datConnLimit); err != nil {
+			return fmt.Errorf("could not read database %s: %v", dbName, err)
+		}
+
+		if datAllowConn != allowConns || datConnLimit != connLimit {
+			return fmt.Errorf(
+				"expected database %s datallowconn=%t and datconnlimit=%d, got %t and %d",
+				dbName, allowConns, connLimit, datAllowConn, datConnLimit,
+			)
+		}
+		return nil
+	}
+}
+
 func testAccCheckPostgresqlDatabaseExists(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -277,3 +585,62 @@ resource "postgresql_database" "mydb_default_owner" {
 }
 
 `
+
+var testAccPostgreSQLDatabaseICUConfig = `
+resource "postgresql_database" "icu" {
+  name            = "tf_tests_db_icu"
+  locale_provider = "icu"
+  icu_locale      = "en-US"
+}
+
+resource "postgresql_database" "libc" {
+  name = "tf_tests_db_libc"
+}
+`
+
+var testAccPostgreSQLDatabaseConnLimitConfig = `
+resource "postgresql_database" "conn_limit" {
+  name             = "tf_tests_db_conn_limit"
+  connection_limit = %d
+}
+`
+
+var testAccPostgreSQLDatabaseAllowConnsConfig = `
+resource "postgresql_database" "allow_conns" {
+  name              = "tf_tests_db_allow_conns"
+  allow_connections = %t
+  connection_limit  = %d
+}
+`
+
+var testAccPostgreSQLDatabaseRenameConfig = `
+resource "postgresql_database" "rename" {
+  name = "%s"
+}
+`
+
+var testAccPostgreSQLDatabaseTemplateConfig = `
+resource "postgresql_database" "template" {
+  name = "tf_tests_db_template"
+}
+
+resource "postgresql_database" "from_template0" {
+  name     = "tf_tests_db_from_template0"
+  template = "template0"
+}
+`
+
+var testAccPostgreSQLDatabaseFromTemplateConfig = testAccPostgreSQLDatabaseTemplateConfig + `
+resource "postgresql_database" "from_template" {
+  name                           = "tf_tests_db_from_template"
+  template                       = "${postgresql_database.template.name}"
+  terminate_template_connections = %t
+}
+`
+
+var testAccPostgreSQLDatabaseTablespaceConfig = `
+resource "postgresql_database" "tablespace" {
+  name            = "tf_tests_db_tablespace"
+  tablespace_name = "%s"
+}
+`
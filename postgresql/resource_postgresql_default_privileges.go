@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -25,7 +26,7 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
-				Description: "The name of the role to which grant default privileges on",
+				Description: "The name of the role to which grant default privileges on (or PUBLIC)",
 			},
 			"database": {
 				Type:        schema.TypeString,
@@ -41,9 +42,9 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 			},
 			"schema": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				ForceNew:    true,
-				Description: "The database schema to set default privileges for this role",
+				Description: "The database schema to set default privileges for this role (required unless object_type is schema)",
 			},
 			"object_type": {
 				Type:     schema.TypeString,
@@ -52,8 +53,10 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					"table",
 					"sequence",
+					"schema",
+					"type",
 				}, false),
-				Description: "The PostgreSQL object type to set the default privileges on (one of: table, sequence)",
+				Description: "The PostgreSQL object type to set the default privileges on (one of: table, sequence, schema, type)",
 			},
 			"privileges": &schema.Schema{
 				Type:        schema.TypeSet,
@@ -63,13 +66,21 @@ func resourcePostgreSQLDefaultPrivileges() *schema.Resource {
 				MinItems:    1,
 				Description: "The list of privileges to apply as default privileges",
 			},
+			"grant_owner_membership": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, owner is temporarily granted to the current user when it is not a member of it (requires CREATEROLE or the ADMIN option on owner)",
+			},
 		},
 	}
 }
 
 func resourcePostgreSQLDefaultPrivilegesRead(d *schema.ResourceData, meta interface{}) error {
-
 	client := meta.(*Client)
+	client.catalogLock.RLock()
+	defer client.catalogLock.RUnlock()
+
 	exists, err := checkRoleDBSchemaExists(client, d)
 	if err != nil {
 		return err
@@ -85,64 +96,162 @@ func resourcePostgreSQLDefaultPrivilegesRead(d *schema.ResourceData, meta interf
 	}
 	defer txn.Rollback()
 
-	return readRoleDefaultPrivileges(txn, d)
+	return readRoleDefaultPrivileges(client, txn, d)
 }
 
 func resourcePostgreSQLDefaultPrivilegesCreate(d *schema.ResourceData, meta interface{}) error {
-	if err := validatePrivileges(d.Get("object_type").(string), d.Get("privileges").(*schema.Set).List()); err != nil {
+	if err := validatePrivileges(meta.(*Client), d.Get("object_type").(string), d.Get("privileges").(*schema.Set).List()); err != nil {
 		return err
 	}
 
 	client := meta.(*Client)
-	database := d.Get("database").(string)
-
-	txn, err := startTransaction(client, database)
-	if err != nil {
+	if err := checkNotCockroachDB(client, "the default privileges of pg_default_acl"); err != nil {
 		return err
 	}
-	defer txn.Rollback()
 
-	// Revoke all privileges before granting otherwise reducing privileges will not work.
-	// We just have to revoke them in the same transaction so role will not lost his privileges between revoke and grant.
-	if err = revokeRoleDefaultPrivileges(txn, d); err != nil {
-		return err
-	}
+	client.catalogLock.Lock()
+	defer client.catalogLock.Unlock()
 
-	if err = grantRoleDefaultPrivileges(txn, d); err != nil {
-		return err
+	// The default privileges on schemas are global to the database, they
+	// cannot be set IN SCHEMA.
+	objectType := d.Get("object_type").(string)
+	pgSchema := d.Get("schema").(string)
+	switch {
+	case objectType == "schema" && pgSchema != "":
+		return fmt.Errorf("schema cannot be set with object_type schema: the default privileges on schemas apply to the whole database")
+	case objectType == "schema" && !client.featureSupported(featureDefaultPrivilegesSchemas):
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support default privileges on schemas", client.version.String())
+	case objectType != "schema" && pgSchema == "":
+		return fmt.Errorf("schema is required with object_type %s", objectType)
+	case objectType == "type" && !client.featureSupported(featureDefaultPrivilegesTypes):
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support default privileges on types", client.version.String())
 	}
 
-	if err := txn.Commit(); err != nil {
+	err := withTxnRetry(func() error {
+		return withOwnerMembership(client, d, func(txn *sql.Tx) error {
+			// Revoke all privileges before granting otherwise reducing privileges will not work.
+			// We just have to revoke them in the same transaction so role will not lost his privileges between revoke and grant.
+			if err := revokeRoleDefaultPrivileges(txn, d); err != nil {
+				return err
+			}
+
+			return grantRoleDefaultPrivileges(txn, d)
+		})
+	})
+	if err != nil {
 		return err
 	}
 
 	d.SetId(generateDefaultPrivilegesID(d))
 
-	txn, err = startTransaction(client, d.Get("database").(string))
+	txn, err := startTransaction(client, d.Get("database").(string))
 	if err != nil {
 		return err
 	}
 	defer txn.Rollback()
 
-	return readRoleDefaultPrivileges(txn, d)
+	return readRoleDefaultPrivileges(client, txn, d)
 }
 
 func resourcePostgreSQLDefaultPrivilegesDelete(d *schema.ResourceData, meta interface{}) error {
-	txn, err := startTransaction(meta.(*Client), d.Get("database").(string))
+	client := meta.(*Client)
+	client.catalogLock.Lock()
+	defer client.catalogLock.Unlock()
+
+	return withTxnRetry(func() error {
+		return withOwnerMembership(client, d, func(txn *sql.Tx) error {
+			revokeRoleDefaultPrivileges(txn, d)
+			return nil
+		})
+	})
+}
+
+// withOwnerMembership runs fn, which alters the default privileges of owner,
+// in a transaction of database and commits it.  Only the members of owner (or
+// a superuser) can alter its default privileges.  With grant_owner_membership,
+// a current user which is not a member of owner is granted it for the duration
+// of fn, which requires CREATEROLE or the ADMIN option on owner.  CREATEROLE is
+// not inherited through SET ROLE, so the transaction runs as the connection
+// user and only assumes the role of the provider around fn.  The membership is
+// revoked before the commit, so it is never visible outside of the transaction
+// and does not outlive a failure.  The callers hold catalogLock, which
+// serializes the resources sharing the same owner.
+func withOwnerMembership(client *Client, d *schema.ResourceData, fn func(txn *sql.Tx) error) error {
+	database := d.Get("database").(string)
+	owner := d.Get("owner").(string)
+
+	if !d.Get("grant_owner_membership").(bool) {
+		txn, err := startTransaction(client, database)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		if err := fn(txn); err != nil {
+			return err
+		}
+		return txn.Commit()
+	}
+
+	// Since PostgreSQL 16, the membership also needs the SET option.
+	privilege := "MEMBER"
+	if client.featureSupported(featureMembershipOptions) {
+		privilege = "SET"
+	}
+
+	txn, err := startConnectionUserTransactionContext(context.Background(), client, database)
 	if err != nil {
 		return err
 	}
 	defer txn.Rollback()
 
-	revokeRoleDefaultPrivileges(txn, d)
-	if err := txn.Commit(); err != nil {
+	var grantee string
+	var isMember bool
+	if err := txn.QueryRow(
+		"SELECT grantee, pg_has_role(grantee, $2, $3) FROM (SELECT COALESCE(NULLIF($1, ''), current_user)::name AS grantee) AS g",
+		client.config.AssumeRole, owner, privilege,
+	).Scan(&grantee, &isMember); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not check if the current user is a member of %s: {{err}}", owner), err)
+	}
+
+	if !isMember {
+		log.Printf("[DEBUG] granting role %s to %s to alter its default privileges", owner, grantee)
+		if _, err := txn.Exec(fmt.Sprintf(
+			"GRANT %s TO %s", pq.QuoteIdentifier(owner), pq.QuoteIdentifier(grantee),
+		)); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not grant role %s to %s to alter its default privileges: {{err}}", owner, grantee), err)
+		}
+	}
+
+	if role := client.config.AssumeRole; role != "" {
+		if _, err := txn.Exec(fmt.Sprintf("SET LOCAL ROLE %s", pq.QuoteIdentifier(role))); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not assume role %s: {{err}}", role), err)
+		}
+	}
+
+	if err := fn(txn); err != nil {
 		return err
 	}
 
-	return nil
+	if isMember {
+		return txn.Commit()
+	}
+
+	// Since PostgreSQL 16, only the membership granted above by the
+	// connection user is revoked, the other grants of owner are kept.
+	if _, err := txn.Exec("RESET ROLE"); err != nil {
+		return errwrap.Wrapf("could not reset role: {{err}}", err)
+	}
+	if _, err := txn.Exec(fmt.Sprintf(
+		"REVOKE %s FROM %s", pq.QuoteIdentifier(owner), pq.QuoteIdentifier(grantee),
+	)); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not revoke role %s from %s: {{err}}", owner, grantee), err)
+	}
+
+	return txn.Commit()
 }
 
-func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func readRoleDefaultPrivileges(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get("role").(string)
 	owner := d.Get("owner").(string)
 	pgSchema := d.Get("schema").(string)
@@ -151,18 +260,27 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	// This query aggregates the list of default privileges type (prtype)
 	// for the role (grantee), owner (grantor), schema (namespace name)
 	// and the specified object type (defaclobjtype).
+	// The default privileges which are not set in a schema (e.g. on schemas)
+	// have no namespace (0), they are matched by an empty schema.
 	query := `SELECT array_agg(prtype) FROM (
 		SELECT defaclnamespace, (aclexplode(defaclacl)).* FROM pg_default_acl
 		WHERE defaclobjtype = $3
 	) AS t (namespace, grantor_oid, grantee_oid, prtype, grantable)
 
-	JOIN pg_namespace ON pg_namespace.oid = namespace
-	WHERE pg_get_userbyid(grantee_oid) = $1 AND nspname = $2 AND pg_get_userbyid(grantor_oid) = $4;
+	LEFT JOIN pg_namespace ON pg_namespace.oid = namespace
+	WHERE CASE WHEN grantee_oid = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(grantee_oid) END = $1
+		AND COALESCE(nspname, '') = $2 AND pg_get_userbyid(grantor_oid) = $4;
 `
 	var privileges pq.ByteaArray
 
+	// In an aclitem, the PUBLIC pseudo-role is represented by the 0 grantee.
+	grantee := role
+	if isPublicRole(role) {
+		grantee = publicRole
+	}
+
 	if err := txn.QueryRow(
-		query, role, pgSchema, objectTypes[objectType], owner,
+		query, grantee, pgSchema, objectTypes[objectType], owner,
 	).Scan(&privileges); err != nil {
 		return errwrap.Wrapf("could not read default privileges: {{err}}", err)
 	}
@@ -174,8 +292,12 @@ func readRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 		return nil
 	}
 
+	// The privileges are kept as configured if they are equivalent, e.g. ALL
+	// read back as the list of all the privileges of the object type.
 	privilegesSet := pgArrayToSet(privileges)
-	d.Set("privileges", privilegesSet)
+	if !privilegesEqual(c, objectType, privilegesSet, d.Get("privileges").(*schema.Set)) {
+		d.Set("privileges", privilegesSet)
+	}
 	d.SetId(generateDefaultPrivilegesID(d))
 
 	return nil
@@ -196,12 +318,12 @@ func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	// In that case, the only solution would be to have the PostgreSQL user used by Terraform
 	// to be also part of the database owner role.
 
-	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s GRANT %s ON %sS TO %s",
+	query := fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s%s GRANT %s ON %sS TO %s",
 		pq.QuoteIdentifier(d.Get("owner").(string)),
-		pq.QuoteIdentifier(pgSchema),
+		inSchemaClause(pgSchema),
 		strings.Join(privileges, ","),
 		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(role),
+		quoteRoleName(role),
 	)
 
 	_, err := txn.Exec(
@@ -216,17 +338,26 @@ func grantRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 
 func revokeRoleDefaultPrivileges(txn *sql.Tx, d *schema.ResourceData) error {
 	query := fmt.Sprintf(
-		"ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s REVOKE ALL ON %sS FROM %s",
+		"ALTER DEFAULT PRIVILEGES FOR ROLE %s%s REVOKE ALL ON %sS FROM %s",
 		pq.QuoteIdentifier(d.Get("owner").(string)),
-		pq.QuoteIdentifier(d.Get("schema").(string)),
+		inSchemaClause(d.Get("schema").(string)),
 		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(d.Get("role").(string)),
+		quoteRoleName(d.Get("role").(string)),
 	)
 
 	_, err := txn.Exec(query)
 	return err
 }
 
+// inSchemaClause returns the IN SCHEMA clause of ALTER DEFAULT PRIVILEGES,
+// empty if the default privileges are not set in a schema.
+func inSchemaClause(pgSchema string) string {
+	if pgSchema == "" {
+		return ""
+	}
+	return " IN SCHEMA " + pq.QuoteIdentifier(pgSchema)
+}
+
 func generateDefaultPrivilegesID(d *schema.ResourceData) string {
 	return strings.Join([]string{
 		d.Get("role").(string), d.Get("database").(string), d.Get("schema").(string),
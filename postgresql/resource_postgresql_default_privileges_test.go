@@ -3,10 +3,15 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/lib/pq"
 )
 
 func TestAccPostgresqlDefaultPrivileges(t *testing.T) {
@@ -55,6 +60,417 @@ func TestAccPostgresqlDefaultPrivileges(t *testing.T) {
 	})
 }
 
+func TestDefaultPrivilegesCockroachDB(t *testing.T) {
+	version, flavor, err := parseServerVersion("CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)")
+	if err != nil {
+		t.Fatalf("could not parse version: %v", err)
+	}
+	if version != nil {
+		t.Fatalf("expected no PostgreSQL version for CockroachDB, got %s", version)
+	}
+	client := &Client{version: semver.MustParse("13.0.0"), flavor: flavor}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLDefaultPrivileges().Schema, map[string]interface{}{
+		"role":        "test_role",
+		"database":    "test_db",
+		"owner":       "test_owner",
+		"schema":      "public",
+		"object_type": "table",
+		"privileges":  []interface{}{"SELECT"},
+	})
+
+	err = resourcePostgreSQLDefaultPrivilegesCreate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "CockroachDB") {
+		t.Errorf("expected an error about CockroachDB, got %v", err)
+	}
+}
+
+func TestDefaultPrivilegesSchemaValidation(t *testing.T) {
+	client := &Client{version: semver.MustParse("9.1.0")}
+
+	for _, tc := range []struct {
+		objectType string
+		schema     string
+		privilege  string
+		expected   string
+	}{
+		{"schema", "public", "USAGE", "schema cannot be set with object_type schema"},
+		{"schema", "", "USAGE", "does not support default privileges on schemas"},
+		{"table", "", "SELECT", "schema is required with object_type table"},
+		{"type", "public", "USAGE", "does not support default privileges on types"},
+	} {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLDefaultPrivileges().Schema, map[string]interface{}{
+			"role":        "test_role",
+			"database":    "test_db",
+			"owner":       "test_owner",
+			"schema":      tc.schema,
+			"object_type": tc.objectType,
+			"privileges":  []interface{}{tc.privilege},
+		})
+
+		err := resourcePostgreSQLDefaultPrivilegesCreate(d, client)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("object_type %s, schema %q: expected an error containing %q, got %v", tc.objectType, tc.schema, tc.expected, err)
+		}
+	}
+}
+
+func TestAccPostgresqlDefaultPrivileges_Schemas(t *testing.T) {
+	testCheckCompatibleVersion(t, featureDefaultPrivilegesSchemas)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testDPSchemas = `
+	resource "postgresql_default_privileges" "test_schemas" {
+		database    = "%s"
+		owner       = "%s"
+		role        = "%s"
+		object_type = "schema"
+		privileges  = %s
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDPSchemas, dbName, config.Username, roleName, `["USAGE"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckNewSchemaPrivileges(t, dbName, roleName, []string{"USAGE"}),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_schemas", "schema", ""),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_schemas", "privileges.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testDPSchemas, dbName, config.Username, roleName, `["ALL"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckNewSchemaPrivileges(t, dbName, roleName, []string{"USAGE", "CREATE"}),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_schemas", "privileges.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlDefaultPrivileges_Types(t *testing.T) {
+	testCheckCompatibleVersion(t, featureDefaultPrivilegesTypes)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testDPTypes = fmt.Sprintf(`
+	resource "postgresql_default_privileges" "test_types" {
+		database    = "%s"
+		owner       = "%s"
+		role        = "%s"
+		schema      = "public"
+		object_type = "type"
+		privileges  = ["USAGE"]
+	}
+	`, dbName, config.Username, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDPTypes,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckNewTypeUsage(t, dbName, roleName),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_types", "object_type", "type"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_types", "privileges.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckNewTypeUsage creates a type in the public schema of the database
+// and checks role has been granted USAGE on it.  USAGE on types is granted to
+// PUBLIC by default, so the ACL of the type is checked rather than
+// has_type_privilege.
+func testCheckNewTypeUsage(t *testing.T, dbName, role string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		if _, err := db.Exec("CREATE TYPE public.test_dp_type AS ENUM ('a', 'b')"); err != nil {
+			return fmt.Errorf("could not create test type in db %s: %v", dbName, err)
+		}
+		defer db.Exec("DROP TYPE public.test_dp_type")
+
+		var granted bool
+		if err := db.QueryRow(
+			`SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_type t, aclexplode(t.typacl) a `+
+				`WHERE t.oid = 'public.test_dp_type'::regtype `+
+				`AND a.grantee = (SELECT oid FROM pg_catalog.pg_roles WHERE rolname = $1) `+
+				`AND a.privilege_type = 'USAGE')`,
+			role,
+		).Scan(&granted); err != nil {
+			return fmt.Errorf("could not read the privileges of the test type: %v", err)
+		}
+		if !granted {
+			return fmt.Errorf("expected %s to be granted USAGE on the new type", role)
+		}
+		return nil
+	}
+}
+
+// testCheckNewSchemaPrivileges creates a schema in the database and checks
+// role has exactly the expected privileges on it.
+func testCheckNewSchemaPrivileges(t *testing.T, dbName, role string, expected []string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		if _, err := db.Exec("CREATE SCHEMA test_dp_schema"); err != nil {
+			return fmt.Errorf("could not create test schema in db %s: %v", dbName, err)
+		}
+		defer db.Exec("DROP SCHEMA test_dp_schema")
+
+		for _, priv := range []string{"USAGE", "CREATE"} {
+			var has bool
+			if err := db.QueryRow(
+				"SELECT has_schema_privilege($1, 'test_dp_schema', $2)", role, priv,
+			).Scan(&has); err != nil {
+				return fmt.Errorf("could not check the %s privilege of %s: %v", priv, role, err)
+			}
+			if has != sliceContainsStr(expected, priv) {
+				return fmt.Errorf("expected %s to have %s on the new schema: %t, got %t", role, priv, !has, has)
+			}
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlDefaultPrivileges_Public(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	// The test role is granted SELECT through PUBLIC.
+	var testDPPublic = fmt.Sprintf(`
+	resource "postgresql_default_privileges" "test_public" {
+		database    = "%s"
+		owner       = "%s"
+		role        = "public"
+		schema      = "public"
+		object_type = "table"
+		privileges  = ["SELECT"]
+	}
+	`, dbName, config.Username)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDPPublic,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						dropFunc := createTestTable(t, dbSuffix)
+						defer dropFunc()
+
+						return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+					},
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_public", "role", "public"),
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_public", "privileges.#", "1"),
+				),
+			},
+			// Revoking the default privileges outside of Terraform is detected.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf(
+						"ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA public REVOKE SELECT ON TABLES FROM PUBLIC",
+						pq.QuoteIdentifier(config.Username),
+					))
+				},
+				Config:             testDPPublic,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testDPPublic,
+				Check: func(*terraform.State) error {
+					dropFunc := createTestTable(t, dbSuffix)
+					defer dropFunc()
+
+					return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+				},
+			},
+		},
+	})
+}
+
+// The provider connects as a role which can grant the owner but is not a
+// member of it, grant_owner_membership grants it temporarily.
+func TestAccPostgresqlDefaultPrivileges_NonMemberOwner(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	adminName := fmt.Sprintf("tf_tests_dp_admin_%s", dbSuffix)
+
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not connect to the PostgreSQL server: %v", err)
+	}
+
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+		"CREATE ROLE %s LOGIN CREATEROLE PASSWORD '%s'", adminName, testRolePassword,
+	))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP ROLE IF EXISTS %s", adminName))
+	// Since PostgreSQL 16, CREATEROLE needs the ADMIN option to grant a role.
+	if client.featureSupported(featureMembershipOptions) {
+		dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+			"GRANT %s TO %s WITH ADMIN TRUE, INHERIT FALSE, SET FALSE", roleName, adminName,
+		))
+	}
+
+	var testDPNonMember = fmt.Sprintf(`
+	provider "postgresql" {
+		username = "%s"
+		password = "%s"
+	}
+
+	resource "postgresql_default_privileges" "test_non_member" {
+		database               = "%s"
+		owner                  = "%s"
+		role                   = "public"
+		schema                 = "public"
+		object_type            = "table"
+		privileges             = ["SELECT"]
+		grant_owner_membership = true
+	}
+	`, adminName, testRolePassword, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				// Without grant_owner_membership, the membership is not granted.
+				Config:      strings.Replace(testDPNonMember, "grant_owner_membership = true", "", 1),
+				ExpectError: regexp.MustCompile("permission denied"),
+			},
+			{
+				Config: testDPNonMember,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_non_member", "privileges.#", "1"),
+					testCheckNotRoleMember(t, roleName, adminName),
+				),
+			},
+		},
+	})
+}
+
+// The provider connects as a role which can grant the owner and assumes a
+// role which is not a member of it: the owner is granted to the assumed role
+// by the connection user.
+func TestAccPostgresqlDefaultPrivileges_NonMemberOwnerAssumeRole(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	adminName := fmt.Sprintf("tf_tests_dp_admin_%s", dbSuffix)
+	assumedName := fmt.Sprintf("tf_tests_dp_assumed_%s", dbSuffix)
+
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not connect to the PostgreSQL server: %v", err)
+	}
+
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+		"CREATE ROLE %s LOGIN CREATEROLE PASSWORD '%s'", adminName, testRolePassword,
+	))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP ROLE IF EXISTS %s", adminName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("CREATE ROLE %s", assumedName))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP ROLE IF EXISTS %s", assumedName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT %s TO %s", assumedName, adminName))
+	// Since PostgreSQL 16, CREATEROLE needs the ADMIN option to grant a role.
+	if client.featureSupported(featureMembershipOptions) {
+		dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+			"GRANT %s TO %s WITH ADMIN TRUE, INHERIT FALSE, SET FALSE", roleName, adminName,
+		))
+	}
+
+	var testDPAssumeRole = fmt.Sprintf(`
+	provider "postgresql" {
+		username    = "%s"
+		password    = "%s"
+		assume_role = "%s"
+	}
+
+	resource "postgresql_default_privileges" "test_assume_role" {
+		database               = "%s"
+		owner                  = "%s"
+		role                   = "public"
+		schema                 = "public"
+		object_type            = "table"
+		privileges             = ["SELECT"]
+		grant_owner_membership = true
+	}
+	`, adminName, testRolePassword, assumedName, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDPAssumeRole,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_default_privileges.test_assume_role", "privileges.#", "1"),
+					testCheckNotRoleMember(t, roleName, assumedName),
+				),
+			},
+		},
+	})
+}
+
+// testCheckNotRoleMember checks member is not a member of role, i.e. the
+// temporary membership has been revoked.
+func testCheckNotRoleMember(t *testing.T, role, member string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr("postgres"))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool: %v", err)
+		}
+		defer db.Close()
+
+		var isMember bool
+		if err := db.QueryRow(
+			"SELECT pg_has_role($1, $2, 'USAGE')", member, role,
+		).Scan(&isMember); err != nil {
+			return fmt.Errorf("could not check if %s is a member of %s: %v", member, role, err)
+		}
+		if isMember {
+			return fmt.Errorf("expected %s not to be a member of %s", member, role)
+		}
+		return nil
+	}
+}
+
 func createTestTable(t *testing.T, dbSuffix string) func() {
 	config := getTestConfig(t)
 	dbName, _ := getTestDBNames(dbSuffix)
@@ -2,20 +2,38 @@ package postgresql
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/lib/pq"
 )
 
 const (
-	extNameAttr    = "name"
-	extSchemaAttr  = "schema"
-	extVersionAttr = "version"
+	extNameAttr          = "name"
+	extSchemaAttr        = "schema"
+	extVersionAttr       = "version"
+	extCreateCascadeAttr = "create_cascade"
+	extFromVersionAttr   = "from_version"
+	extIfNotExistsAttr   = "if_not_exists"
+	extDropCascadeAttr   = "drop_cascade"
+	extObjectsAttr       = "objects"
+	extCreateSchemaAttr  = "create_schema"
+	extObjectTypeAttr    = "type"
+	extObjectNameAttr    = "name"
+
+	// extDefaultTimeout is the default timeout of the operations on an
+	// extension.  Creating or updating some extensions (e.g. postgis) takes a
+	// few minutes.
+	extDefaultTimeout = 10 * time.Minute
 )
 
 func resourcePostgreSQLExtension() *schema.Resource {
@@ -29,11 +47,18 @@ func resourcePostgreSQLExtension() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(extDefaultTimeout),
+			Update: schema.DefaultTimeout(extDefaultTimeout),
+			Delete: schema.DefaultTimeout(extDefaultTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			extNameAttr: {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateIdentifier,
 			},
 			extSchemaAttr: {
 				Type:        schema.TypeString,
@@ -47,19 +72,149 @@ func resourcePostgreSQLExtension() *schema.Resource {
 				Computed:    true,
 				Description: "Sets the version number of the extension",
 			},
+			extCreateCascadeAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Automatically install any extensions that this extension depends on",
+			},
+			extFromVersionAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Create the extension from the existing objects of this old version (e.g. unpackaged)",
+			},
+			extIfNotExistsAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, use the existing extension if it exists",
+			},
+			extDropCascadeAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, also drop the objects which depend on the extension, including other extensions",
+			},
+			extCreateSchemaAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, create the schema of the extension if it does not exist",
+			},
+			extObjectsAttr: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						extObjectTypeAttr: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(extensionObjectTypes(), false),
+							Description:  "The type of the object",
+						},
+						extObjectNameAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the object, optionally schema-qualified, with the argument types of a function",
+						},
+					},
+				},
+				Description: "The objects added to the extension with ALTER EXTENSION ... ADD",
+			},
+			connectionAttr:      connectionSchema(),
+			sessionSettingsAttr: sessionSettingsSchema(),
 		},
 	}
 }
 
 func resourcePostgreSQLExtensionCreate(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
+	if err := checkNotCockroachDB(c, "extensions"); err != nil {
+		return err
+	}
+
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
+	ctx, cancel := operationContext(c, d, schema.TimeoutCreate)
+	defer cancel()
+
 	extName := d.Get(extNameAttr).(string)
 
+	sql, err := createExtensionSQL(c, d)
+	if err != nil {
+		return err
+	}
+
+	connClient, closeConn, err := connectionClient(c, d)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+	defer txn.Rollback()
+
+	if err := setSessionSettings(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+
+	if d.Get(extCreateSchemaAttr).(bool) {
+		if err := createExtSchema(ctx, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+	}
+
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("Error creating extension: {{err}}", err))
+	}
+
+	if err := setExtObjects(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	d.SetId(extName)
+
+	return resourcePostgreSQLExtensionReadImpl(d, meta)
+}
+
+// createExtSchema creates the schema of the extension if it does not exist.
+// The schema is not dropped with the extension.
+func createExtSchema(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	extSchema := d.Get(extSchemaAttr).(string)
+	if extSchema == "" {
+		return fmt.Errorf("%s requires the %s of the extension to be set", extCreateSchemaAttr, extSchemaAttr)
+	}
+
+	exists, err := schemaExists(txn, extSchema)
+	if err != nil || exists {
+		return err
+	}
+
+	if _, err := txn.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA %s", pq.QuoteIdentifier(extSchema))); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error creating schema %s of extension: {{err}}", extSchema), err)
+	}
+	return nil
+}
+
+// createExtensionSQL returns the CREATE EXTENSION statement of the extension.
+func createExtensionSQL(c *Client, d *schema.ResourceData) (string, error) {
 	b := bytes.NewBufferString("CREATE EXTENSION ")
-	fmt.Fprint(b, pq.QuoteIdentifier(extName))
+	if d.Get(extIfNotExistsAttr).(bool) {
+		if !c.featureSupported(featureExtensionCreateIfNotExists) {
+			return "", fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support CREATE EXTENSION IF NOT EXISTS", c.version.String())
+		}
+		fmt.Fprint(b, "IF NOT EXISTS ")
+	}
+	fmt.Fprint(b, pq.QuoteIdentifier(d.Get(extNameAttr).(string)))
 
 	if v, ok := d.GetOk(extSchemaAttr); ok {
 		fmt.Fprint(b, " SCHEMA ", pq.QuoteIdentifier(v.(string)))
@@ -69,14 +224,21 @@ func resourcePostgreSQLExtensionCreate(d *schema.ResourceData, meta interface{})
 		fmt.Fprint(b, " VERSION ", pq.QuoteIdentifier(v.(string)))
 	}
 
-	sql := b.String()
-	if _, err := c.DB().Exec(sql); err != nil {
-		return errwrap.Wrapf("Error creating extension: {{err}}", err)
+	if v, ok := d.GetOk(extFromVersionAttr); ok {
+		if !c.featureSupported(featureExtensionCreateFrom) {
+			return "", fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support CREATE EXTENSION ... FROM (removed in PostgreSQL 13)", c.version.String())
+		}
+		fmt.Fprint(b, " FROM ", pq.QuoteIdentifier(v.(string)))
 	}
 
-	d.SetId(extName)
+	if d.Get(extCreateCascadeAttr).(bool) {
+		if !c.featureSupported(featureExtensionCreateCascade) {
+			return "", fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support CREATE EXTENSION ... CASCADE", c.version.String())
+		}
+		fmt.Fprint(b, " CASCADE")
+	}
 
-	return resourcePostgreSQLExtensionReadImpl(d, meta)
+	return b.String(), nil
 }
 
 func resourcePostgreSQLExtensionExists(d *schema.ResourceData, meta interface{}) (bool, error) {
@@ -115,7 +277,7 @@ func resourcePostgreSQLExtensionReadImpl(d *schema.ResourceData, meta interface{
 		`WHERE n.oid = e.extnamespace AND e.extname = $1`
 	err := c.DB().QueryRow(query, extID).Scan(&extName, &extSchema, &extVersion)
 	switch {
-	case err == sql.ErrNoRows:
+	case err == sql.ErrNoRows, pqErrorType(err) == ErrObjectNotFound:
 		log.Printf("[WARN] PostgreSQL extension (%s) not found", d.Id())
 		d.SetId("")
 		return nil
@@ -128,19 +290,220 @@ func resourcePostgreSQLExtensionReadImpl(d *schema.ResourceData, meta interface{
 	d.Set(extVersionAttr, extVersion)
 	d.SetId(extName)
 
+	if err := readExtObjects(c, d); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// extensionObjects maps the types of objects which can be managed as members
+// of an extension to the function resolving their name and to their catalog.
+var extensionObjects = map[string]struct {
+	resolve string
+	catalog string
+}{
+	"function": {"to_regprocedure", "pg_proc"},
+	"sequence": {"to_regclass", "pg_class"},
+	"table":    {"to_regclass", "pg_class"},
+	"type":     {"to_regtype", "pg_type"},
+	"view":     {"to_regclass", "pg_class"},
+}
+
+func extensionObjectTypes() []string {
+	types := make([]string, 0, len(extensionObjects))
+	for objType := range extensionObjects {
+		types = append(types, objType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// extensionObjectMember resolves the object of the given type and name and
+// returns its name as printed by PostgreSQL, properly quoted, and whether it
+// is a member of the extension.  The returned name is empty if the object
+// does not exist.
+func extensionObjectMember(q queryer, extName, objType, objName string) (string, bool, error) {
+	obj := extensionObjects[objType]
+	query := fmt.Sprintf(
+		`SELECT o::text, EXISTS (`+
+			`SELECT 1 FROM pg_catalog.pg_depend d `+
+			`JOIN pg_catalog.pg_extension e ON e.oid = d.refobjid `+
+			`WHERE d.classid = 'pg_catalog.%s'::regclass AND d.objid = o::oid `+
+			`AND d.refclassid = 'pg_catalog.pg_extension'::regclass AND d.deptype = 'e' `+
+			`AND e.extname = $2) `+
+			`FROM pg_catalog.%s($1) AS o`,
+		obj.catalog, obj.resolve,
+	)
+
+	var name sql.NullString
+	var member bool
+	if err := q.QueryRow(query, objName, extName).Scan(&name, &member); err != nil {
+		return "", false, errwrap.Wrapf(fmt.Sprintf("could not resolve %s %s: {{err}}", objType, objName), err)
+	}
+	return name.String, name.Valid && member, nil
+}
+
+// readExtObjects sets the objects of the configuration which are members of
+// the extension.  The other members of the extension, e.g. the ones created
+// by its script, are ignored.
+func readExtObjects(c *Client, d *schema.ResourceData) error {
+	objects := d.Get(extObjectsAttr).(*schema.Set).List()
+	if len(objects) == 0 || !c.featureSupported(featureExtensionMembers) {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(objects))
+	for _, o := range objects {
+		object := o.(map[string]interface{})
+		_, member, err := extensionObjectMember(c.DB(), d.Id(), object[extObjectTypeAttr].(string), object[extObjectNameAttr].(string))
+		if err != nil {
+			return errwrap.Wrapf("Error reading extension members: {{err}}", err)
+		}
+		if member {
+			members = append(members, object)
+		}
+	}
+
+	d.Set(extObjectsAttr, members)
+	return nil
+}
+
+// setExtObjects adds the configured objects which are not members of the
+// extension yet, and drops the ones removed from the configuration.
+func setExtObjects(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	o, n := d.GetChange(extObjectsAttr)
+	oldObjects, newObjects := o.(*schema.Set), n.(*schema.Set)
+	if oldObjects.Len() == 0 && newObjects.Len() == 0 {
+		return nil
+	}
+
+	if !c.featureSupported(featureExtensionMembers) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support ALTER EXTENSION ... ADD/DROP", c.version.String())
+	}
+
+	extName := d.Get(extNameAttr).(string)
+	alter := func(object interface{}, action string, member bool) error {
+		objType := object.(map[string]interface{})[extObjectTypeAttr].(string)
+		objName := object.(map[string]interface{})[extObjectNameAttr].(string)
+
+		name, isMember, err := extensionObjectMember(txn, extName, objType, objName)
+		switch {
+		case err != nil:
+			return err
+		case name == "" && action == "ADD":
+			return fmt.Errorf("could not add %s %s to extension %s: it does not exist", objType, objName, extName)
+		case name == "" || isMember == member:
+			return nil
+		}
+
+		sql := fmt.Sprintf("ALTER EXTENSION %s %s %s %s", pq.QuoteIdentifier(extName), action, strings.ToUpper(objType), name)
+		if _, err := txn.ExecContext(ctx, sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error updating the members of extension %s: {{err}}", extName), err)
+		}
+		return nil
+	}
+
+	for _, object := range oldObjects.Difference(newObjects).List() {
+		if err := alter(object, "DROP", false); err != nil {
+			return err
+		}
+	}
+	// All the configured objects are checked, to add back the ones dropped
+	// from the extension outside of Terraform.
+	for _, object := range newObjects.List() {
+		if err := alter(object, "ADD", true); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// dependentExtensions returns the names of the extensions which require the
+// given extension, and prevent it from being dropped without CASCADE.
+func dependentExtensions(txn *sql.Tx, extName string) ([]string, error) {
+	rows, err := txn.Query(
+		`SELECT e.extname FROM pg_catalog.pg_depend d `+
+			`JOIN pg_catalog.pg_extension e ON e.oid = d.objid `+
+			`JOIN pg_catalog.pg_extension r ON r.oid = d.refobjid `+
+			`WHERE d.classid = 'pg_catalog.pg_extension'::regclass `+
+			`AND d.refclassid = 'pg_catalog.pg_extension'::regclass `+
+			`AND d.deptype = 'n' AND r.extname = $1 ORDER BY 1`,
+		extName,
+	)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("could not list the extensions depending on %s: {{err}}", extName), err)
+	}
+	defer rows.Close()
+
+	dependents := []string{}
+	for rows.Next() {
+		var dependent string
+		if err := rows.Scan(&dependent); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("could not scan the extensions depending on %s: {{err}}", extName), err)
+		}
+		dependents = append(dependents, dependent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("could not list the extensions depending on %s: {{err}}", extName), err)
+	}
+	return dependents, nil
+}
+
 func resourcePostgreSQLExtensionDelete(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
+	ctx, cancel := operationContext(c, d, schema.TimeoutDelete)
+	defer cancel()
+
 	extID := d.Id()
 
+	connClient, closeConn, err := connectionClient(c, d)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutDelete, err)
+	}
+	defer txn.Rollback()
+
+	if err := setSessionSettings(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, err)
+	}
+
 	sql := fmt.Sprintf("DROP EXTENSION %s", pq.QuoteIdentifier(extID))
-	if _, err := c.DB().Exec(sql); err != nil {
-		return errwrap.Wrapf("Error deleting extension: {{err}}", err)
+	if d.Get(extDropCascadeAttr).(bool) {
+		sql += " CASCADE"
+	} else {
+		dependents, err := dependentExtensions(txn, extID)
+		if err != nil {
+			return operationError(ctx, schema.TimeoutDelete, err)
+		}
+		if len(dependents) > 0 {
+			return fmt.Errorf(
+				"Error deleting extension %s: it is required by the extension(s) %s, destroy them first or set %s to drop them as well",
+				extID, strings.Join(dependents, ", "), extDropCascadeAttr,
+			)
+		}
+	}
+
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("Error deleting extension: {{err}}", err))
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("could not commit transaction: {{err}}", err))
 	}
 
 	d.SetId("")
@@ -153,20 +516,53 @@ func resourcePostgreSQLExtensionUpdate(d *schema.ResourceData, meta interface{})
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	// Can't rename a schema
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
 
-	if err := setExtSchema(c.DB(), d); err != nil {
+	connClient, closeConn, err := connectionClient(c, d)
+	if err != nil {
 		return err
 	}
+	defer closeConn()
 
-	if err := setExtVersion(c.DB(), d); err != nil {
-		return err
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+	defer txn.Rollback()
+
+	if err := setSessionSettings(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	// Can't rename a schema
+
+	if d.HasChange(extSchemaAttr) && d.Get(extCreateSchemaAttr).(bool) {
+		if err := createExtSchema(ctx, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutUpdate, err)
+		}
+	}
+
+	if err := setExtSchema(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err := setExtVersion(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err := setExtObjects(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
 	}
 
 	return resourcePostgreSQLExtensionReadImpl(d, meta)
 }
 
-func setExtSchema(db *sql.DB, d *schema.ResourceData) error {
+func setExtSchema(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(extSchemaAttr) {
 		return nil
 	}
@@ -178,16 +574,29 @@ func setExtSchema(db *sql.DB, d *schema.ResourceData) error {
 		return errors.New("Error setting extension name to an empty string")
 	}
 
+	// Only relocatable extensions can be moved to another schema, give a
+	// clearer error than the server for the other ones.
+	var relocatable bool
+	err := txn.QueryRow("SELECT extrelocatable FROM pg_catalog.pg_extension WHERE extname = $1", extID).Scan(&relocatable)
+	switch {
+	case err == sql.ErrNoRows:
+		return fmt.Errorf("Error updating extension SCHEMA: extension %s does not exist", extID)
+	case err != nil:
+		return errwrap.Wrapf("Error reading extension: {{err}}", err)
+	case !relocatable:
+		return fmt.Errorf("Error updating extension SCHEMA: extension %s is not relocatable, it has to be recreated to change its schema", extID)
+	}
+
 	sql := fmt.Sprintf("ALTER EXTENSION %s SET SCHEMA %s",
 		pq.QuoteIdentifier(extID), pq.QuoteIdentifier(n))
-	if _, err := db.Exec(sql); err != nil {
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating extension SCHEMA: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setExtVersion(db *sql.DB, d *schema.ResourceData) error {
+func setExtVersion(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(extVersionAttr) {
 		return nil
 	}
@@ -197,16 +606,52 @@ func setExtVersion(db *sql.DB, d *schema.ResourceData) error {
 	b := bytes.NewBufferString("ALTER EXTENSION ")
 	fmt.Fprintf(b, "%s UPDATE", pq.QuoteIdentifier(extID))
 
-	_, nraw := d.GetChange(extVersionAttr)
+	oraw, nraw := d.GetChange(extVersionAttr)
+	o := oraw.(string)
 	n := nraw.(string)
 	if n != "" {
+		if err := validateExtUpdatePath(txn, extID, o, n); err != nil {
+			return err
+		}
 		fmt.Fprintf(b, " TO %s", pq.QuoteIdentifier(n))
 	}
 
 	sql := b.String()
-	if _, err := db.Exec(sql); err != nil {
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating extension version: {{err}}", err)
 	}
 
 	return nil
 }
+
+// validateExtUpdatePath checks that an update path exists between two versions
+// of an extension and returns an error listing the reachable versions if not.
+func validateExtUpdatePath(txn *sql.Tx, extName, from, to string) error {
+	if from == "" || from == to {
+		return nil
+	}
+
+	var path sql.NullString
+	query := "SELECT path FROM pg_catalog.pg_extension_update_paths($1) WHERE source = $2 AND target = $3"
+	err := txn.QueryRow(query, extName, from, to).Scan(&path)
+	switch {
+	case err == sql.ErrNoRows:
+		// The target version is unknown for this extension.
+	case err != nil:
+		return errwrap.Wrapf("Error reading extension update paths: {{err}}", err)
+	case path.Valid:
+		return nil
+	}
+
+	var available []string
+	query = "SELECT array_agg(target ORDER BY target) FROM pg_catalog.pg_extension_update_paths($1) WHERE source = $2 AND path IS NOT NULL"
+	if err := txn.QueryRow(query, extName, from).Scan(pq.Array(&available)); err != nil {
+		return errwrap.Wrapf("Error reading extension update paths: {{err}}", err)
+	}
+
+	if len(available) == 0 {
+		return fmt.Errorf("Error updating extension %q: no update path from version %q to %q (no other versions are available)", extName, from, to)
+	}
+
+	return fmt.Errorf("Error updating extension %q: no update path from version %q to %q (available versions: %s)", extName, from, to, strings.Join(available, ", "))
+}
@@ -3,9 +3,14 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -34,6 +39,111 @@ func TestAccPostgresqlExtension_Basic(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlExtension_Import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionConfigHyphen,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.hyphen"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.hyphen", "name", "uuid-ossp"),
+				),
+			},
+			// The import ID is the extension name, hyphens included.
+			{
+				ResourceName:            "postgresql_extension.hyphen",
+				ImportState:             true,
+				ImportStateId:           "uuid-ossp",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"create_cascade", "drop_cascade", "create_schema"},
+			},
+		},
+	})
+}
+
+func TestCreateExtensionSQL(t *testing.T) {
+	cases := []struct {
+		version  string
+		raw      map[string]interface{}
+		expected string
+		err      string
+	}{
+		{
+			version:  "9.6.0",
+			raw:      map[string]interface{}{"name": "hstore"},
+			expected: `CREATE EXTENSION "hstore"`,
+		},
+		{
+			version:  "9.6.0",
+			raw:      map[string]interface{}{"name": "hstore", "schema": "public", "version": "1.4", "create_cascade": true},
+			expected: `CREATE EXTENSION "hstore" SCHEMA "public" VERSION "1.4" CASCADE`,
+		},
+		{
+			version:  "12.0.0",
+			raw:      map[string]interface{}{"name": "hstore", "from_version": "unpackaged"},
+			expected: `CREATE EXTENSION "hstore" FROM "unpackaged"`,
+		},
+		{
+			version:  "9.6.0",
+			raw:      map[string]interface{}{"name": "hstore", "if_not_exists": true, "schema": "public"},
+			expected: `CREATE EXTENSION IF NOT EXISTS "hstore" SCHEMA "public"`,
+		},
+		{
+			version: "9.0.0",
+			raw:     map[string]interface{}{"name": "hstore", "if_not_exists": true},
+			err:     "does not support CREATE EXTENSION IF NOT EXISTS",
+		},
+		{
+			version: "13.0.0",
+			raw:     map[string]interface{}{"name": "hstore", "from_version": "unpackaged"},
+			err:     "does not support CREATE EXTENSION ... FROM",
+		},
+		{
+			version: "9.5.0",
+			raw:     map[string]interface{}{"name": "hstore", "create_cascade": true},
+			err:     "does not support CREATE EXTENSION ... CASCADE",
+		},
+	}
+
+	for _, c := range cases {
+		client := &Client{version: semver.MustParse(c.version)}
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLExtension().Schema, c.raw)
+
+		sql, err := createExtensionSQL(client, d)
+		switch {
+		case c.err != "":
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("createExtensionSQL(%v) on %s: expected error %q, got %v", c.raw, c.version, c.err, err)
+			}
+		case err != nil:
+			t.Errorf("createExtensionSQL(%v) on %s: unexpected error %v", c.raw, c.version, err)
+		case sql != c.expected:
+			t.Errorf("createExtensionSQL(%v) on %s = %q, expected %q", c.raw, c.version, sql, c.expected)
+		}
+	}
+}
+
+func TestExtensionCockroachDB(t *testing.T) {
+	_, flavor, err := parseServerVersion("CockroachDB CCL v23.1.11 (x86_64-pc-linux-gnu, built 2023/09/27 01:53:43, go1.19.10)")
+	if err != nil {
+		t.Fatalf("could not parse version: %v", err)
+	}
+	client := &Client{version: semver.MustParse("13.0.0"), flavor: flavor}
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLExtension().Schema, map[string]interface{}{
+		"name": "pgcrypto",
+	})
+
+	err = resourcePostgreSQLExtensionCreate(d, client)
+	if err == nil || !strings.Contains(err.Error(), "CockroachDB") || !strings.Contains(err.Error(), "does not support extensions") {
+		t.Errorf("expected an error about CockroachDB extensions, got %v", err)
+	}
+}
+
 func testAccCheckPostgresqlExtensionDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -118,6 +228,315 @@ func TestAccPostgresqlExtension_SchemaRename(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlExtension_CreateSchema(t *testing.T) {
+	testCheckExtensionAvailable(t, "pg_trgm")
+
+	config := getTestConfig(t)
+	defer dbExecute(t, config.connStr("postgres"), "DROP SCHEMA IF EXISTS tf_tests_ext_schema")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testAccCheckPostgresqlExtensionDestroy,
+			// The schema is not dropped with the extension.
+			testAccCheckSchemaExists("tf_tests_ext_schema", true),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlExtensionCreateSchemaConfig, false),
+				ExpectError: regexp.MustCompile("schema \"tf_tests_ext_schema\" does not exist"),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlExtensionCreateSchemaConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "schema", "tf_tests_ext_schema"),
+					testAccCheckSchemaExists("tf_tests_ext_schema", true),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckSchemaExists checks whether the schema exists in the database of
+// the provider.
+func testAccCheckSchemaExists(schemaName string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		var exists bool
+		if err := client.DB().QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = $1)", schemaName).Scan(&exists); err != nil {
+			return fmt.Errorf("could not check if schema %s exists: %v", schemaName, err)
+		}
+		if exists != expected {
+			return fmt.Errorf("expected schema %s to exist: %t, got %t", schemaName, expected, exists)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlExtension_SessionSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			// The settings apply to the transaction creating the extension.
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlExtensionSessionSettingsConfig, "transaction_read_only", "on"),
+				ExpectError: regexp.MustCompile("cannot execute CREATE EXTENSION in a read-only transaction"),
+			},
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlExtensionSessionSettingsConfig, "tf_tests_unknown", "1"),
+				ExpectError: regexp.MustCompile("could not set tf_tests_unknown"),
+			},
+			// And only to it: the read-only transaction did not leak to the
+			// connections of the provider.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlExtensionSessionSettingsConfig, "maintenance_work_mem", "64MB"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "session_settings.maintenance_work_mem", "64MB"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_CreateCascade(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionConfigCascade,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.cascade"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.cascade", "name", "earthdistance"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.cascade", "create_cascade", "true"),
+
+					// The cube extension is a dependency of earthdistance and
+					// must have been installed by the CASCADE.
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+						exists, err := checkExtensionExists(client, "cube")
+						if err != nil {
+							return fmt.Errorf("Error checking extension %s", err)
+						}
+						if !exists {
+							return fmt.Errorf("Extension cube not installed by CASCADE")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_DropDependency(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlExtensionDependencyConfig, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.cube"),
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.earthdistance"),
+				),
+			},
+			// cube cannot be dropped while earthdistance requires it.
+			{
+				Config:      testAccPostgresqlExtensionDependentConfig,
+				ExpectError: regexp.MustCompile("it is required by the extension\\(s\\) earthdistance, destroy them first"),
+			},
+			// With drop_cascade, earthdistance is dropped as well.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlExtensionDependencyConfig, true),
+			},
+			{
+				Config:             testAccPostgresqlExtensionDependentConfig,
+				ExpectNonEmptyPlan: true,
+				Check: func(*terraform.State) error {
+					client := testAccProvider.Meta().(*Client)
+					for _, extName := range []string{"cube", "earthdistance"} {
+						exists, err := checkExtensionExists(client, extName)
+						if err != nil {
+							return fmt.Errorf("Error checking extension %s", err)
+						}
+						if exists {
+							return fmt.Errorf("Extension %s still exists after the CASCADE", extName)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_InvalidVersionUpdate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.myextension"),
+				),
+			},
+			{
+				Config:      testAccPostgresqlExtensionConfigInvalidVersion,
+				ExpectError: regexp.MustCompile(`no update path from version "[^"]+" to "99.99"`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_NotRelocatable(t *testing.T) {
+	// adminpack is not relocatable, its objects are always in pg_catalog.
+	testCheckExtensionAvailable(t, "adminpack")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlExtensionNotRelocatable1,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.adminpack"),
+					resource.TestCheckResourceAttr(
+						"postgresql_extension.adminpack", "schema", "pg_catalog"),
+				),
+			},
+			{
+				Config:      testAccPostgresqlExtensionNotRelocatable2,
+				ExpectError: regexp.MustCompile(`extension adminpack is not relocatable`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_IfNotExists(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The extension is installed outside of Terraform, e.g. in the
+				// template of the database, and adopted.
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					if _, err := client.DB().Exec("CREATE EXTENSION pg_trgm"); err != nil {
+						t.Fatalf("could not create extension: %v", err)
+					}
+				},
+				Config: testAccPostgresqlExtensionIfNotExistsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "name", "pg_trgm"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "if_not_exists", "true"),
+					resource.TestCheckResourceAttrSet("postgresql_extension.myextension", "version"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlExtension_Objects(t *testing.T) {
+	testCheckCompatibleVersion(t, featureExtensionMembers)
+
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), "CREATE OR REPLACE FUNCTION tf_tests_ext_member(integer) RETURNS integer LANGUAGE sql AS 'SELECT $1'")
+	defer dbExecute(t, config.connStr("postgres"), "DROP FUNCTION IF EXISTS tf_tests_ext_member(integer)")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlExtensionDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlExtensionObjectsConfig, "tf_tests_ext_member(integer)"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlExtensionExists("postgresql_extension.myextension"),
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "objects.#", "1"),
+					testAccCheckExtensionMember("pg_trgm", "tf_tests_ext_member(integer)", true),
+				),
+			},
+			// The function is added back if it is dropped from the extension
+			// outside of Terraform.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr("postgres"), "ALTER EXTENSION pg_trgm DROP FUNCTION tf_tests_ext_member(integer)")
+				},
+				Config: fmt.Sprintf(testAccPostgresqlExtensionObjectsConfig, "tf_tests_ext_member(integer)"),
+				Check:  testAccCheckExtensionMember("pg_trgm", "tf_tests_ext_member(integer)", true),
+			},
+			// Removing the object from the configuration drops it from the
+			// extension, but not from the database.
+			{
+				Config: testAccPostgresqlExtensionConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_extension.myextension", "objects.#", "0"),
+					testAccCheckExtensionMember("pg_trgm", "tf_tests_ext_member(integer)", false),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlExtensionObjectsConfig, "tf_tests_ext_missing(integer)"),
+				ExpectError: regexp.MustCompile("could not add function tf_tests_ext_missing\\(integer\\) to extension pg_trgm: it does not exist"),
+			},
+		},
+	})
+}
+
+// testAccCheckExtensionMember checks whether the function is a member of the
+// extension.
+func testAccCheckExtensionMember(extName, function string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		_, member, err := extensionObjectMember(client.DB(), extName, "function", function)
+		if err != nil {
+			return err
+		}
+		if member != expected {
+			return fmt.Errorf("expected function %s to be a member of extension %s: %t, got %t", function, extName, expected, member)
+		}
+		return nil
+	}
+}
+
+// testCheckExtensionAvailable skips the test if the extension is not
+// available on the test server.
+func testCheckExtensionAvailable(t *testing.T, extensionName string) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	config := getTestConfig(t)
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var available bool
+	err = client.DB().QueryRow("SELECT TRUE FROM pg_catalog.pg_available_extensions WHERE name = $1", extensionName).Scan(&available)
+	switch {
+	case err == sql.ErrNoRows:
+		t.Skipf("Skip test: extension %s is not available", extensionName)
+	case err != nil:
+		t.Fatalf("could not check if extension %s is available: %v", extensionName, err)
+	}
+}
+
 func checkExtensionExists(client *Client, extensionName string) (bool, error) {
 	var _rez bool
 	err := client.DB().QueryRow("SELECT TRUE from pg_catalog.pg_extension d WHERE extname=$1", extensionName).Scan(&_rez)
@@ -158,3 +577,90 @@ resource "postgresql_extension" "ext1trgm" {
   schema = "${postgresql_schema.ext1foo.name}"
 }
 `
+
+var testAccPostgresqlExtensionConfigCascade = `
+resource "postgresql_extension" "cascade" {
+  name           = "earthdistance"
+  create_cascade = true
+}
+`
+
+var testAccPostgresqlExtensionConfigInvalidVersion = `
+resource "postgresql_extension" "myextension" {
+  name    = "pg_trgm"
+  version = "99.99"
+}
+`
+
+var testAccPostgresqlExtensionConfigHyphen = `
+resource "postgresql_extension" "hyphen" {
+  name = "uuid-ossp"
+}
+`
+
+var testAccPostgresqlExtensionNotRelocatable1 = `
+resource "postgresql_extension" "adminpack" {
+  name = "adminpack"
+}
+`
+
+var testAccPostgresqlExtensionNotRelocatable2 = `
+resource "postgresql_extension" "adminpack" {
+  name   = "adminpack"
+  schema = "public"
+}
+`
+
+var testAccPostgresqlExtensionIfNotExistsConfig = `
+resource "postgresql_extension" "myextension" {
+  name          = "pg_trgm"
+  if_not_exists = true
+}
+`
+
+var testAccPostgresqlExtensionDependencyConfig = `
+resource "postgresql_extension" "cube" {
+  name         = "cube"
+  drop_cascade = %t
+}
+
+resource "postgresql_extension" "earthdistance" {
+  name       = "earthdistance"
+  depends_on = ["postgresql_extension.cube"]
+}
+`
+
+var testAccPostgresqlExtensionDependentConfig = `
+resource "postgresql_extension" "earthdistance" {
+  name = "earthdistance"
+}
+`
+
+var testAccPostgresqlExtensionObjectsConfig = `
+resource "postgresql_extension" "myextension" {
+  name = "pg_trgm"
+
+  objects {
+    type = "function"
+    name = "%s"
+  }
+}
+`
+
+var testAccPostgresqlExtensionCreateSchemaConfig = `
+resource "postgresql_extension" "myextension" {
+  name          = "pg_trgm"
+  schema        = "tf_tests_ext_schema"
+  create_schema = %t
+}
+`
+
+var testAccPostgresqlExtensionSessionSettingsConfig = `
+resource "postgresql_extension" "myextension" {
+  name = "pg_trgm"
+
+  session_settings = {
+    %s = "%s"
+  }
+}
+`
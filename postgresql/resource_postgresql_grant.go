@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
@@ -13,16 +15,41 @@ import (
 	"github.com/lib/pq"
 )
 
+const (
+	grantTargetAttr           = "target"
+	grantTargetObjectTypeAttr = "object_type"
+	grantTargetObjectsAttr    = "objects"
+	grantTargetPrivilegesAttr = "privileges"
+)
+
 var objectTypes = map[string]string{
 	"table":    "r",
 	"sequence": "S",
+	"schema":   "n",
+	"type":     "T",
+}
+
+// databaseObject describes an object type which does not belong to a schema:
+// its catalog, and the SQL expressions of the name and of the ACL of its
+// objects in this catalog.
+type databaseObject struct {
+	catalog string
+	name    string
+	acl     string
+}
+
+// databaseObjectTypes are the object types of the targets which do not belong
+// to a schema.  The large objects are named by their OID.
+var databaseObjectTypes = map[string]databaseObject{
+	"large_object":         {catalog: "pg_largeobject_metadata", name: "oid::text", acl: "lomacl"},
+	"foreign_data_wrapper": {catalog: "pg_foreign_data_wrapper", name: "fdwname::text", acl: "fdwacl"},
+	"foreign_server":       {catalog: "pg_foreign_server", name: "srvname::text", acl: "srvacl"},
 }
 
 func resourcePostgreSQLGrant() *schema.Resource {
 	return &schema.Resource{
 		Create: resourcePostgreSQLGrantCreate,
-		// As create revokes and grants we can use it to update too
-		Update: resourcePostgreSQLGrantCreate,
+		Update: resourcePostgreSQLGrantUpdate,
 		Read:   resourcePostgreSQLGrantRead,
 		Delete: resourcePostgreSQLGrantDelete,
 
@@ -31,7 +58,7 @@ func resourcePostgreSQLGrant() *schema.Resource {
 				Type:        schema.TypeString,
 				Required:    true,
 				ForceNew:    true,
-				Description: "The name of the role to grant privileges on",
+				Description: "The name of the role to grant privileges on (or PUBLIC)",
 			},
 			"database": {
 				Type:        schema.TypeString,
@@ -41,27 +68,86 @@ func resourcePostgreSQLGrant() *schema.Resource {
 			},
 			"schema": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				ForceNew:    true,
-				Description: "The database schema to grant privileges on for this role",
+				Description: "The database schema to grant privileges on for this role, required unless the targets are large objects, foreign data wrappers or foreign servers",
 			},
 			"object_type": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
 				ForceNew: true,
 				ValidateFunc: validation.StringInSlice([]string{
 					"table",
 					"sequence",
+					"function",
 				}, false),
-				Description: "The PostgreSQL object type to grant the privileges on (one of: table, sequence)",
+				ConflictsWith: []string{grantTargetAttr},
+				Description:   "The PostgreSQL object type to grant the privileges on (one of: table, sequence, function)",
 			},
 			"privileges": &schema.Schema{
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Set:           schema.HashString,
+				MinItems:      1,
+				ConflictsWith: []string{grantTargetAttr},
+				Description:   "The list of privileges to grant",
+			},
+			"object_matcher": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{grantTargetAttr},
+				Description:   "A regular expression matching the names of the objects to grant the privileges on, instead of all the objects of the schema",
+			},
+			grantTargetAttr: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						grantTargetObjectTypeAttr: {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								"table",
+								"sequence",
+								"function",
+								"large_object",
+								"foreign_data_wrapper",
+								"foreign_server",
+							}, false),
+							Description: "The PostgreSQL object type to grant the privileges on (one of: table, sequence, function, large_object, foreign_data_wrapper, foreign_server)",
+						},
+						grantTargetObjectsAttr: {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Set:         schema.HashString,
+							Description: "The objects to grant the privileges on, instead of all the objects of this type in the schema, functions are given with the types of their arguments (e.g. my_func(integer, text))",
+						},
+						grantTargetPrivilegesAttr: {
+							Type:        schema.TypeSet,
+							Required:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Set:         schema.HashString,
+							MinItems:    1,
+							Description: "The list of privileges to grant on these objects",
+						},
+					},
+				},
+				Description: "The object types and objects to grant privileges on, instead of object_type and privileges",
+			},
+			"objects": {
 				Type:        schema.TypeSet,
-				Required:    true,
+				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Set:         schema.HashString,
-				MinItems:    1,
-				Description: "The list of privileges to grant",
+				Description: "The objects matched by object_matcher the privileges have been granted on, with the types of their arguments for functions",
+			},
+			"granted_by": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The role the privileges are granted by (PostgreSQL 14+)",
 			},
 		},
 	}
@@ -85,107 +171,254 @@ func resourcePostgreSQLGrantRead(d *schema.ResourceData, meta interface{}) error
 	}
 	defer txn.Rollback()
 
-	return readRolePrivileges(txn, d)
+	return readRolePrivileges(client, txn, d)
 }
 
 func resourcePostgreSQLGrantCreate(d *schema.ResourceData, meta interface{}) error {
-	if err := validatePrivileges(d.Get("object_type").(string), d.Get("privileges").(*schema.Set).List()); err != nil {
+	if err := validateGrant(meta.(*Client), d); err != nil {
 		return err
 	}
 
 	client := meta.(*Client)
 	database := d.Get("database").(string)
 
+	if d.Get("granted_by").(string) != "" && !client.featureSupported(featureGrantedBy) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support GRANT ... GRANTED BY", client.version.String())
+	}
+
+	err := withTxnRetry(func() error {
+		txn, err := startTransaction(client, database)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		// Revoke all privileges before granting otherwise reducing privileges will not work.
+		// We just have to revoke them in the same transaction so the role will not lost its
+		// privileges between the revoke and grant statements.
+		if err = revokeRolePrivileges(client, txn, d); err != nil {
+			return err
+		}
+
+		if err = grantRolePrivileges(client, txn, d); err != nil {
+			return err
+		}
+
+		if err = txn.Commit(); err != nil {
+			return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(generateGrantID(d))
+
 	txn, err := startTransaction(client, database)
 	if err != nil {
 		return err
 	}
 	defer txn.Rollback()
 
-	// Revoke all privileges before granting otherwise reducing privileges will not work.
-	// We just have to revoke them in the same transaction so the role will not lost its
-	// privileges between the revoke and grant statements.
-	if err = revokeRolePrivileges(txn, d); err != nil {
-		return err
+	return readRolePrivileges(client, txn, d)
+}
+
+func resourcePostgreSQLGrantUpdate(d *schema.ResourceData, meta interface{}) error {
+	// Only a change of the privileges of an object_type is applied as a
+	// delta, otherwise all the privileges are revoked and granted again, as
+	// when creating the grant.
+	oldTargets, newTargets := d.GetChange(grantTargetAttr)
+	if len(oldTargets.([]interface{})) > 0 || len(newTargets.([]interface{})) > 0 ||
+		d.Get("object_matcher").(string) != "" || d.HasChange("granted_by") {
+		return resourcePostgreSQLGrantCreate(d, meta)
 	}
 
-	if err = grantRolePrivileges(txn, d); err != nil {
+	if err := validateGrant(meta.(*Client), d); err != nil {
 		return err
 	}
 
-	if err = txn.Commit(); err != nil {
-		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
-	}
+	client := meta.(*Client)
+	database := d.Get("database").(string)
 
-	d.SetId(generateGrantID(d))
+	err := withTxnRetry(func() error {
+		txn, err := startTransaction(client, database)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
 
-	txn, err = startTransaction(client, database)
+		if err = updateRolePrivileges(client, txn, d); err != nil {
+			return err
+		}
+
+		if err = txn.Commit(); err != nil {
+			return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	defer txn.Rollback()
 
-	return readRolePrivileges(txn, d)
-}
-
-func resourcePostgreSQLGrantDelete(d *schema.ResourceData, meta interface{}) error {
-	txn, err := startTransaction(meta.(*Client), d.Get("database").(string))
+	txn, err := startTransaction(client, database)
 	if err != nil {
 		return err
 	}
 	defer txn.Rollback()
 
-	if err = revokeRolePrivileges(txn, d); err != nil {
-		return err
-	}
+	return readRolePrivileges(client, txn, d)
+}
 
-	if err = txn.Commit(); err != nil {
-		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
-	}
+func resourcePostgreSQLGrantDelete(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*Client)
+	return withTxnRetry(func() error {
+		txn, err := startTransaction(client, d.Get("database").(string))
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
 
-	return nil
+		if err = revokeRolePrivileges(client, txn, d); err != nil {
+			return err
+		}
+
+		if err = txn.Commit(); err != nil {
+			return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+		}
+
+		return nil
+	})
 }
 
-func readRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
-	// This returns, for the specified role (rolname),
-	// the list of all object of the specified type (relkind) in the specified schema (namespace)
-	// with the list of the currently applied privileges (aggregation of privilege_type)
-	//
-	// Our goal is to check that every object has the same privileges as saved in the state.
-	query := `
-SELECT pg_class.relname, array_remove(array_agg(privilege_type), NULL)
+// rolePrivilegesQuery returns, for the specified role (rolname),
+// the list of all object of the specified type (relkind) in the specified schema (namespace)
+// with the list of the currently applied privileges (aggregation of privilege_type)
+// and of their grantors.  The objects are filtered further by the $4 parameter.
+const rolePrivilegesQuery = `
+SELECT pg_class.relname, array_remove(array_agg(privilege_type), NULL),
+    array_remove(array_agg(DISTINCT pg_get_userbyid(grantor)::text), NULL)
 FROM pg_class
 JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
 LEFT JOIN (
     SELECT acls.* FROM (
         SELECT relname, relnamespace, relkind, (aclexplode(relacl)).* FROM pg_class c
     ) as acls
-    JOIN pg_roles on grantee = pg_roles.oid
-    WHERE rolname=$1
+    WHERE CASE WHEN grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(grantee) END = $1
 ) privs
 USING (relname, relnamespace, relkind)
-WHERE nspname = $2 AND relkind = $3
+WHERE nspname = $2 AND relkind = $3 AND %s
 GROUP BY pg_class.relname;
 `
 
+// roleFunctionPrivilegesQuery is rolePrivilegesQuery for the functions, which
+// are named by functionSignature.  The functions without ACL have the default
+// privileges: EXECUTE is granted to PUBLIC.  The functions are filtered further
+// by the $3 parameter.
+const roleFunctionPrivilegesQuery = `
+SELECT ` + functionSignature + `, array_remove(array_agg(privs.privilege_type), NULL),
+    array_remove(array_agg(DISTINCT pg_get_userbyid(privs.grantor)::text), NULL)
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+LEFT JOIN (
+    SELECT acls.* FROM (
+        SELECT oid, (aclexplode(COALESCE(proacl, acldefault('f', proowner)))).* FROM pg_proc
+    ) as acls
+    WHERE CASE WHEN grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(grantee) END = $1
+) privs
+ON privs.oid = pg_proc.oid
+WHERE nspname = $2 AND %s
+GROUP BY pg_proc.oid, 1;
+`
+
+// roleDatabaseObjectPrivilegesQuery is rolePrivilegesQuery for the objects
+// which do not belong to a schema, formatted with the name and ACL
+// expressions and the catalog of their databaseObject.  The objects are
+// filtered by the $2 parameter.
+const roleDatabaseObjectPrivilegesQuery = `
+SELECT o.%[1]s, array_remove(array_agg(privs.privilege_type), NULL),
+    array_remove(array_agg(DISTINCT pg_get_userbyid(privs.grantor)::text), NULL)
+FROM pg_catalog.%[3]s o
+LEFT JOIN (
+    SELECT acls.* FROM (
+        SELECT oid, (aclexplode(%[2]s)).* FROM pg_catalog.%[3]s
+    ) as acls
+    WHERE CASE WHEN grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(grantee) END = $1
+) privs
+ON privs.oid = o.oid
+WHERE o.%[1]s = ANY($2)
+GROUP BY o.oid, 1;
+`
+
+// queryRolePrivileges runs rolePrivilegesQuery, or roleFunctionPrivilegesQuery
+// for the functions, on the objects of the grant's object type.  The objects
+// are filtered by the optional object matcher (relname, or proname for the
+// functions).
+func queryRolePrivileges(txn *sql.Tx, d *schema.ResourceData) (*sql.Rows, error) {
+	query := fmt.Sprintf(rolePrivilegesQuery, "($4 = '' OR pg_class.relname ~ $4)")
+
 	objectType := d.Get("object_type").(string)
-	rows, err := txn.Query(
-		query, d.Get("role"), d.Get("schema"), objectTypes[objectType],
-	)
+	objectMatcher := d.Get("object_matcher").(string)
+	// In an aclitem, the PUBLIC pseudo-role is represented by the 0 grantee.
+	role := d.Get("role").(string)
+	if isPublicRole(role) {
+		role = publicRole
+	}
+
+	args := []interface{}{role, d.Get("schema"), objectTypes[objectType], objectMatcher}
+	if objectType == "function" {
+		query = fmt.Sprintf(roleFunctionPrivilegesQuery, "($3 = '' OR pg_proc.proname ~ $3)")
+		args = []interface{}{role, d.Get("schema"), objectMatcher}
+	}
+
+	return txn.Query(query, args...)
+}
+
+func readRolePrivileges(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	if len(grantTargets(d.Get(grantTargetAttr).([]interface{}))) > 0 {
+		return readTargetsPrivileges(c, txn, d)
+	}
+
+	// Our goal is to check that every object has the same privileges as saved in the state.
+	objectType := d.Get("object_type").(string)
+	objectMatcher := d.Get("object_matcher").(string)
+
+	rows, err := queryRolePrivileges(txn, d)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
+	grantedBy := d.Get("granted_by").(string)
+
+	objects := []interface{}{}
 	for rows.Next() {
 		var objName string
-		var privileges pq.ByteaArray
+		var privileges, grantors pq.ByteaArray
 
-		if err := rows.Scan(&objName, &privileges); err != nil {
+		if err := rows.Scan(&objName, &privileges, &grantors); err != nil {
 			return err
 		}
+		objects = append(objects, objName)
+
+		// The grantor is only checked when managed, it is otherwise the owner
+		// of the object.
+		if grantedBy != "" {
+			for _, grantor := range grantors {
+				if string(grantor) != grantedBy {
+					log.Printf(
+						"[DEBUG] %s %s has privileges granted by %s instead of %s for role %s",
+						strings.ToTitle(objectType), objName, grantor, grantedBy, d.Get("role"),
+					)
+					d.Set("granted_by", string(grantor))
+					break
+				}
+			}
+		}
+
 		privilegesSet := pgArrayToSet(privileges)
 
-		if !privilegesSet.Equal(d.Get("privileges").(*schema.Set)) {
+		if !privilegesEqual(c, objectType, privilegesSet, d.Get("privileges").(*schema.Set)) {
 			// If any object doesn't have the same privileges as saved in the state,
 			// we return an empty privileges to force an update.
 			log.Printf(
@@ -198,39 +431,658 @@ GROUP BY pg_class.relname;
 
 	}
 
+	if objectMatcher == "" {
+		return nil
+	}
+
+	// Objects matching the object matcher may have been created or removed
+	// since the last apply, in which case the privileges have to be applied again.
+	objectsSet := schema.NewSet(schema.HashString, objects)
+	if !objectsSet.Equal(d.Get("objects").(*schema.Set)) {
+		log.Printf(
+			"[DEBUG] objects matching %s in schema %s have changed for role %s",
+			objectMatcher, d.Get("schema"), d.Get("role"),
+		)
+		d.Set("privileges", schema.NewSet(schema.HashString, []interface{}{}))
+	}
+
 	return nil
 }
 
-func grantRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func grantRolePrivileges(client *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	if targets := grantTargets(d.Get(grantTargetAttr).([]interface{})); len(targets) > 0 {
+		for _, target := range targets {
+			if err := grantTargetPrivileges(client, txn, d, target); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	privileges := []string{}
 	for _, priv := range d.Get("privileges").(*schema.Set).List() {
 		privileges = append(privileges, priv.(string))
 	}
 
+	objectType := d.Get("object_type").(string)
+	objectMatcher := d.Get("object_matcher").(string)
+	if objectMatcher == "" {
+		query := fmt.Sprintf(
+			"GRANT %s ON ALL %sS IN SCHEMA %s TO %s",
+			strings.Join(privileges, ","),
+			grantObjectKeyword(client, objectType),
+			pq.QuoteIdentifier(d.Get("schema").(string)),
+			quoteRoleName(d.Get("role").(string)),
+		) + grantedByClause(d.Get("granted_by").(string))
+
+		_, err := txn.Exec(query)
+		return err
+	}
+
+	objects, err := listMatchingObjects(txn, d, nil)
+	if err != nil {
+		return err
+	}
+	d.Set("objects", schema.NewSet(schema.HashString, stringsToInterfaces(objects)))
+
+	if len(objects) == 0 {
+		log.Printf("[DEBUG] no object matching %s in schema %s", objectMatcher, d.Get("schema"))
+		return nil
+	}
+
 	query := fmt.Sprintf(
-		"GRANT %s ON ALL %sS IN SCHEMA %s TO %s",
+		"GRANT %s ON %s %s TO %s",
 		strings.Join(privileges, ","),
-		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(d.Get("schema").(string)),
-		pq.QuoteIdentifier(d.Get("role").(string)),
-	)
+		grantObjectKeyword(client, objectType),
+		quoteTargetObjects(d.Get("schema").(string), objectType, objects),
+		quoteRoleName(d.Get("role").(string)),
+	) + grantedByClause(d.Get("granted_by").(string))
+
+	_, err = txn.Exec(query)
+	return err
+}
+
+// updateRolePrivileges only revokes the privileges the role has on the objects
+// which are not configured anymore, then grants the configured ones, so that
+// the privileges which are kept are never revoked.  The current privileges are
+// read from the ACLs of the objects, the ones of the state may have drifted.
+func updateRolePrivileges(client *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	rows, err := queryRolePrivileges(txn, d)
+	if err != nil {
+		return err
+	}
+
+	privileges := d.Get("privileges").(*schema.Set)
+	revoked := []string{}
+	for rows.Next() {
+		var objName string
+		var current, grantors pq.ByteaArray
+
+		if err := rows.Scan(&objName, &current, &grantors); err != nil {
+			rows.Close()
+			return err
+		}
+		for _, priv := range current {
+			if !privileges.Contains("ALL") && !privileges.Contains(string(priv)) && !sliceContainsStr(revoked, string(priv)) {
+				revoked = append(revoked, string(priv))
+			}
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	if len(revoked) > 0 {
+		sort.Strings(revoked)
+		query := fmt.Sprintf(
+			"REVOKE %s ON ALL %sS IN SCHEMA %s FROM %s",
+			strings.Join(revoked, ","),
+			grantObjectKeyword(client, d.Get("object_type").(string)),
+			pq.QuoteIdentifier(d.Get("schema").(string)),
+			quoteRoleName(d.Get("role").(string)),
+		) + grantedByClause(d.Get("granted_by").(string))
+
+		if _, err := txn.Exec(query); err != nil {
+			return err
+		}
+	}
+
+	return grantRolePrivileges(client, txn, d)
+}
+
+func revokeRolePrivileges(client *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	// The privileges have to be revoked by the role which granted them.
+	oldGrantedBy, _ := d.GetChange("granted_by")
+	grantedBy := grantedByClause(oldGrantedBy.(string))
+
+	// The privileges are revoked from the objects of the previous targets
+	// too, the new ones may not include them anymore.
+	oldTargets, newTargets := d.GetChange(grantTargetAttr)
+	targets := append(grantTargets(oldTargets.([]interface{})), grantTargets(newTargets.([]interface{}))...)
+	if len(targets) > 0 {
+		for _, target := range targets {
+			if err := revokeTargetPrivileges(client, txn, d, target, grantedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	objectType := d.Get("object_type").(string)
+	if d.Get("object_matcher").(string) == "" {
+		query := fmt.Sprintf(
+			"REVOKE ALL PRIVILEGES ON ALL %sS IN SCHEMA %s FROM %s",
+			grantObjectKeyword(client, objectType),
+			pq.QuoteIdentifier(d.Get("schema").(string)),
+			quoteRoleName(d.Get("role").(string)),
+		) + grantedBy
+
+		_, err := txn.Exec(query)
+		return err
+	}
+
+	// Also revoke on the previously granted objects which may not match anymore
+	// (e.g. renamed), as long as they still exist.
+	previousObjects := []string{}
+	for _, obj := range d.Get("objects").(*schema.Set).List() {
+		previousObjects = append(previousObjects, obj.(string))
+	}
+
+	objects, err := listMatchingObjects(txn, d, previousObjects)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		"REVOKE ALL PRIVILEGES ON %s %s FROM %s",
+		grantObjectKeyword(client, objectType),
+		quoteTargetObjects(d.Get("schema").(string), objectType, objects),
+		quoteRoleName(d.Get("role").(string)),
+	) + grantedBy
+
+	_, err = txn.Exec(query)
+	return err
+}
+
+// grantTarget is an object type and the objects of this type a grant applies
+// to, all the objects of the schema if objects is empty.
+type grantTarget struct {
+	objectType string
+	objects    []string
+	privileges []string
+}
+
+func grantTargets(raw []interface{}) []grantTarget {
+	targets := make([]grantTarget, 0, len(raw))
+	for _, r := range raw {
+		m := r.(map[string]interface{})
+		target := grantTarget{objectType: m[grantTargetObjectTypeAttr].(string)}
+		for _, obj := range m[grantTargetObjectsAttr].(*schema.Set).List() {
+			target.objects = append(target.objects, obj.(string))
+		}
+		for _, priv := range m[grantTargetPrivilegesAttr].(*schema.Set).List() {
+			target.privileges = append(target.privileges, priv.(string))
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// validateGrant checks that the grant has either an object type and
+// privileges or targets, and that the privileges are allowed for their object
+// type.  The schema is required, except for the targets whose objects do not
+// belong to a schema, which have to list their objects.
+func validateGrant(c *Client, d *schema.ResourceData) error {
+	pgSchema := d.Get("schema").(string)
+
+	targets := grantTargets(d.Get(grantTargetAttr).([]interface{}))
+	if len(targets) == 0 {
+		objectType := d.Get("object_type").(string)
+		privileges := d.Get("privileges").(*schema.Set).List()
+		if objectType == "" || len(privileges) == 0 {
+			return fmt.Errorf("either object_type and privileges or %s blocks have to be set", grantTargetAttr)
+		}
+		if pgSchema == "" {
+			return fmt.Errorf("schema has to be set to grant privileges on object_type %s", objectType)
+		}
+		return validatePrivileges(c, objectType, privileges)
+	}
+
+	for _, target := range targets {
+		if err := validatePrivileges(c, target.objectType, stringsToInterfaces(target.privileges)); err != nil {
+			return err
+		}
+
+		if _, ok := databaseObjectTypes[target.objectType]; !ok {
+			if pgSchema == "" {
+				return fmt.Errorf("schema has to be set to grant privileges on object_type %s", target.objectType)
+			}
+			continue
+		}
+
+		if pgSchema != "" {
+			return fmt.Errorf("schema cannot be set to grant privileges on object_type %s, which does not belong to a schema", target.objectType)
+		}
+		if len(target.objects) == 0 {
+			return fmt.Errorf("objects have to be listed to grant privileges on object_type %s", target.objectType)
+		}
+		if target.objectType == "large_object" {
+			for _, obj := range target.objects {
+				if _, err := strconv.ParseUint(obj, 10, 32); err != nil {
+					return fmt.Errorf("large object %q has to be given by its OID", obj)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func grantTargetPrivileges(client *Client, txn *sql.Tx, d *schema.ResourceData, target grantTarget) error {
+	var on string
+	if _, ok := databaseObjectTypes[target.objectType]; ok {
+		on = fmt.Sprintf(
+			"%s %s",
+			grantObjectKeyword(client, target.objectType), quoteTargetObjects("", target.objectType, target.objects),
+		)
+	} else if len(target.objects) == 0 {
+		on = fmt.Sprintf(
+			"ALL %sS IN SCHEMA %s",
+			grantObjectKeyword(client, target.objectType), pq.QuoteIdentifier(d.Get("schema").(string)),
+		)
+	} else {
+		objects, err := targetObjects(txn, target)
+		if err != nil {
+			return err
+		}
+		on = fmt.Sprintf(
+			"%s %s",
+			grantObjectKeyword(client, target.objectType), quoteTargetObjects(d.Get("schema").(string), target.objectType, objects),
+		)
+	}
+
+	query := fmt.Sprintf(
+		"GRANT %s ON %s TO %s",
+		strings.Join(target.privileges, ","), on, quoteRoleName(d.Get("role").(string)),
+	) + grantedByClause(d.Get("granted_by").(string))
 
 	_, err := txn.Exec(query)
 	return err
 }
 
-func revokeRolePrivileges(txn *sql.Tx, d *schema.ResourceData) error {
+func revokeTargetPrivileges(client *Client, txn *sql.Tx, d *schema.ResourceData, target grantTarget, grantedBy string) error {
+	var on string
+	_, isDatabaseObject := databaseObjectTypes[target.objectType]
+	if len(target.objects) == 0 && !isDatabaseObject {
+		on = fmt.Sprintf(
+			"ALL %sS IN SCHEMA %s",
+			grantObjectKeyword(client, target.objectType), pq.QuoteIdentifier(d.Get("schema").(string)),
+		)
+	} else {
+		// The objects which do not exist anymore are skipped.
+		objects, err := listTargetObjects(txn, d, target)
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		on = fmt.Sprintf(
+			"%s %s",
+			grantObjectKeyword(client, target.objectType), quoteTargetObjects(d.Get("schema").(string), target.objectType, objects),
+		)
+	}
+
 	query := fmt.Sprintf(
-		"REVOKE ALL PRIVILEGES ON ALL %sS IN SCHEMA %s FROM %s",
-		strings.ToUpper(d.Get("object_type").(string)),
-		pq.QuoteIdentifier(d.Get("schema").(string)),
-		pq.QuoteIdentifier(d.Get("role").(string)),
-	)
+		"REVOKE ALL PRIVILEGES ON %s FROM %s",
+		on, quoteRoleName(d.Get("role").(string)),
+	) + grantedBy
 
 	_, err := txn.Exec(query)
 	return err
 }
 
+// listTargetObjects returns the names of the objects of the target which exist
+// in the grant's schema.
+func listTargetObjects(txn *sql.Tx, d *schema.ResourceData, target grantTarget) ([]string, error) {
+	names, err := targetObjects(txn, target)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT pg_class.relname
+FROM pg_class
+JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
+WHERE nspname = $1 AND relkind = $2 AND relname = ANY($3)
+ORDER BY pg_class.relname
+`
+	args := []interface{}{d.Get("schema"), objectTypes[target.objectType], pq.Array(names)}
+	if target.objectType == "function" {
+		query = `
+SELECT ` + functionSignature + `
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+WHERE nspname = $1 AND ` + functionSignature + ` = ANY($2)
+ORDER BY 1
+`
+		args = []interface{}{d.Get("schema"), pq.Array(names)}
+	}
+	if obj, ok := databaseObjectTypes[target.objectType]; ok {
+		query = fmt.Sprintf(
+			"SELECT %[1]s FROM pg_catalog.%[2]s WHERE %[1]s = ANY($1) ORDER BY 1",
+			obj.name, obj.catalog,
+		)
+		args = []interface{}{pq.Array(names)}
+	}
+
+	rows, err := txn.Query(query, args...)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not list objects: {{err}}", err)
+	}
+	defer rows.Close()
+
+	objects := []string{}
+	for rows.Next() {
+		var objName string
+		if err := rows.Scan(&objName); err != nil {
+			return nil, errwrap.Wrapf("could not scan object name: {{err}}", err)
+		}
+		objects = append(objects, objName)
+	}
+
+	return objects, rows.Err()
+}
+
+// functionSignature is the SQL expression of the signature of a function of
+// pg_proc as used in the objects of a function target: its name followed by
+// the types of its arguments, e.g. my_func(integer, text).
+const functionSignature = `pg_proc.proname || '(' || pg_catalog.oidvectortypes(pg_proc.proargtypes) || ')'`
+
+// targetObjects returns the objects of the target, the signatures of the
+// functions being normalized as functionSignature so they identify a single
+// overload of the function whatever the spelling of the types of its
+// arguments (e.g. int and integer).
+func targetObjects(txn *sql.Tx, target grantTarget) ([]string, error) {
+	if target.objectType != "function" {
+		return target.objects, nil
+	}
+
+	objects := make([]string, 0, len(target.objects))
+	for _, obj := range target.objects {
+		name, args, err := parseFunctionSignature(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		types := make([]string, 0, len(args))
+		for _, arg := range args {
+			var argType string
+			if err := txn.QueryRow("SELECT $1::regtype::text", arg).Scan(&argType); err != nil {
+				return nil, errwrap.Wrapf(fmt.Sprintf("could not resolve the type %s of the arguments of function %s: {{err}}", arg, obj), err)
+			}
+			types = append(types, argType)
+		}
+
+		signature := fmt.Sprintf("%s(%s)", name, strings.Join(types, ", "))
+		if !sliceContainsStr(objects, signature) {
+			objects = append(objects, signature)
+		}
+	}
+	return objects, nil
+}
+
+// parseFunctionSignature returns the name and the argument types of a
+// function signature, e.g. my_func(int, numeric(10,2)).  The name is folded to
+// lower case unless it is quoted, as PostgreSQL does.
+func parseFunctionSignature(signature string) (string, []string, error) {
+	signature = strings.TrimSpace(signature)
+	start := strings.IndexByte(signature, '(')
+	if start == -1 || !strings.HasSuffix(signature, ")") {
+		return "", nil, fmt.Errorf("function %q must be given with the types of its arguments, e.g. %s()", signature, signature)
+	}
+
+	name := strings.TrimSpace(signature[:start])
+	if len(name) > 1 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		name = strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+	} else {
+		name = strings.ToLower(name)
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("function %q has no name", signature)
+	}
+
+	// The arguments are split on the commas which are not part of a type
+	// modifier, e.g. numeric(10,2).
+	var args []string
+	depth, argStart := 0, start+1
+	for i := start + 1; i < len(signature)-1; i++ {
+		switch signature[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(signature[argStart:i]))
+				argStart = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(signature[argStart : len(signature)-1]); last != "" || len(args) > 0 {
+		args = append(args, last)
+	}
+	for _, arg := range args {
+		if arg == "" {
+			return "", nil, fmt.Errorf("function %q has an empty argument type", signature)
+		}
+	}
+
+	return name, args, nil
+}
+
+// quoteTargetObjects quotes the objects of a target for a GRANT or REVOKE
+// statement, the objects of a function target being normalized signatures.
+// The objects which do not belong to a schema are not qualified, and the
+// large objects are OIDs.
+func quoteTargetObjects(pgSchema, objectType string, objects []string) string {
+	switch objectType {
+	case "large_object":
+		return strings.Join(objects, ",")
+	case "foreign_data_wrapper", "foreign_server":
+		quoted := make([]string, 0, len(objects))
+		for _, obj := range objects {
+			quoted = append(quoted, pq.QuoteIdentifier(obj))
+		}
+		return strings.Join(quoted, ",")
+	case "function":
+		return quoteFunctionSignatures(pgSchema, objects)
+	default:
+		return quoteSchemaObjects(pgSchema, objects)
+	}
+}
+
+// quoteFunctionSignatures quotes normalized function signatures, qualified
+// by the schema.
+func quoteFunctionSignatures(pgSchema string, objects []string) string {
+	quoted := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		// The types of the arguments do not contain parentheses once
+		// normalized, unlike the name of the function.
+		i := strings.LastIndex(obj, "(")
+		quoted = append(quoted, pq.QuoteIdentifier(pgSchema)+"."+pq.QuoteIdentifier(obj[:i])+obj[i:])
+	}
+	return strings.Join(quoted, ",")
+}
+
+// readTargetsPrivileges checks that the objects of every target have the
+// privileges of the target.  The privileges of a target whose objects do not
+// are emptied in the state to force an update.
+func readTargetsPrivileges(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	// The objects are filtered by the optional list of objects of the target.
+	query := fmt.Sprintf(rolePrivilegesQuery, "(array_length($4::text[], 1) IS NULL OR pg_class.relname = ANY($4))")
+
+	functionsQuery := fmt.Sprintf(roleFunctionPrivilegesQuery, "(array_length($3::text[], 1) IS NULL OR "+functionSignature+" = ANY($3))")
+
+	// In an aclitem, the PUBLIC pseudo-role is represented by the 0 grantee.
+	role := d.Get("role").(string)
+	if isPublicRole(role) {
+		role = publicRole
+	}
+	grantedBy := d.Get("granted_by").(string)
+
+	rawTargets := d.Get(grantTargetAttr).([]interface{})
+	for i, target := range grantTargets(rawTargets) {
+		objects, err := targetObjects(txn, target)
+		if err != nil {
+			return err
+		}
+
+		var rows *sql.Rows
+		if obj, ok := databaseObjectTypes[target.objectType]; ok {
+			rows, err = txn.Query(fmt.Sprintf(roleDatabaseObjectPrivilegesQuery, obj.name, obj.acl, obj.catalog), role, pq.Array(objects))
+		} else if target.objectType == "function" {
+			rows, err = txn.Query(functionsQuery, role, d.Get("schema"), pq.Array(objects))
+		} else {
+			rows, err = txn.Query(query, role, d.Get("schema"), objectTypes[target.objectType], pq.Array(objects))
+		}
+		if err != nil {
+			return err
+		}
+
+		expected := schema.NewSet(schema.HashString, stringsToInterfaces(target.privileges))
+		found, drifted := 0, false
+		for rows.Next() {
+			var objName string
+			var privileges, grantors pq.ByteaArray
+
+			if err := rows.Scan(&objName, &privileges, &grantors); err != nil {
+				rows.Close()
+				return err
+			}
+			found++
+
+			if grantedBy != "" {
+				for _, grantor := range grantors {
+					if string(grantor) != grantedBy {
+						log.Printf(
+							"[DEBUG] %s %s has privileges granted by %s instead of %s for role %s",
+							strings.ToTitle(target.objectType), objName, grantor, grantedBy, d.Get("role"),
+						)
+						d.Set("granted_by", string(grantor))
+						break
+					}
+				}
+			}
+
+			if !privilegesEqual(c, target.objectType, pgArrayToSet(privileges), expected) {
+				log.Printf(
+					"[DEBUG] %s %s has not the expected privileges %v for role %s",
+					strings.ToTitle(target.objectType), objName, privileges, d.Get("role"),
+				)
+				drifted = true
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		// Listed objects may have been dropped since the last apply.
+		if len(objects) > 0 && found != len(objects) {
+			log.Printf(
+				"[DEBUG] some %ss of %v do not exist in schema %s",
+				target.objectType, target.objects, d.Get("schema"),
+			)
+			drifted = true
+		}
+
+		if drifted {
+			rawTargets[i].(map[string]interface{})[grantTargetPrivilegesAttr] = schema.NewSet(schema.HashString, []interface{}{})
+		}
+	}
+
+	d.Set(grantTargetAttr, rawTargets)
+
+	return nil
+}
+
+// grantedByClause returns the GRANTED BY clause of the GRANT/REVOKE statements,
+// empty if grantedBy is not set.
+func grantedByClause(grantedBy string) string {
+	if grantedBy == "" {
+		return ""
+	}
+	return " GRANTED BY " + pq.QuoteIdentifier(grantedBy)
+}
+
+// grantObjectKeyword returns the keyword of the object type in the GRANT and
+// REVOKE statements.  The functions are granted as routines when supported, so
+// that ALL ROUTINES IN SCHEMA includes the procedures too.
+func grantObjectKeyword(client *Client, objectType string) string {
+	if objectType == "function" && client.featureSupported(featureRoutines) {
+		return "ROUTINE"
+	}
+	return strings.ToUpper(strings.Replace(objectType, "_", " ", -1))
+}
+
+// listMatchingObjects returns the names of the objects of the grant's schema and
+// object type which match the grant's object matcher or are part of extraObjects.
+// The functions are matched by name and returned as functionSignature.
+func listMatchingObjects(txn *sql.Tx, d *schema.ResourceData, extraObjects []string) ([]string, error) {
+	query := `
+SELECT pg_class.relname
+FROM pg_class
+JOIN pg_namespace ON pg_namespace.oid = pg_class.relnamespace
+WHERE nspname = $1 AND relkind = $2 AND (relname ~ $3 OR relname = ANY($4))
+ORDER BY pg_class.relname
+`
+	args := []interface{}{
+		d.Get("schema"), objectTypes[d.Get("object_type").(string)],
+		d.Get("object_matcher"), pq.Array(extraObjects),
+	}
+	if d.Get("object_type").(string) == "function" {
+		query = `
+SELECT ` + functionSignature + `
+FROM pg_proc
+JOIN pg_namespace ON pg_namespace.oid = pg_proc.pronamespace
+WHERE nspname = $1 AND (proname ~ $2 OR ` + functionSignature + ` = ANY($3))
+ORDER BY 1
+`
+		args = []interface{}{d.Get("schema"), d.Get("object_matcher"), pq.Array(extraObjects)}
+	}
+
+	rows, err := txn.Query(query, args...)
+	if err != nil {
+		return nil, errwrap.Wrapf("could not list matching objects: {{err}}", err)
+	}
+	defer rows.Close()
+
+	objects := []string{}
+	for rows.Next() {
+		var objName string
+		if err := rows.Scan(&objName); err != nil {
+			return nil, errwrap.Wrapf("could not scan object name: {{err}}", err)
+		}
+		objects = append(objects, objName)
+	}
+
+	return objects, rows.Err()
+}
+
+func quoteSchemaObjects(pgSchema string, objects []string) string {
+	quoted := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		quoted = append(quoted, pq.QuoteIdentifier(pgSchema)+"."+pq.QuoteIdentifier(obj))
+	}
+	return strings.Join(quoted, ",")
+}
+
 func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, error) {
 	txn, err := startTransaction(client, "")
 	if err != nil {
@@ -238,20 +1090,22 @@ func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, erro
 	}
 	defer txn.Rollback()
 
-	// Check the role exists
+	// Check the role exists, PUBLIC always does
 	role := d.Get("role").(string)
-	exists, err := roleExists(txn, role)
-	if err != nil {
-		return false, err
-	}
-	if !exists {
-		log.Printf("[DEBUG] role %s does not exists", role)
-		return false, nil
+	if !isPublicRole(role) {
+		exists, err := roleExists(txn, role)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			log.Printf("[DEBUG] role %s does not exists", role)
+			return false, nil
+		}
 	}
 
 	// Check the database exists
 	database := d.Get("database").(string)
-	exists, err = dbExists(txn, database)
+	exists, err := dbExists(txn, database)
 	if err != nil {
 		return false, err
 	}
@@ -267,8 +1121,12 @@ func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, erro
 	}
 	defer dbTxn.Rollback()
 
-	// Check the schema exists (the SQL connection needs to be on the right database)
+	// Check the schema exists (the SQL connection needs to be on the right database),
+	// the default privileges on schemas have none.
 	pgSchema := d.Get("schema").(string)
+	if pgSchema == "" {
+		return true, nil
+	}
 	exists, err = schemaExists(txn, pgSchema)
 	if err != nil {
 		return false, err
@@ -282,8 +1140,23 @@ func checkRoleDBSchemaExists(client *Client, d *schema.ResourceData) (bool, erro
 }
 
 func generateGrantID(d *schema.ResourceData) string {
-	return strings.Join([]string{
+	objectType := d.Get("object_type").(string)
+	if objectType == "" {
+		types := []string{}
+		for _, target := range grantTargets(d.Get(grantTargetAttr).([]interface{})) {
+			if !sliceContainsStr(types, target.objectType) {
+				types = append(types, target.objectType)
+			}
+		}
+		objectType = strings.Join(types, "-")
+	}
+
+	parts := []string{
 		d.Get("role").(string), d.Get("database").(string),
-		d.Get("schema").(string), d.Get("object_type").(string),
-	}, "_")
+		d.Get("schema").(string), objectType,
+	}
+	if objectMatcher := d.Get("object_matcher").(string); objectMatcher != "" {
+		parts = append(parts, objectMatcher)
+	}
+	return strings.Join(parts, "_")
 }
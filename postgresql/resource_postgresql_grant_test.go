@@ -1,10 +1,15 @@
 package postgresql
 
 import (
+	"database/sql"
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
 
@@ -65,3 +70,657 @@ func TestAccPostgresqlGrant(t *testing.T) {
 		},
 	})
 }
+
+func TestAccPostgresqlGrant_RevokeOnePrivilege(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	var testGrantConfig = `
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "public"
+		object_type = "table"
+		privileges  = [%s]
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrantConfig, dbName, roleName, `"SELECT", "INSERT"`),
+				Check: func(*terraform.State) error {
+					return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT", "INSERT"})
+				},
+			},
+			// Only INSERT is revoked: SELECT is kept, with the grant option
+			// added outside of Terraform, instead of being revoked and granted
+			// again.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("GRANT SELECT ON test_table TO %s WITH GRANT OPTION", roleName))
+				},
+				Config: fmt.Sprintf(testGrantConfig, dbName, roleName, `"SELECT"`),
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+					},
+					testCheckRoleTablePrivilege(t, dbName, roleName, "SELECT WITH GRANT OPTION", true),
+					testCheckRoleTablePrivilege(t, dbName, roleName, "INSERT", false),
+					resource.TestCheckResourceAttr("postgresql_grant.test", "privileges.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckRoleTablePrivilege checks whether the role has the privilege on the
+// test table.
+func testCheckRoleTablePrivilege(t *testing.T, dbName, roleName, privilege string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow("SELECT has_table_privilege($1, 'test_table', $2)", roleName, privilege).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check %s privilege of %s: %v", privilege, roleName, err)
+		}
+
+		if granted != expected {
+			return fmt.Errorf("expected %s %s privilege on test_table to be %t, got %t", roleName, privilege, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlGrant_ObjectMatcher(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	var testGrantMatcher = fmt.Sprintf(`
+	resource "postgresql_grant" "test_matcher" {
+		database       = "%s"
+		role           = "%s"
+		schema         = "public"
+		object_type    = "table"
+		object_matcher = "^test_"
+		privileges     = ["SELECT"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantMatcher,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+					},
+					resource.TestCheckResourceAttr("postgresql_grant.test_matcher", "objects.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_matcher", "privileges.#", "1"),
+				),
+			},
+			{
+				// A new table matching the regex must be detected as drift
+				// and granted on the next apply.
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "CREATE TABLE test_table_2 (val text)")
+				},
+				Config: testGrantMatcher,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_grant.test_matcher", "objects.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrant_GrantedBy(t *testing.T) {
+	testCheckCompatibleVersion(t, featureGrantedBy)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	// The connection user owns the test table so it can be the grantor.
+	var testGrantGrantedBy = fmt.Sprintf(`
+	resource "postgresql_grant" "test_granted_by" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "public"
+		object_type = "table"
+		privileges  = ["SELECT"]
+		granted_by  = "%s"
+	}
+	`, dbName, roleName, config.Username)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantGrantedBy,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+					},
+					resource.TestCheckResourceAttr("postgresql_grant.test_granted_by", "granted_by", config.Username),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrant_Public(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testGrantPublic = `
+	resource "postgresql_grant" "test_public" {
+		database    = "%s"
+		role        = "PUBLIC"
+		schema      = "public"
+		object_type = "table"
+		privileges  = [%s]
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrantPublic, dbName, `"SELECT", "INSERT"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckPublicTablePrivileges(t, dbName, "SELECT", true),
+					testCheckPublicTablePrivileges(t, dbName, "INSERT", true),
+					resource.TestCheckResourceAttr("postgresql_grant.test_public", "privileges.#", "2"),
+				),
+			},
+			// INSERT is revoked from PUBLIC.
+			{
+				Config: fmt.Sprintf(testGrantPublic, dbName, `"SELECT"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckPublicTablePrivileges(t, dbName, "SELECT", true),
+					testCheckPublicTablePrivileges(t, dbName, "INSERT", false),
+					resource.TestCheckResourceAttr("postgresql_grant.test_public", "privileges.#", "1"),
+				),
+			},
+			// Granting INSERT again out-of-band is detected.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "GRANT INSERT ON test_table TO PUBLIC")
+				},
+				Config:             fmt.Sprintf(testGrantPublic, dbName, `"SELECT"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testCheckPublicTablePrivileges checks whether the PUBLIC pseudo-role has
+// the privilege on the test table.
+func testCheckPublicTablePrivileges(t *testing.T, dbName, privilege string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow("SELECT has_table_privilege('public', 'test_table', $1)", privilege).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check %s privilege of PUBLIC: %v", privilege, err)
+		}
+
+		if granted != expected {
+			return fmt.Errorf("expected PUBLIC %s privilege on test_table to be %t, got %t", privilege, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlGrant_Targets(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE SEQUENCE test_seq")
+	dbExecute(t, config.connStr(dbName), "CREATE SEQUENCE test_seq_other")
+
+	var testGrantTargets = fmt.Sprintf(`
+	resource "postgresql_grant" "test_targets" {
+		database = "%s"
+		role     = "%s"
+		schema   = "public"
+
+		target {
+			object_type = "table"
+			privileges  = ["SELECT"]
+		}
+
+		target {
+			object_type = "sequence"
+			objects     = ["test_seq"]
+			privileges  = ["USAGE"]
+		}
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantTargets,
+				Check: resource.ComposeTestCheckFunc(
+					func(*terraform.State) error {
+						return testCheckTablePrivileges(t, dbSuffix, []string{"SELECT"})
+					},
+					testCheckSequencePrivilege(t, dbName, roleName, "test_seq", "USAGE", true),
+					testCheckSequencePrivilege(t, dbName, roleName, "test_seq", "SELECT", false),
+					testCheckSequencePrivilege(t, dbName, roleName, "test_seq_other", "USAGE", false),
+					resource.TestCheckResourceAttr("postgresql_grant.test_targets", "target.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_targets", "target.0.object_type", "table"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_targets", "target.0.privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_targets", "target.1.object_type", "sequence"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_targets", "target.1.privileges.#", "1"),
+				),
+			},
+			{
+				// Privileges revoked outside of Terraform are granted again.
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("REVOKE USAGE ON SEQUENCE test_seq FROM %s", roleName))
+				},
+				Config: testGrantTargets,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSequencePrivilege(t, dbName, roleName, "test_seq", "USAGE", true),
+				),
+			},
+		},
+	})
+}
+
+// testCheckSequencePrivilege checks whether the role has the privilege on the
+// sequence.
+func testCheckSequencePrivilege(t *testing.T, dbName, roleName, sequence, privilege string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow("SELECT has_sequence_privilege($1, $2, $3)", roleName, sequence, privilege).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check %s privilege of %s: %v", privilege, roleName, err)
+		}
+
+		if granted != expected {
+			return fmt.Errorf("expected %s %s privilege on %s to be %t, got %t", roleName, privilege, sequence, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlGrant_ForeignDataWrapper(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE FOREIGN DATA WRAPPER test_fdw")
+	dbExecute(t, config.connStr(dbName), "CREATE SERVER test_server FOREIGN DATA WRAPPER test_fdw")
+	dbExecute(t, config.connStr(dbName), "CREATE SERVER test_server_other FOREIGN DATA WRAPPER test_fdw")
+
+	var testGrantFDW = fmt.Sprintf(`
+	resource "postgresql_grant" "test_fdw" {
+		database = "%s"
+		role     = "%s"
+
+		target {
+			object_type = "foreign_data_wrapper"
+			objects     = ["test_fdw"]
+			privileges  = ["USAGE"]
+		}
+
+		target {
+			object_type = "foreign_server"
+			objects     = ["test_server"]
+			privileges  = ["USAGE"]
+		}
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		// The privileges are revoked when the grant is destroyed.
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testCheckForeignPrivilege(t, dbName, "has_foreign_data_wrapper_privilege", roleName, "test_fdw", false),
+			testCheckForeignPrivilege(t, dbName, "has_server_privilege", roleName, "test_server", false),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantFDW,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckForeignPrivilege(t, dbName, "has_foreign_data_wrapper_privilege", roleName, "test_fdw", true),
+					testCheckForeignPrivilege(t, dbName, "has_server_privilege", roleName, "test_server", true),
+					testCheckForeignPrivilege(t, dbName, "has_server_privilege", roleName, "test_server_other", false),
+					resource.TestCheckResourceAttr("postgresql_grant.test_fdw", "target.0.privileges.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_grant.test_fdw", "target.1.privileges.#", "1"),
+				),
+			},
+			{
+				// Privileges revoked outside of Terraform are granted again.
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("REVOKE USAGE ON FOREIGN SERVER test_server FROM %s", roleName))
+				},
+				Config: testGrantFDW,
+				Check:  testCheckForeignPrivilege(t, dbName, "has_server_privilege", roleName, "test_server", true),
+			},
+		},
+	})
+}
+
+// testCheckForeignPrivilege checks whether the role has the USAGE privilege
+// on the foreign data wrapper or server, with the given has_*_privilege
+// function.
+func testCheckForeignPrivilege(t *testing.T, dbName, function, roleName, object string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow(fmt.Sprintf("SELECT %s($1, $2, 'USAGE')", function), roleName, object).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check USAGE privilege of %s: %v", roleName, err)
+		}
+
+		if granted != expected {
+			return fmt.Errorf("expected %s USAGE privilege on %s to be %t, got %t", roleName, object, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestValidateGrantDatabaseObjects(t *testing.T) {
+	target := func(objectType string, objects ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"object_type": objectType,
+			"objects":     objects,
+			"privileges":  []interface{}{"USAGE"},
+		}
+	}
+
+	cases := []struct {
+		raw        map[string]interface{}
+		shouldFail bool
+	}{
+		{raw: map[string]interface{}{"target": []interface{}{target("foreign_data_wrapper", "my_fdw")}}},
+		{raw: map[string]interface{}{"target": []interface{}{target("foreign_server", "my_server")}}},
+		{raw: map[string]interface{}{"target": []interface{}{map[string]interface{}{
+			"object_type": "large_object",
+			"objects":     []interface{}{"16384"},
+			"privileges":  []interface{}{"SELECT", "UPDATE"},
+		}}}},
+		{raw: map[string]interface{}{"schema": "public", "target": []interface{}{target("sequence")}}},
+		// The objects which do not belong to a schema have to be listed.
+		{raw: map[string]interface{}{"target": []interface{}{target("foreign_data_wrapper")}}, shouldFail: true},
+		{raw: map[string]interface{}{"schema": "public", "target": []interface{}{target("foreign_server", "my_server")}}, shouldFail: true},
+		{raw: map[string]interface{}{"target": []interface{}{target("sequence")}}, shouldFail: true},
+		{raw: map[string]interface{}{"object_type": "table", "privileges": []interface{}{"SELECT"}}, shouldFail: true},
+		{raw: map[string]interface{}{"target": []interface{}{target("large_object", "my_lo")}}, shouldFail: true},
+	}
+
+	for _, c := range cases {
+		c.raw["role"] = "my_role"
+		c.raw["database"] = "my_db"
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLGrant().Schema, c.raw)
+
+		err := validateGrant(&Client{version: semver.MustParse("13.0.0")}, d)
+		if c.shouldFail && err == nil {
+			t.Errorf("expected validateGrant(%v) to fail", c.raw)
+		}
+		if !c.shouldFail && err != nil {
+			t.Errorf("unexpected error validating %v: %v", c.raw, err)
+		}
+	}
+}
+
+func TestAccPostgresqlGrant_FunctionOverloads(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_func(a integer) RETURNS integer AS 'SELECT a' LANGUAGE sql")
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_func(a text) RETURNS text AS 'SELECT a' LANGUAGE sql")
+	// EXECUTE is granted to PUBLIC by default.
+	dbExecute(t, config.connStr(dbName), "REVOKE EXECUTE ON FUNCTION test_func(integer), test_func(text) FROM PUBLIC")
+
+	var testGrantFunction = `
+	resource "postgresql_grant" "test_function" {
+		database = "%s"
+		role     = "%s"
+		schema   = "public"
+
+		target {
+			object_type = "function"
+			objects     = ["%s"]
+			privileges  = ["EXECUTE"]
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testGrantFunction, dbName, roleName, "test_func(int4)"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(integer)", true),
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(text)", false),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testGrantFunction, dbName, roleName, "TEST_FUNC(text)"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(integer)", false),
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(text)", true),
+				),
+			},
+			{
+				// Privileges revoked outside of Terraform are granted again.
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("REVOKE EXECUTE ON FUNCTION test_func(text) FROM %s", roleName))
+				},
+				Config: fmt.Sprintf(testGrantFunction, dbName, roleName, "TEST_FUNC(text)"),
+				Check:  testCheckFunctionPrivilege(t, dbName, roleName, "test_func(text)", true),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlGrant_AllFunctions(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_func(a integer) RETURNS integer AS 'SELECT a' LANGUAGE sql")
+	dbExecute(t, config.connStr(dbName), "CREATE FUNCTION other_func(a integer) RETURNS integer AS 'SELECT a' LANGUAGE sql")
+	dbExecute(t, config.connStr(dbName), "REVOKE EXECUTE ON ALL FUNCTIONS IN SCHEMA public FROM PUBLIC")
+
+	var testGrantAllFunctions = fmt.Sprintf(`
+	resource "postgresql_grant" "test_functions" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "public"
+		object_type = "function"
+		privileges  = ["EXECUTE"]
+	}
+	`, dbName, roleName)
+
+	var testGrantMatchingFunctions = fmt.Sprintf(`
+	resource "postgresql_grant" "test_functions" {
+		database       = "%s"
+		role           = "%s"
+		schema         = "public"
+		object_type    = "function"
+		object_matcher = "^test_"
+		privileges     = ["EXECUTE"]
+	}
+	`, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantAllFunctions,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(integer)", true),
+					testCheckFunctionPrivilege(t, dbName, roleName, "other_func(integer)", true),
+				),
+			},
+			{
+				// The functions created since the last apply are granted too.
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "CREATE FUNCTION test_func(a text) RETURNS text AS 'SELECT a' LANGUAGE sql")
+					dbExecute(t, config.connStr(dbName), "REVOKE EXECUTE ON FUNCTION test_func(text) FROM PUBLIC")
+				},
+				Config: testGrantAllFunctions,
+				Check:  testCheckFunctionPrivilege(t, dbName, roleName, "test_func(text)", true),
+			},
+			{
+				Config: testGrantMatchingFunctions,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(integer)", true),
+					testCheckFunctionPrivilege(t, dbName, roleName, "test_func(text)", true),
+					testCheckFunctionPrivilege(t, dbName, roleName, "other_func(integer)", false),
+					resource.TestCheckResourceAttr("postgresql_grant.test_functions", "objects.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckFunctionPrivilege checks whether the role can execute the function.
+func testCheckFunctionPrivilege(t *testing.T, dbName, roleName, function string, expected bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var granted bool
+		if err := db.QueryRow("SELECT has_function_privilege($1, $2, 'EXECUTE')", roleName, function).Scan(&granted); err != nil {
+			return fmt.Errorf("could not check EXECUTE privilege of %s: %v", roleName, err)
+		}
+
+		if granted != expected {
+			return fmt.Errorf("expected %s EXECUTE privilege on %s to be %t, got %t", roleName, function, expected, granted)
+		}
+		return nil
+	}
+}
+
+func TestParseFunctionSignature(t *testing.T) {
+	cases := []struct {
+		signature  string
+		name       string
+		args       []string
+		shouldFail bool
+	}{
+		{signature: "my_func()", name: "my_func"},
+		{signature: "My_Func(int, text)", name: "my_func", args: []string{"int", "text"}},
+		{signature: ` "My Func" ( numeric(10,2) , character varying[] ) `, name: "My Func", args: []string{"numeric(10,2)", "character varying[]"}},
+		{signature: "my_func", shouldFail: true},
+		{signature: "my_func(int,)", shouldFail: true},
+		{signature: "(int)", shouldFail: true},
+	}
+
+	for _, c := range cases {
+		name, args, err := parseFunctionSignature(c.signature)
+		if c.shouldFail {
+			if err == nil {
+				t.Errorf("expected parseFunctionSignature(%q) to fail", c.signature)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", c.signature, err)
+			continue
+		}
+		if name != c.name || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("parseFunctionSignature(%q) = (%q, %q), expected (%q, %q)", c.signature, name, args, c.name, c.args)
+		}
+	}
+}
+
+// A role which cannot connect to the database of the grant gets a clear
+// error naming the database and the role.
+func TestAccPostgresqlGrant_NoConnectPrivilege(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), fmt.Sprintf("REVOKE CONNECT ON DATABASE %s FROM PUBLIC", dbName))
+
+	testGrantNoConnect := fmt.Sprintf(`
+	provider "postgresql" {
+		username = "%s"
+		password = "%s"
+	}
+
+	resource "postgresql_grant" "test" {
+		database    = "%s"
+		role        = "%s"
+		schema      = "public"
+		object_type = "table"
+		privileges  = ["SELECT"]
+	}
+	`, roleName, testRolePassword, dbName, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testGrantNoConnect,
+				ExpectError: regexp.MustCompile(fmt.Sprintf(
+					"cannot connect to database %s as %s, the role lacks the CONNECT privilege", dbName, roleName,
+				)),
+			},
+		},
+	})
+}
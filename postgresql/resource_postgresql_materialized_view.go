@@ -0,0 +1,321 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	matViewNameAttr            = "name"
+	matViewDatabaseAttr        = "database"
+	matViewSchemaAttr          = "schema"
+	matViewQueryAttr           = "query"
+	matViewWithDataAttr        = "with_data"
+	matViewRefreshOnChangeAttr = "refresh_on_change"
+	matViewConcurrentlyAttr    = "refresh_concurrently"
+	matViewDefinitionAttr      = "definition"
+
+	// matViewDefaultTimeout is the default timeout of the operations on a
+	// materialized view, which run its query.
+	matViewDefaultTimeout = 30 * time.Minute
+)
+
+func resourcePostgreSQLMaterializedView() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLMaterializedViewCreate,
+		Read:   resourcePostgreSQLMaterializedViewRead,
+		Update: resourcePostgreSQLMaterializedViewUpdate,
+		Delete: resourcePostgreSQLMaterializedViewDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(matViewDefaultTimeout),
+			Update: schema.DefaultTimeout(matViewDefaultTimeout),
+			Delete: schema.DefaultTimeout(matViewDefaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			matViewNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateIdentifier,
+				Description:  "The name of the materialized view",
+			},
+			matViewDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database to create the materialized view in",
+			},
+			matViewSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema to create the materialized view in",
+			},
+			matViewQueryAttr: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentSQL,
+				Description:      "The SELECT query of the materialized view",
+			},
+			matViewWithDataAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether the materialized view is populated, it cannot be queried otherwise",
+			},
+			matViewRefreshOnChangeAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Any value (e.g. a hash of the data sources of the query) which refreshes the materialized view when it changes",
+			},
+			matViewConcurrentlyAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Refresh the materialized view without locking out the concurrent selects, it requires a unique index on the view",
+			},
+			matViewDefinitionAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The definition of the materialized view as stored by PostgreSQL",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLMaterializedViewCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureMaterializedView) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support materialized views", c.version.String())
+	}
+
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutCreate)
+	defer cancel()
+
+	txn, err := startTransactionContext(ctx, c, d.Get(matViewDatabaseAttr).(string))
+	if err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+	defer txn.Rollback()
+
+	b := bytes.NewBufferString("CREATE MATERIALIZED VIEW ")
+	fmt.Fprint(b, matViewName(d), " AS ", d.Get(matViewQueryAttr).(string))
+	if !d.Get(matViewWithDataAttr).(bool) {
+		fmt.Fprint(b, " WITH NO DATA")
+	}
+
+	if _, err := txn.ExecContext(ctx, b.String()); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("Error creating materialized view: {{err}}", err))
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	d.SetId(generateMatViewID(d))
+
+	return readMaterializedView(c, d)
+}
+
+func resourcePostgreSQLMaterializedViewRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readMaterializedView(c, d)
+}
+
+func readMaterializedView(c *Client, d *schema.ResourceData) error {
+	txn, err := startTransaction(c, d.Get(matViewDatabaseAttr).(string))
+	switch {
+	case isDatabaseNotFoundError(err):
+		log.Printf("[WARN] PostgreSQL database of materialized view (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return err
+	}
+	defer txn.Rollback()
+
+	var definition string
+	var populated bool
+	query := `SELECT pg_catalog.pg_get_viewdef(c.oid), c.relispopulated ` +
+		`FROM pg_catalog.pg_class c ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'm'`
+	err = txn.QueryRow(query, d.Get(matViewSchemaAttr).(string), d.Get(matViewNameAttr).(string)).Scan(&definition, &populated)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL materialized view (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading materialized view: {{err}}", err)
+	}
+
+	// The query is stored deparsed by PostgreSQL, so the definition read
+	// after creating the view is kept to detect the changes made outside of
+	// Terraform, which recreate the view.  The deparsed query is only compared
+	// once normalized as its formatting changes between PostgreSQL versions.
+	if previous := d.Get(matViewDefinitionAttr).(string); previous != "" && normalizeSQL(previous) != normalizeSQL(definition) {
+		log.Printf("[WARN] PostgreSQL materialized view (%s) definition changed outside of Terraform", d.Id())
+		d.Set(matViewQueryAttr, "")
+	}
+
+	d.Set(matViewDefinitionAttr, definition)
+	d.Set(matViewWithDataAttr, populated)
+	d.SetId(generateMatViewID(d))
+
+	return nil
+}
+
+func resourcePostgreSQLMaterializedViewUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if !d.HasChange(matViewWithDataAttr) && !d.HasChange(matViewRefreshOnChangeAttr) {
+		return readMaterializedView(c, d)
+	}
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
+
+	txn, err := startTransactionContext(ctx, c, d.Get(matViewDatabaseAttr).(string))
+	if err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+	defer txn.Rollback()
+
+	if err := refreshMaterializedView(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	return readMaterializedView(c, d)
+}
+
+// refreshMaterializedView replaces the content of the materialized view by
+// the result of its query, or empties it when with_data is false.
+func refreshMaterializedView(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	withData := d.Get(matViewWithDataAttr).(bool)
+
+	b := bytes.NewBufferString("REFRESH MATERIALIZED VIEW ")
+	if concurrent, err := refreshConcurrently(c, txn, d); err != nil {
+		return err
+	} else if concurrent {
+		fmt.Fprint(b, "CONCURRENTLY ")
+	}
+	fmt.Fprint(b, matViewName(d))
+	if !withData {
+		fmt.Fprint(b, " WITH NO DATA")
+	}
+
+	if _, err := txn.ExecContext(ctx, b.String()); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error refreshing materialized view %s: {{err}}", d.Id()), err)
+	}
+
+	return nil
+}
+
+// refreshConcurrently returns whether the materialized view can be refreshed
+// concurrently as configured.  PostgreSQL requires a unique index on a view
+// which is already populated, a clear error is returned otherwise.
+func refreshConcurrently(c *Client, txn *sql.Tx, d *schema.ResourceData) (bool, error) {
+	if !d.Get(matViewConcurrentlyAttr).(bool) {
+		return false, nil
+	}
+
+	if !c.featureSupported(featureRefreshMatViewConcurrently) {
+		return false, fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support REFRESH MATERIALIZED VIEW CONCURRENTLY", c.version.String())
+	}
+
+	// An unpopulated view has to be refreshed without CONCURRENTLY, as well
+	// as a view which is emptied.
+	oldWithData, withData := d.GetChange(matViewWithDataAttr)
+	if !oldWithData.(bool) || !withData.(bool) {
+		log.Printf("[DEBUG] PostgreSQL materialized view (%s) not populated, refreshing it without CONCURRENTLY", d.Id())
+		return false, nil
+	}
+
+	var hasUniqueIndex bool
+	query := `SELECT EXISTS (` +
+		`SELECT 1 FROM pg_catalog.pg_index i ` +
+		`JOIN pg_catalog.pg_class c ON c.oid = i.indrelid ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND i.indisunique AND i.indpred IS NULL` +
+		`)`
+	if err := txn.QueryRow(query, d.Get(matViewSchemaAttr).(string), d.Get(matViewNameAttr).(string)).Scan(&hasUniqueIndex); err != nil {
+		return false, errwrap.Wrapf("Error reading the indexes of materialized view: {{err}}", err)
+	}
+	if !hasUniqueIndex {
+		return false, fmt.Errorf("materialized view %s cannot be refreshed concurrently without a unique index on all its rows", d.Id())
+	}
+
+	return true, nil
+}
+
+func resourcePostgreSQLMaterializedViewDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutDelete)
+	defer cancel()
+
+	txn, err := startTransactionContext(ctx, c, d.Get(matViewDatabaseAttr).(string))
+	if err != nil {
+		return operationError(ctx, schema.TimeoutDelete, err)
+	}
+	defer txn.Rollback()
+
+	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s", matViewName(d))
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("Error deleting materialized view: {{err}}", err))
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// matViewName returns the quoted schema qualified name of the materialized view.
+func matViewName(d *schema.ResourceData) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		pq.QuoteIdentifier(d.Get(matViewSchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(matViewNameAttr).(string)),
+	)
+}
+
+func generateMatViewID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(matViewDatabaseAttr).(string),
+		d.Get(matViewSchemaAttr).(string),
+		d.Get(matViewNameAttr).(string),
+	}, ".")
+}
@@ -0,0 +1,164 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlMaterializedView_Refresh(t *testing.T) {
+	testCheckCompatibleVersion(t, featureMaterializedView)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "INSERT INTO test_table VALUES ('a')")
+
+	var testMatViewConfig = `
+	resource "postgresql_materialized_view" "test" {
+		name              = "test_matview"
+		database          = "%s"
+		query             = "SELECT val FROM test_table"
+		with_data         = %t
+		refresh_on_change = "%s"
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckMaterializedViewDestroy(t, dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testMatViewConfig, dbName, true, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMaterializedViewRows(t, dbName, 1),
+					resource.TestCheckResourceAttr("postgresql_materialized_view.test", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_materialized_view.test", "with_data", "true"),
+					resource.TestCheckResourceAttrSet("postgresql_materialized_view.test", "definition"),
+				),
+			},
+			// The view is only refreshed when refresh_on_change changes.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "INSERT INTO test_table VALUES ('b')")
+				},
+				Config: fmt.Sprintf(testMatViewConfig, dbName, true, "1"),
+				Check:  testAccCheckMaterializedViewRows(t, dbName, 1),
+			},
+			{
+				Config: fmt.Sprintf(testMatViewConfig, dbName, true, "2"),
+				Check:  testAccCheckMaterializedViewRows(t, dbName, 2),
+			},
+			{
+				Config: fmt.Sprintf(testMatViewConfig, dbName, false, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckMaterializedViewRows(t, dbName, -1),
+					resource.TestCheckResourceAttr("postgresql_materialized_view.test", "with_data", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlMaterializedView_RefreshConcurrently(t *testing.T) {
+	testCheckCompatibleVersion(t, featureRefreshMatViewConcurrently)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+	dbExecute(t, config.connStr(dbName), "INSERT INTO test_table VALUES ('a')")
+
+	var testMatViewConfig = `
+	resource "postgresql_materialized_view" "test" {
+		name                 = "test_matview"
+		database             = "%s"
+		query                = "SELECT val FROM test_table"
+		refresh_on_change    = "%s"
+		refresh_concurrently = true
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckMaterializedViewDestroy(t, dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testMatViewConfig, dbName, "1"),
+				Check:  testAccCheckMaterializedViewRows(t, dbName, 1),
+			},
+			// Without a unique index the view cannot be refreshed concurrently.
+			{
+				Config:      fmt.Sprintf(testMatViewConfig, dbName, "2"),
+				ExpectError: regexp.MustCompile("cannot be refreshed concurrently without a unique index"),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "CREATE UNIQUE INDEX test_matview_val ON test_matview (val)")
+					dbExecute(t, config.connStr(dbName), "INSERT INTO test_table VALUES ('b')")
+				},
+				Config: fmt.Sprintf(testMatViewConfig, dbName, "3"),
+				Check:  testAccCheckMaterializedViewRows(t, dbName, 2),
+			},
+		},
+	})
+}
+
+func testAccCheckMaterializedViewDestroy(t *testing.T, dbName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_matviews WHERE matviewname = 'test_matview')").Scan(&exists); err != nil {
+			return fmt.Errorf("could not check materialized view: %v", err)
+		}
+		if exists {
+			return fmt.Errorf("materialized view test_matview still exists after destroy")
+		}
+		return nil
+	}
+}
+
+// testAccCheckMaterializedViewRows checks the number of rows of the test
+// materialized view, -1 if it is not populated.
+func testAccCheckMaterializedViewRows(t *testing.T, dbName string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		config := getTestConfig(t)
+		db, err := sql.Open("postgres", config.connStr(dbName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for db %s: %v", dbName, err)
+		}
+		defer db.Close()
+
+		var populated bool
+		if err := db.QueryRow("SELECT ispopulated FROM pg_catalog.pg_matviews WHERE matviewname = 'test_matview'").Scan(&populated); err != nil {
+			return fmt.Errorf("could not read materialized view: %v", err)
+		}
+
+		rows := -1
+		if populated {
+			if err := db.QueryRow("SELECT count(*) FROM test_matview").Scan(&rows); err != nil {
+				return fmt.Errorf("could not count the rows of materialized view: %v", err)
+			}
+		}
+
+		if rows != expected {
+			return fmt.Errorf("expected %d rows in materialized view, got %d", expected, rows)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,180 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	replSlotNameAttr       = "name"
+	replSlotDatabaseAttr   = "database"
+	replSlotPluginAttr     = "plugin"
+	replSlotTypeAttr       = "slot_type"
+	replSlotActiveAttr     = "active"
+	replSlotRestartLSNAttr = "restart_lsn"
+)
+
+func resourcePostgreSQLReplicationSlot() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLReplicationSlotCreate,
+		Read:   resourcePostgreSQLReplicationSlotRead,
+		Delete: resourcePostgreSQLReplicationSlotDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			replSlotNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateIdentifier,
+				Description:  "The name of the replication slot",
+			},
+			replSlotDatabaseAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "The database of the logical replication slot",
+			},
+			replSlotPluginAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The output plugin of the logical replication slot, the slot is physical if not set",
+			},
+			replSlotTypeAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The type of the replication slot (physical or logical)",
+			},
+			replSlotActiveAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the replication slot is currently used by a connection",
+			},
+			replSlotRestartLSNAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The oldest WAL location which might be required by the consumer of the slot",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLReplicationSlotCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureReplicationSlots) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support replication slots", c.version.String())
+	}
+
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	slotName := d.Get(replSlotNameAttr).(string)
+
+	if plugin, ok := d.GetOk(replSlotPluginAttr); ok {
+		// A logical replication slot is created in the database of the connection.
+		client, err := databaseClient(c, d.Get(replSlotDatabaseAttr).(string))
+		if err != nil {
+			return err
+		}
+
+		if _, err := client.DB().Exec("SELECT pg_catalog.pg_create_logical_replication_slot($1, $2)", slotName, plugin.(string)); err != nil {
+			return errwrap.Wrapf("Error creating logical replication slot: {{err}}", err)
+		}
+	} else {
+		if _, ok := d.GetOk(replSlotDatabaseAttr); ok {
+			return fmt.Errorf("Error creating replication slot %s: %s can only be set with %s", slotName, replSlotDatabaseAttr, replSlotPluginAttr)
+		}
+
+		if _, err := c.DB().Exec("SELECT pg_catalog.pg_create_physical_replication_slot($1)", slotName); err != nil {
+			return errwrap.Wrapf("Error creating physical replication slot: {{err}}", err)
+		}
+	}
+
+	d.SetId(slotName)
+
+	return readReplicationSlot(c, d)
+}
+
+func resourcePostgreSQLReplicationSlotRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readReplicationSlot(c, d)
+}
+
+func readReplicationSlot(c *Client, d *schema.ResourceData) error {
+	slotID := d.Id()
+
+	var slotName, slotType, plugin, database, restartLSN string
+	var active bool
+	query := `SELECT slot_name, slot_type, COALESCE(plugin, ''), COALESCE(database, ''), active, ` +
+		`COALESCE(restart_lsn::text, '') ` +
+		`FROM pg_catalog.pg_replication_slots WHERE slot_name = $1`
+	err := c.DB().QueryRow(query, slotID).Scan(&slotName, &slotType, &plugin, &database, &active, &restartLSN)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL replication slot (%s) not found", slotID)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading replication slot: {{err}}", err)
+	}
+
+	d.Set(replSlotNameAttr, slotName)
+	d.Set(replSlotTypeAttr, slotType)
+	d.Set(replSlotPluginAttr, plugin)
+	d.Set(replSlotDatabaseAttr, database)
+	d.Set(replSlotActiveAttr, active)
+	d.Set(replSlotRestartLSNAttr, restartLSN)
+	d.SetId(slotName)
+
+	return nil
+}
+
+func resourcePostgreSQLReplicationSlotDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	slotName := d.Id()
+
+	// A logical replication slot can only be dropped from its database.
+	client, err := databaseClient(c, d.Get(replSlotDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+
+	// pg_drop_replication_slot fails on an active slot, give a clearer error.
+	var active bool
+	err = client.DB().QueryRow("SELECT active FROM pg_catalog.pg_replication_slots WHERE slot_name = $1", slotName).Scan(&active)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL replication slot (%s) already dropped", slotName)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading replication slot: {{err}}", err)
+	}
+
+	if active {
+		return fmt.Errorf("Error deleting replication slot %s: the slot is active, its consumer needs to be stopped first", slotName)
+	}
+
+	if _, err := client.DB().Exec("SELECT pg_catalog.pg_drop_replication_slot($1)", slotName); err != nil {
+		return errwrap.Wrapf("Error deleting replication slot: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
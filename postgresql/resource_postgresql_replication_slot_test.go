@@ -0,0 +1,150 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlReplicationSlot_Physical(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlReplicationSlotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlReplicationSlotPhysicalConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlReplicationSlotExists("tf_tests_physical_slot"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "name", "tf_tests_physical_slot"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "slot_type", "physical"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "plugin", ""),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "database", ""),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "active", "false"),
+				),
+			},
+			{
+				ResourceName:      "postgresql_replication_slot.slot",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlReplicationSlot_Logical(t *testing.T) {
+	testCheckLogicalReplication(t)
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlReplicationSlotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlReplicationSlotLogicalConfig, dbName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlReplicationSlotExists("tf_tests_logical_slot"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "slot_type", "logical"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "plugin", "test_decoding"),
+					resource.TestCheckResourceAttr("postgresql_replication_slot.slot", "database", dbName),
+				),
+			},
+		},
+	})
+}
+
+// testCheckLogicalReplication skips the test if the server does not support
+// logical replication slots (wal_level lower than logical).
+func testCheckLogicalReplication(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	config := getTestConfig(t)
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+
+	var walLevel string
+	if err := client.DB().QueryRow("SHOW wal_level").Scan(&walLevel); err != nil {
+		t.Fatalf("could not read wal_level: %v", err)
+	}
+	if walLevel != "logical" {
+		t.Skipf("Skip test: wal_level is %s, logical replication slots need logical", walLevel)
+	}
+}
+
+func testAccCheckPostgresqlReplicationSlotDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_replication_slot" {
+			continue
+		}
+
+		exists, err := checkReplicationSlotExists(client, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error checking replication slot %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Replication slot still exists after destroy")
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckPostgresqlReplicationSlotExists(slotName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		exists, err := checkReplicationSlotExists(client, slotName)
+		if err != nil {
+			return fmt.Errorf("Error checking replication slot %s", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("Replication slot not found")
+		}
+
+		return nil
+	}
+}
+
+func checkReplicationSlotExists(client *Client, slotName string) (bool, error) {
+	var _rez int
+	err := client.DB().QueryRow("SELECT 1 FROM pg_catalog.pg_replication_slots WHERE slot_name = $1", slotName).Scan(&_rez)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading info about replication slot: %s", err)
+	}
+
+	return true, nil
+}
+
+var testAccPostgresqlReplicationSlotPhysicalConfig = `
+resource "postgresql_replication_slot" "slot" {
+  name = "tf_tests_physical_slot"
+}
+`
+
+var testAccPostgresqlReplicationSlotLogicalConfig = `
+resource "postgresql_replication_slot" "slot" {
+  name     = "tf_tests_logical_slot"
+  database = "%s"
+  plugin   = "test_decoding"
+}
+`
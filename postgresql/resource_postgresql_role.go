@@ -1,11 +1,22 @@
 package postgresql
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -18,19 +29,43 @@ const (
 	roleCreateDBAttr          = "create_database"
 	roleCreateRoleAttr        = "create_role"
 	roleEncryptedPassAttr     = "encrypted_password"
+	roleGroupAttr             = "group"
 	roleInheritAttr           = "inherit"
 	roleLoginAttr             = "login"
 	roleNameAttr              = "name"
 	rolePasswordAttr          = "password"
+	rolePasswordNullAttr      = "password_null"
 	roleReplicationAttr       = "replication"
 	roleSkipDropRoleAttr      = "skip_drop_role"
 	roleSkipReassignOwnedAttr = "skip_reassign_owned"
+	roleReassignOwnedAttr     = "reassign_owned"
+	roleDropOwnedAttr         = "drop_owned"
+	roleStorePasswordAttr     = "store_password_in_state"
 	roleSuperuserAttr         = "superuser"
 	roleValidUntilAttr        = "valid_until"
+	roleValidUntilDurAttr     = "valid_until_duration"
 	roleRolesAttr             = "roles"
+	roleMembershipAttr        = "membership"
+	roleMembersAttr           = "members"
+	roleSettingsAttr          = "role_settings"
+	roleSQLStatementsAttr     = "sql_statements"
+	roleOIDAttr               = "oid"
+
+	// Membership block options
+	roleMembershipRoleAttr    = "role"
+	roleMembershipInheritAttr = "inherit"
+	roleMembershipSetAttr     = "set"
+	roleMembershipAdminAttr   = "admin"
 
 	// Deprecated options
 	roleDepEncryptedAttr = "encrypted"
+
+	// roleDepPasswordNull is the legacy password value which clears the
+	// role's password, superseded by rolePasswordNullAttr.
+	roleDepPasswordNull = "NULL"
+
+	// roleDefaultTimeout is the default timeout of the operations on a role.
+	roleDefaultTimeout = 20 * time.Minute
 )
 
 func resourcePostgreSQLRole() *schema.Resource {
@@ -41,22 +76,40 @@ func resourcePostgreSQLRole() *schema.Resource {
 		Delete: resourcePostgreSQLRoleDelete,
 		Exists: resourcePostgreSQLRoleExists,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourcePostgreSQLRoleImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(roleDefaultTimeout),
+			Update: schema.DefaultTimeout(roleDefaultTimeout),
+			Delete: schema.DefaultTimeout(roleDefaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
 			roleNameAttr: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the role",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateIdentifier,
+				Description:  "The name of the role",
 			},
 			rolePasswordAttr: {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Computed:    true,
-				Sensitive:   true,
-				DefaultFunc: schema.EnvDefaultFunc("PGPASSWORD", nil),
-				Description: "Sets the role's password",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Sensitive:    true,
+				Description:  "Sets the role's password",
+				ValidateFunc: validateRolePassword,
+				// The password is read back hashed from pg_authid.
+				DiffSuppressFunc: suppressRolePasswordHashDiff,
+			},
+			// A string rather than a bool, so that an explicit false can be
+			// told apart from an unset attribute, see rolePasswordNull.
+			rolePasswordNullAttr: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateBoolString,
+				StateFunc:    normalizeBoolString,
+				Description:  "Clear the role's password",
 			},
 			roleDepEncryptedAttr: {
 				Type:       schema.TypeString,
@@ -71,6 +124,59 @@ func resourcePostgreSQLRole() *schema.Resource {
 				MinItems:    0,
 				Description: "Role(s) to grant to this new role",
 			},
+			roleMembershipAttr: {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				ConflictsWith: []string{roleRolesAttr},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleMembershipRoleAttr: {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The role to grant to this role",
+						},
+						roleMembershipInheritAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether this role inherits the privileges of the granted role (PostgreSQL 16+)",
+						},
+						roleMembershipSetAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether this role can SET ROLE to the granted role (PostgreSQL 16+)",
+						},
+						roleMembershipAdminAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether this role can grant the granted role to other roles",
+						},
+					},
+				},
+				Description: "Role(s) to grant to this role, with the options of the membership",
+			},
+			roleMembersAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "Role(s) to grant this role to",
+			},
+			roleSettingsAttr: {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: validateRoleSettings,
+				Description:  "Run-time parameters set for the role (ALTER ROLE ... SET)",
+			},
+			roleSQLStatementsAttr: {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The statements run by the last creation or update of the role, with the passwords redacted",
+			},
 			roleEncryptedPassAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -78,10 +184,17 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Description: "Control whether the password is stored encrypted in the system catalogs",
 			},
 			roleValidUntilAttr: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "infinity",
+				Description:      "Sets a date and time, or a duration from now (e.g. +90d), after which the role's password is no longer valid, or NULL to unset it",
+				ValidateFunc:     validateRoleValidUntil,
+				DiffSuppressFunc: suppressRoleValidUntilDiff,
+			},
+			roleValidUntilDurAttr: {
 				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     "infinity",
-				Description: "Sets a date and time after which the role's password is no longer valid",
+				Computed:    true,
+				Description: "The duration from now valid_until has last been resolved from, empty if it was not a duration",
 			},
 			roleConnLimitAttr: {
 				Type:         schema.TypeInt,
@@ -120,6 +233,12 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Determine whether a role is allowed to log in",
 			},
+			roleGroupAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Mark the role as a group role, which can neither log in nor have a password",
+			},
 			roleReplicationAttr: {
 				Type:        schema.TypeBool,
 				Optional:    true,
@@ -144,6 +263,29 @@ func resourcePostgreSQLRole() *schema.Resource {
 				Default:     false,
 				Description: "Skip actually running the REASSIGN OWNED command when removing a role from PostgreSQL",
 			},
+			roleReassignOwnedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Run the REASSIGN OWNED command when removing a role from PostgreSQL",
+			},
+			roleDropOwnedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Run the DROP OWNED command when removing a role from PostgreSQL",
+			},
+			roleStorePasswordAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Read the role's password hash from the database into the state",
+			},
+			roleOIDAttr: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The OID of the role, used to find it after it has been renamed outside of Terraform",
+			},
 		},
 	}
 }
@@ -153,10 +295,28 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
-	if err != nil {
+	if err := checkRoleAttributes(d); err != nil {
 		return err
 	}
+
+	// A role without a configured password, but a group role, gets the one
+	// of PGPASSWORD.  It is not the default of the attribute, so that the
+	// configured password can be checked by checkRoleAttributes.
+	if _, ok := d.GetOk(rolePasswordAttr); !ok && !d.Get(roleGroupAttr).(bool) && !rolePasswordNull(d) {
+		if password := os.Getenv("PGPASSWORD"); password != "" {
+			d.Set(rolePasswordAttr, password)
+		}
+	}
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutCreate)
+	defer cancel()
+
+	d.Set(roleSQLStatementsAttr, []string{})
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
 	defer txn.Rollback()
 
 	stringOpts := []struct {
@@ -183,18 +343,41 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 		{roleCreateRoleAttr, "CREATEROLE", "NOCREATEROLE"},
 		{roleInheritAttr, "INHERIT", "NOINHERIT"},
 		{roleLoginAttr, "LOGIN", "NOLOGIN"},
-		{roleReplicationAttr, "REPLICATION", "NOREPLICATION"},
 
 		// roleEncryptedPassAttr is used only when rolePasswordAttr is set.
 		// {roleEncryptedPassAttr, "ENCRYPTED", "UNENCRYPTED"},
 	}
 
-	if c.featureSupported(featureRLS) {
+	if c.flavor == flavorRedshift {
+		// Redshift users always log in and have no INHERIT and CREATEROLE
+		// attributes.
+		supported := boolOpts[:0]
+		for _, opt := range boolOpts {
+			switch opt.hclKey {
+			case roleInheritAttr, roleCreateRoleAttr, roleLoginAttr:
+			default:
+				supported = append(supported, opt)
+			}
+		}
+		boolOpts = supported
+	}
+
+	if c.featureSupported(featureRoleReplication) {
+		boolOpts = append(boolOpts, boolOptType{roleReplicationAttr, "REPLICATION", "NOREPLICATION"})
+	} else if d.Get(roleReplicationAttr).(bool) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support the REPLICATION role attribute", c.version.String())
+	}
+
+	if c.featureSupported(featureRoleBypassRLS) {
 		boolOpts = append(boolOpts, boolOptType{roleBypassRLSAttr, "BYPASSRLS", "NOBYPASSRLS"})
 	}
 
 	createOpts := make([]string, 0, len(stringOpts)+len(intOpts)+len(boolOpts))
 
+	if rolePasswordNull(d) {
+		createOpts = append(createOpts, "PASSWORD NULL")
+	}
+
 	for _, opt := range stringOpts {
 		v, ok := d.GetOk(opt.hclKey)
 		if !ok {
@@ -205,23 +388,18 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 		if val != "" {
 			switch {
 			case opt.hclKey == rolePasswordAttr:
-				if strings.ToUpper(v.(string)) == "NULL" {
-					createOpts = append(createOpts, "PASSWORD NULL")
-				} else {
-					if d.Get(roleEncryptedPassAttr).(bool) {
-						createOpts = append(createOpts, "ENCRYPTED")
-					} else {
-						createOpts = append(createOpts, "UNENCRYPTED")
-					}
-					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, pqQuoteLiteral(val)))
+				if rolePasswordNull(d) {
+					// The password is cleared above.
+					continue
 				}
+				createOpts = append(createOpts, rolePasswordOpts(d, val)...)
 			case opt.hclKey == roleValidUntilAttr:
-				switch {
-				case v.(string) == "", strings.ToLower(v.(string)) == "infinity":
-					createOpts = append(createOpts, fmt.Sprintf("%s '%s'", opt.sqlKey, "infinity"))
-				default:
-					createOpts = append(createOpts, fmt.Sprintf("%s %s", opt.sqlKey, pq.QuoteIdentifier(val)))
+				validUntil, err := roleValidUntilSQL(ctx, txn, val)
+				if err != nil {
+					return operationError(ctx, schema.TimeoutCreate, err)
 				}
+				createOpts = append(createOpts, fmt.Sprintf("%s %s", opt.sqlKey, validUntil))
+				d.Set(roleValidUntilDurAttr, roleValidUntilDuration(val))
 			default:
 				createOpts = append(createOpts, fmt.Sprintf("%s %s", opt.sqlKey, pq.QuoteIdentifier(val)))
 			}
@@ -240,6 +418,10 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 			continue
 		}
 		val := d.Get(opt.hclKey).(bool)
+		if opt.hclKey == roleLoginAttr && d.Get(roleGroupAttr).(bool) {
+			// A group role never logs in.
+			val = false
+		}
 		valStr := opt.sqlKeyDisable
 		if val {
 			valStr = opt.sqlKeyEnable
@@ -259,16 +441,25 @@ func resourcePostgreSQLRoleCreate(d *schema.ResourceData, meta interface{}) erro
 	}
 
 	sql := fmt.Sprintf("CREATE ROLE %s%s", pq.QuoteIdentifier(roleName), createStr)
-	if _, err := txn.Exec(sql); err != nil {
-		return errwrap.Wrapf(fmt.Sprintf("error creating role %s: {{err}}", roleName), err)
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf(fmt.Sprintf("error creating role %s: {{err}}", roleName), redactError(err, d.Get(rolePasswordAttr).(string))))
 	}
 
-	if err = grantRoles(txn, d); err != nil {
-		return err
+	if err = grantRoles(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+
+	if err = grantMembers(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+
+	if err = setRoleSettings(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
 	}
 
 	if err = txn.Commit(); err != nil {
-		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
 	}
 
 	d.SetId(roleName)
@@ -281,43 +472,146 @@ func resourcePostgreSQLRoleDelete(d *schema.ResourceData, meta interface{}) erro
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
+	ctx, cancel := operationContext(c, d, schema.TimeoutDelete)
+	defer cancel()
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
 	if err != nil {
-		return err
+		return operationError(ctx, schema.TimeoutDelete, err)
 	}
 	defer txn.Rollback()
 
 	roleName := d.Get(roleNameAttr).(string)
 
+	// skip_reassign_owned skips both commands, as it did before they could
+	// be toggled individually.
+	skipReassignOwned := d.Get(roleSkipReassignOwnedAttr).(bool)
+
+	queries := roleDropQueries(
+		c, roleName,
+		!skipReassignOwned && d.Get(roleReassignOwnedAttr).(bool),
+		!skipReassignOwned && d.Get(roleDropOwnedAttr).(bool),
+		!d.Get(roleSkipDropRoleAttr).(bool),
+	)
+
+	if len(queries) > 0 {
+		if err := execRoleDropQueries(ctx, c, txn, roleName, queries); err != nil {
+			return operationError(ctx, schema.TimeoutDelete, err)
+		}
+
+		if err := txn.Commit(); err != nil {
+			return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("Error committing schema: {{err}}", err))
+		}
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// roleDropQueries returns the statements dropping a role: the objects it owns
+// are reassigned to the current user and dropped first when reassignOwned and
+// dropOwned are set, in the database the provider is connected to.
+func roleDropQueries(c *Client, roleName string, reassignOwned, dropOwned, dropRole bool) []string {
 	queries := make([]string, 0, 3)
-	if !d.Get(roleSkipReassignOwnedAttr).(bool) {
+	if reassignOwned {
 		if c.featureSupported(featureReassignOwnedCurrentUser) {
 			queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO CURRENT_USER", pq.QuoteIdentifier(roleName)))
 		} else {
 			queries = append(queries, fmt.Sprintf("REASSIGN OWNED BY %s TO %s", pq.QuoteIdentifier(roleName), pq.QuoteIdentifier(c.config.Username)))
 		}
+	}
+
+	if dropOwned {
 		queries = append(queries, fmt.Sprintf("DROP OWNED BY %s", pq.QuoteIdentifier(roleName)))
 	}
 
-	if !d.Get(roleSkipDropRoleAttr).(bool) {
+	if dropRole {
 		queries = append(queries, fmt.Sprintf("DROP ROLE %s", pq.QuoteIdentifier(roleName)))
 	}
 
-	if len(queries) > 0 {
-		for _, query := range queries {
-			if _, err := txn.Exec(query); err != nil {
-				return errwrap.Wrapf("Error deleting role: {{err}}", err)
+	return queries
+}
+
+// execRoleDropQueries runs the statements of roleDropQueries in txn.  When
+// objects still depend on the role, the error lists them.
+func execRoleDropQueries(ctx context.Context, c *Client, txn *sql.Tx, roleName string, queries []string) error {
+	for _, query := range queries {
+		logSQL(query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "dependent_objects_still_exist" {
+				return roleDependenciesError(c, txn, roleName, err)
 			}
+			return errwrap.Wrapf(fmt.Sprintf("Error deleting role %s: {{err}}", roleName), err)
 		}
+	}
+	return nil
+}
 
-		if err := txn.Commit(); err != nil {
-			return errwrap.Wrapf("Error committing schema: {{err}}", err)
+// roleDependenciesError builds an error listing the objects, in all the
+// databases, which still depend on a role that could not be dropped.  txn,
+// the aborted transaction of the DROP, is rolled back first.
+func roleDependenciesError(c *Client, txn *sql.Tx, roleName string, dropErr error) error {
+	// The transaction of the DROP is aborted, we have to query outside of it
+	// and release its connection first as the pool may only have one.
+	txn.Rollback()
+
+	query := `SELECT COALESCE(d.datname, ''), s.classid::regclass::text, s.deptype, count(*)
+		FROM pg_catalog.pg_shdepend s
+		JOIN pg_catalog.pg_roles r ON r.oid = s.refobjid AND s.refclassid = 'pg_catalog.pg_authid'::regclass
+		LEFT JOIN pg_catalog.pg_database d ON d.oid = s.dbid
+		WHERE r.rolname = $1
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3`
+	rows, err := c.DB().Query(query, roleName)
+	if err != nil {
+		log.Printf("[WARN] could not list the dependencies of role %s: %v", roleName, err)
+		return errwrap.Wrapf("Error deleting role: {{err}}", dropErr)
+	}
+	defer rows.Close()
+
+	dependencyTypes := map[string]string{
+		"o": "owner of",
+		"a": "privileges on",
+		"r": "policies on",
+	}
+
+	dependencies := []string{}
+	otherDatabases := false
+	for rows.Next() {
+		var dbName, catalog, depType string
+		var count int
+		if err := rows.Scan(&dbName, &catalog, &depType, &count); err != nil {
+			return errwrap.Wrapf("Error deleting role: {{err}}", dropErr)
+		}
+
+		depDesc, ok := dependencyTypes[depType]
+		if !ok {
+			depDesc = fmt.Sprintf("dependency (%s) on", depType)
+		}
+		dependency := fmt.Sprintf("%s %d object(s) of %s", depDesc, count, catalog)
+		if dbName != "" {
+			dependency += fmt.Sprintf(" in database %q", dbName)
+			if dbName != c.databaseName {
+				otherDatabases = true
+			}
 		}
+		dependencies = append(dependencies, dependency)
 	}
 
-	d.SetId("")
+	if len(dependencies) == 0 {
+		return errwrap.Wrapf("Error deleting role: {{err}}", dropErr)
+	}
 
-	return nil
+	hint := ""
+	if otherDatabases {
+		hint = fmt.Sprintf(" (REASSIGN OWNED and DROP OWNED only apply to database %q the provider is connected to, the objects of the other databases have to be reassigned or dropped there)", c.databaseName)
+	}
+
+	return fmt.Errorf(
+		"Error deleting role %q, some objects still depend on it%s:\n  - %s\n%s",
+		roleName, hint, strings.Join(dependencies, "\n  - "), dropErr,
+	)
 }
 
 func resourcePostgreSQLRoleExists(d *schema.ResourceData, meta interface{}) (bool, error) {
@@ -329,7 +623,9 @@ func resourcePostgreSQLRoleExists(d *schema.ResourceData, meta interface{}) (boo
 	err := c.DB().QueryRow("SELECT rolname FROM pg_catalog.pg_roles WHERE rolname=$1", d.Id()).Scan(&roleName)
 	switch {
 	case err == sql.ErrNoRows:
-		return false, nil
+		// The read follows the role if it has been renamed.
+		_, renamed, err := findRenamedRole(c, d)
+		return renamed, err
 	case err != nil:
 		return false, err
 	}
@@ -337,6 +633,32 @@ func resourcePostgreSQLRoleExists(d *schema.ResourceData, meta interface{}) (boo
 	return true, nil
 }
 
+// findRenamedRole returns the current name of the role whose OID is in the
+// state, when the role is not found by its name because it has been renamed
+// outside of Terraform.
+func findRenamedRole(c *Client, d *schema.ResourceData) (string, bool, error) {
+	roleOID := d.Get(roleOIDAttr).(int)
+	if roleOID == 0 {
+		return "", false, nil
+	}
+
+	query := "SELECT rolname FROM pg_catalog.pg_roles WHERE oid=$1"
+	if c.flavor == flavorRedshift {
+		query = "SELECT usename FROM pg_catalog.pg_user WHERE usesysid=$1"
+	}
+
+	var roleName string
+	err := c.DB().QueryRow(query, roleOID).Scan(&roleName)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", false, nil
+	case err != nil:
+		return "", false, errwrap.Wrapf(fmt.Sprintf("Error finding role with OID %d: {{err}}", roleOID), err)
+	}
+
+	return roleName, true, nil
+}
+
 func resourcePostgreSQLRoleRead(d *schema.ResourceData, meta interface{}) error {
 	c := meta.(*Client)
 	c.catalogLock.RLock()
@@ -345,49 +667,161 @@ func resourcePostgreSQLRoleRead(d *schema.ResourceData, meta interface{}) error
 	return resourcePostgreSQLRoleReadImpl(c, d)
 }
 
+// checkRoleAttributes checks the combinations of the role attributes before
+// they are applied.  The provider cannot validate them when planning, so the
+// combinations PostgreSQL would reject, or which contradict themselves, are
+// errors here while the merely unusual ones are logged as warnings.
+func checkRoleAttributes(d *schema.ResourceData) error {
+	for _, warning := range roleAttributesWarnings(d) {
+		log.Printf("[WARN] %s", warning)
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	if d.Get(roleGroupAttr).(bool) {
+		if d.Get(roleLoginAttr).(bool) {
+			return fmt.Errorf("role %s is a group role (%s is true) and cannot log in, remove %s", roleName, roleGroupAttr, roleLoginAttr)
+		}
+		if roleConfiguredPassword(d) != "" {
+			return fmt.Errorf("role %s is a group role (%s is true) and cannot have a password, remove %s", roleName, roleGroupAttr, rolePasswordAttr)
+		}
+	}
+	if rolePasswordNull(d) && roleConfiguredPassword(d) != "" {
+		return fmt.Errorf("role %s cannot have a password when %s is true, remove %s", roleName, rolePasswordNullAttr, rolePasswordAttr)
+	}
+
+	members := make(map[string]bool)
+	for _, member := range d.Get(roleMembersAttr).(*schema.Set).List() {
+		members[member.(string)] = true
+	}
+	if members[roleName] {
+		return fmt.Errorf("role %s cannot be a member of itself, remove it from %s", roleName, roleMembersAttr)
+	}
+
+	for _, membership := range roleMemberships(d) {
+		if membership.role == roleName {
+			return fmt.Errorf("role %s cannot be a member of itself, remove it from its granted roles", roleName)
+		}
+		if members[membership.role] {
+			return fmt.Errorf(
+				"role %s cannot be both granted to and a member of %s, which would create a circular membership",
+				membership.role, roleName,
+			)
+		}
+	}
+
+	return nil
+}
+
+// roleConfiguredPassword returns the password newly set in the configuration
+// of the role.  The password is computed, it keeps the one of the state when
+// it is not configured, so an unchanged password is ignored.
+func roleConfiguredPassword(d *schema.ResourceData) string {
+	if !d.HasChange(rolePasswordAttr) {
+		return ""
+	}
+	return d.Get(rolePasswordAttr).(string)
+}
+
+// roleAttributesWarnings returns the warnings about the role attributes which
+// are accepted by PostgreSQL but are ignored or redundant.
+func roleAttributesWarnings(d *schema.ResourceData) []string {
+	roleName := d.Get(roleNameAttr).(string)
+	var warnings []string
+
+	if !d.Get(roleLoginAttr).(bool) {
+		if roleConfiguredPassword(d) != "" {
+			warnings = append(warnings, fmt.Sprintf("role %s has a password but cannot log in (%s is false)", roleName, roleLoginAttr))
+		}
+		if d.Get(roleReplicationAttr).(bool) {
+			warnings = append(warnings, fmt.Sprintf("role %s has the %s attribute but cannot log in to start a replication (%s is false)", roleName, roleReplicationAttr, roleLoginAttr))
+		}
+		if d.Get(roleConnLimitAttr).(int) != -1 {
+			warnings = append(warnings, fmt.Sprintf("the %s of role %s is ignored as it cannot log in (%s is false)", roleConnLimitAttr, roleName, roleLoginAttr))
+		}
+	}
+
+	if d.Get(roleSuperuserAttr).(bool) {
+		for _, attr := range []string{roleCreateDBAttr, roleCreateRoleAttr, roleBypassRLSAttr} {
+			if d.Get(attr).(bool) {
+				warnings = append(warnings, fmt.Sprintf("the %s attribute of role %s is redundant as it is a superuser", attr, roleName))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// resourcePostgreSQLRoleImport imports a role by name.  The settings and the
+// memberships of a role are only read when they are managed, so they are
+// set here for the read to populate them.  The memberships are imported in the
+// membership attribute when one of them has options, in roles otherwise.
+func resourcePostgreSQLRoleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	roleName := d.Id()
+	d.Set(roleNameAttr, roleName)
+
+	settings, err := getRoleSettings(c.DB(), roleName)
+	if err != nil {
+		return nil, err
+	}
+	d.Set(roleSettingsAttr, settings)
+
+	if err := readRoleMemberships(c, d); err != nil {
+		return nil, err
+	}
+	withOptions := false
+	for _, raw := range d.Get(roleMembershipAttr).(*schema.Set).List() {
+		membership := raw.(map[string]interface{})
+		if membership[roleMembershipAdminAttr].(bool) || !membership[roleMembershipInheritAttr].(bool) || !membership[roleMembershipSetAttr].(bool) {
+			withOptions = true
+			break
+		}
+	}
+	if !withOptions {
+		d.Set(roleMembershipAttr, []interface{}{})
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
 func resourcePostgreSQLRoleReadImpl(c *Client, d *schema.ResourceData) error {
-	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin, roleReplication bool
-	var roleConnLimit int
+	var roleSuperuser, roleInherit, roleCreateRole, roleCreateDB, roleCanLogin bool
+	var roleOID, roleConnLimit int
 	var roleName, roleValidUntil string
 	var roleRoles pq.ByteaArray
 
 	roleID := d.Id()
 
-	columns := []string{
-		"rolname",
-		"rolsuper",
-		"rolinherit",
-		"rolcreaterole",
-		"rolcreatedb",
-		"rolcanlogin",
-		"rolreplication",
-		"rolconnlimit",
-		`COALESCE(rolvaliduntil::TEXT, 'infinity')`,
-	}
-
-	roleSQL := fmt.Sprintf(`SELECT %s, array_remove(array_agg(roles.role_name::text), NULL)
-		FROM pg_catalog.pg_roles LEFT JOIN information_schema.applicable_roles roles ON rolname = roles.grantee
-		WHERE rolname=$1
-		GROUP BY %s`,
-		// select columns
-		strings.Join(columns, ", "),
-		// group by columns
-		strings.Join(columns, ", "),
-	)
+	roleSQL := roleReadSQL(c)
 	err := c.DB().QueryRow(roleSQL, roleID).Scan(
+		&roleOID,
 		&roleName,
 		&roleSuperuser,
 		&roleInherit,
 		&roleCreateRole,
 		&roleCreateDB,
 		&roleCanLogin,
-		&roleReplication,
 		&roleConnLimit,
 		&roleValidUntil,
 		&roleRoles,
 	)
 	switch {
-	case err == sql.ErrNoRows:
+	case err == sql.ErrNoRows, pqErrorType(err) == ErrObjectNotFound:
+		newName, renamed, err := findRenamedRole(c, d)
+		if err != nil {
+			return err
+		}
+		if renamed {
+			// The configured name is then seen as a change and the role
+			// renamed back, rather than created again.
+			log.Printf("[WARN] PostgreSQL ROLE (%s) has been renamed to %s", roleID, newName)
+			d.SetId(newName)
+			return resourcePostgreSQLRoleReadImpl(c, d)
+		}
+
 		log.Printf("[WARN] PostgreSQL ROLE (%s) not found", roleID)
 		d.SetId("")
 		return nil
@@ -395,6 +829,16 @@ func resourcePostgreSQLRoleReadImpl(c *Client, d *schema.ResourceData) error {
 		return errwrap.Wrapf("Error reading ROLE: {{err}}", err)
 	}
 
+	if c.flavor == flavorRedshift {
+		// Redshift has none of these attributes, the configured ones are
+		// kept.
+		roleInherit = d.Get(roleInheritAttr).(bool)
+		roleCreateRole = d.Get(roleCreateRoleAttr).(bool)
+		roleCanLogin = d.Get(roleLoginAttr).(bool)
+		roleConnLimit = d.Get(roleConnLimitAttr).(int)
+	}
+
+	d.Set(roleOIDAttr, roleOID)
 	d.Set(roleNameAttr, roleName)
 	d.Set(roleConnLimitAttr, roleConnLimit)
 	d.Set(roleCreateDBAttr, roleCreateDB)
@@ -402,14 +846,52 @@ func resourcePostgreSQLRoleReadImpl(c *Client, d *schema.ResourceData) error {
 	d.Set(roleEncryptedPassAttr, true)
 	d.Set(roleInheritAttr, roleInherit)
 	d.Set(roleLoginAttr, roleCanLogin)
-	d.Set(roleReplicationAttr, roleReplication)
+	d.Set(roleGroupAttr, d.Get(roleGroupAttr).(bool))
 	d.Set(roleSkipDropRoleAttr, d.Get(roleSkipDropRoleAttr).(bool))
 	d.Set(roleSkipReassignOwnedAttr, d.Get(roleSkipReassignOwnedAttr).(bool))
+	d.Set(roleReassignOwnedAttr, d.Get(roleReassignOwnedAttr).(bool))
+	d.Set(roleDropOwnedAttr, d.Get(roleDropOwnedAttr).(bool))
+	d.Set(roleStorePasswordAttr, d.Get(roleStorePasswordAttr).(bool))
 	d.Set(roleSuperuserAttr, roleSuperuser)
 	d.Set(roleValidUntilAttr, roleValidUntil)
-	d.Set(roleRolesAttr, pgArrayToSet(roleRoles))
+	if d.Get(roleMembershipAttr).(*schema.Set).Len() > 0 {
+		if err := readRoleMemberships(c, d); err != nil {
+			return err
+		}
+	} else {
+		d.Set(roleRolesAttr, pgArrayToSet(roleRoles))
+	}
+
+	// Members are only read when managed, they may also be granted by the
+	// roles attribute of other roles.
+	if d.Get(roleMembersAttr).(*schema.Set).Len() > 0 {
+		members, err := getRoleMembers(c.DB(), roleID)
+		if err != nil {
+			return err
+		}
+		d.Set(roleMembersAttr, stringsToInterfaces(members))
+	}
+
+	// Settings are only read when managed, like members.
+	if len(d.Get(roleSettingsAttr).(map[string]interface{})) > 0 {
+		settings, err := getRoleSettings(c.DB(), roleID)
+		if err != nil {
+			return err
+		}
+		d.Set(roleSettingsAttr, settings)
+	}
 
-	if c.featureSupported(featureRLS) {
+	if c.featureSupported(featureRoleReplication) {
+		var roleReplication bool
+		roleSQL := "SELECT rolreplication FROM pg_catalog.pg_roles WHERE rolname=$1"
+		err = c.DB().QueryRow(roleSQL, roleID).Scan(&roleReplication)
+		if err != nil {
+			return errwrap.Wrapf("Error reading replication attribute of ROLE: {{err}}", err)
+		}
+		d.Set(roleReplicationAttr, roleReplication)
+	}
+
+	if c.featureSupported(featureRoleBypassRLS) {
 		var roleBypassRLS bool
 		roleSQL := "SELECT rolbypassrls FROM pg_catalog.pg_roles WHERE rolname=$1"
 		err = c.DB().QueryRow(roleSQL, roleID).Scan(&roleBypassRLS)
@@ -426,16 +908,142 @@ func resourcePostgreSQLRoleReadImpl(c *Client, d *schema.ResourceData) error {
 		return nil
 	}
 
+	if !d.Get(roleStorePasswordAttr).(bool) || c.flavor != flavorPostgreSQL {
+		// Keep the configured password, the password hash is never read.
+		// Redshift does not expose it in pg_authid, CockroachDB has no
+		// pg_authid.
+		return nil
+	}
+
+	rolePassword, ok, err := readRolePasswordHash(c, roleID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		d.Set(rolePasswordAttr, rolePassword)
+	}
+	return nil
+}
+
+// readRolePasswordHash reads the password hash of a role from pg_authid.
+// Only a superuser can read pg_authid (and pg_shadow, a view on it): when the
+// provider connects as another role, e.g. on managed services, false is
+// returned and the configured password is kept in the state.
+func readRolePasswordHash(c *Client, roleName string) (string, bool, error) {
+	var readable bool
+	if err := c.DB().QueryRow(
+		"SELECT has_table_privilege('pg_catalog.pg_authid', 'SELECT')",
+	).Scan(&readable); err != nil {
+		return "", false, errwrap.Wrapf("Error checking the privileges on pg_authid: {{err}}", err)
+	}
+	if !readable {
+		log.Printf("[WARN] the password hash of role %s is not read, reading pg_authid requires a superuser", roleName)
+		return "", false, nil
+	}
+
 	var rolePassword string
-	err = c.DB().QueryRow("SELECT COALESCE(passwd, '') FROM pg_catalog.pg_shadow AS s WHERE s.usename = $1", roleID).Scan(&rolePassword)
+	err := c.DB().QueryRow(
+		"SELECT COALESCE(rolpassword, '') FROM pg_catalog.pg_authid WHERE rolname = $1", roleName,
+	).Scan(&rolePassword)
 	switch {
 	case err == sql.ErrNoRows:
-		return errwrap.Wrapf(fmt.Sprintf("PostgreSQL role (%s) not found in shadow database: {{err}}", roleID), err)
+		return "", false, errwrap.Wrapf(fmt.Sprintf("PostgreSQL role (%s) not found in pg_authid: {{err}}", roleName), err)
 	case err != nil:
-		return errwrap.Wrapf("Error reading role: {{err}}", err)
+		return "", false, errwrap.Wrapf(fmt.Sprintf("Error reading password of role %s: {{err}}", roleName), err)
+	}
+
+	return rolePassword, true, nil
+}
+
+// roleReadSQL returns the query reading the attributes of a role and the
+// roles it is a member of.  Redshift has no pg_roles nor applicable_roles:
+// its users are read from pg_user and their groups from pg_group, with
+// constants for the attributes it does not have.
+func roleReadSQL(c *Client) string {
+	if c.flavor == flavorRedshift {
+		return `SELECT usesysid, usename, usesuper, true, false, usecreatedb, true, -1, ` +
+			`COALESCE(valuntil::TEXT, '` + roleValidUntilNull + `'), ` +
+			`ARRAY(SELECT groname::text FROM pg_catalog.pg_group WHERE usesysid = ANY(grolist)) ` +
+			`FROM pg_catalog.pg_user WHERE usename=$1`
+	}
+
+	columns := []string{
+		"oid",
+		"rolname",
+		"rolsuper",
+		"rolinherit",
+		"rolcreaterole",
+		"rolcreatedb",
+		"rolcanlogin",
+		"rolconnlimit",
+		`COALESCE(rolvaliduntil::TEXT, '` + roleValidUntilNull + `')`,
+	}
+
+	return fmt.Sprintf(`SELECT %s, array_remove(array_agg(roles.role_name::text), NULL)
+		FROM pg_catalog.pg_roles LEFT JOIN information_schema.applicable_roles roles ON rolname = roles.grantee
+		WHERE rolname=$1
+		GROUP BY %s`,
+		// select columns
+		strings.Join(columns, ", "),
+		// group by columns
+		strings.Join(columns, ", "),
+	)
+}
+
+// readRoleMemberships reads the roles directly granted to the role with the
+// options of the memberships.
+func readRoleMemberships(c *Client, d *schema.ResourceData) error {
+	roleName := d.Get(roleNameAttr).(string)
+
+	// Before PostgreSQL 16, the INHERIT and SET options do not exist and
+	// are kept as configured.
+	configured := make(map[string]map[string]interface{})
+	for _, raw := range d.Get(roleMembershipAttr).(*schema.Set).List() {
+		membership := raw.(map[string]interface{})
+		configured[membership[roleMembershipRoleAttr].(string)] = membership
+	}
+
+	options := "true, true"
+	if c.featureSupported(featureMembershipOptions) {
+		options = "m.inherit_option, m.set_option"
+	}
+	query := fmt.Sprintf(`SELECT r.rolname, m.admin_option, %s `+
+		`FROM pg_catalog.pg_auth_members m `+
+		`JOIN pg_catalog.pg_roles r ON r.oid = m.roleid `+
+		`JOIN pg_catalog.pg_roles u ON u.oid = m.member `+
+		`WHERE u.rolname = $1`, options)
+	rows, err := c.DB().Query(query, roleName)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not read memberships of role %s: {{err}}", roleName), err)
+	}
+	defer rows.Close()
+
+	memberships := []interface{}{}
+	for rows.Next() {
+		var grantedRole string
+		var admin, inherit, set bool
+		if err := rows.Scan(&grantedRole, &admin, &inherit, &set); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not scan membership of role %s: {{err}}", roleName), err)
+		}
+
+		if membership, ok := configured[grantedRole]; ok && !c.featureSupported(featureMembershipOptions) {
+			inherit = membership[roleMembershipInheritAttr].(bool)
+			set = membership[roleMembershipSetAttr].(bool)
+		}
+
+		memberships = append(memberships, map[string]interface{}{
+			roleMembershipRoleAttr:    grantedRole,
+			roleMembershipInheritAttr: inherit,
+			roleMembershipSetAttr:     set,
+			roleMembershipAdminAttr:   admin,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("could not read memberships of role %s: {{err}}", roleName), err)
 	}
 
-	d.Set(rolePasswordAttr, rolePassword)
+	d.Set(roleMembershipAttr, memberships)
+
 	return nil
 }
 
@@ -444,69 +1052,103 @@ func resourcePostgreSQLRoleUpdate(d *schema.ResourceData, meta interface{}) erro
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
-	if err != nil {
+	if err := checkRoleAttributes(d); err != nil {
 		return err
 	}
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
+
+	d.Set(roleSQLStatementsAttr, []string{})
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
 	defer txn.Rollback()
 
-	if err := setRoleName(txn, d); err != nil {
-		return err
+	if err := setRoleName(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleBypassRLS(c, txn, d); err != nil {
-		return err
+	if err := setRoleBypassRLS(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleConnLimit(txn, d); err != nil {
-		return err
+	if err := setRoleConnLimit(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleCreateDB(txn, d); err != nil {
-		return err
+	if err := setRoleCreateDB(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleCreateRole(txn, d); err != nil {
-		return err
+	// Redshift users always log in and have no INHERIT and CREATEROLE
+	// attributes.
+	if c.flavor != flavorRedshift {
+		if err := setRoleCreateRole(ctx, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutUpdate, err)
+		}
+
+		if err := setRoleInherit(ctx, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutUpdate, err)
+		}
+
+		if err := setRoleLogin(ctx, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutUpdate, err)
+		}
 	}
 
-	if err := setRoleInherit(txn, d); err != nil {
-		return err
+	if err := setRoleReplication(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleLogin(txn, d); err != nil {
-		return err
+	if err := setRoleSuperuser(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleReplication(txn, d); err != nil {
-		return err
+	if err := setRoleValidUntil(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleSuperuser(txn, d); err != nil {
-		return err
+	if err := setRolePassword(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err := setRoleValidUntil(txn, d); err != nil {
-		return err
+	// applying roles: let's revoke the removed ones / grant the missing ones
+	if err = revokeRoles(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	// applying roles: let's revoke all / grant the right ones
-	if err = revokeRoles(txn, d); err != nil {
-		return err
+	if err = grantRoles(ctx, c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
-	if err = grantRoles(txn, d); err != nil {
-		return err
+	// applying members: same in the other direction
+	if err = revokeMembers(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err = grantMembers(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err = setRoleSettings(ctx, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
 	if err = txn.Commit(); err != nil {
-		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+		return operationError(ctx, schema.TimeoutUpdate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
 	}
 
+	// The ID only follows a rename once it is committed, so that a retried
+	// or failed update still refers to the role by its current name.
+	d.SetId(d.Get(roleNameAttr).(string))
+
 	return resourcePostgreSQLRoleReadImpl(c, d)
 }
 
-func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleName(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleNameAttr) {
 		return nil
 	}
@@ -519,21 +1161,20 @@ func setRoleName(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 
 	sql := fmt.Sprintf("ALTER ROLE %s RENAME TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role NAME: {{err}}", err)
 	}
 
-	d.SetId(n)
-
 	return nil
 }
 
-func setRoleBypassRLS(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleBypassRLS(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleBypassRLSAttr) {
 		return nil
 	}
 
-	if !c.featureSupported(featureRLS) {
+	if !c.featureSupported(featureRoleBypassRLS) {
 		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support PostgreSQL Row-Level Security", c.version.String())
 	}
 
@@ -544,14 +1185,15 @@ func setRoleBypassRLS(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role BYPASSRLS: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleConnLimit(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleConnLimitAttr) {
 		return nil
 	}
@@ -559,14 +1201,15 @@ func setRoleConnLimit(txn *sql.Tx, d *schema.ResourceData) error {
 	connLimit := d.Get(roleConnLimitAttr).(int)
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d", pq.QuoteIdentifier(roleName), connLimit)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role CONNECTION LIMIT: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleCreateDB(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleCreateDBAttr) {
 		return nil
 	}
@@ -578,14 +1221,15 @@ func setRoleCreateDB(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role CREATEDB: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleCreateRole(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleCreateRoleAttr) {
 		return nil
 	}
@@ -597,14 +1241,15 @@ func setRoleCreateRole(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role CREATEROLE: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleInherit(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleInheritAttr) {
 		return nil
 	}
@@ -616,37 +1261,46 @@ func setRoleInherit(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role INHERIT: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleLogin(txn *sql.Tx, d *schema.ResourceData) error {
-	if !d.HasChange(roleLoginAttr) {
+func setRoleLogin(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	// Turning the role into a group role revokes its LOGIN, in case it was
+	// granted out of band.
+	group := d.Get(roleGroupAttr).(bool)
+	if !d.HasChange(roleLoginAttr) && !(group && d.HasChange(roleGroupAttr)) {
 		return nil
 	}
 
-	login := d.Get(roleLoginAttr).(bool)
+	login := d.Get(roleLoginAttr).(bool) && !group
 	tok := "NOLOGIN"
 	if login {
 		tok = "LOGIN"
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role LOGIN: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleReplication(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleReplicationAttr) {
 		return nil
 	}
 
+	if !c.featureSupported(featureRoleReplication) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support the REPLICATION role attribute", c.version.String())
+	}
+
 	replication := d.Get(roleReplicationAttr).(bool)
 	tok := "NOREPLICATION"
 	if replication {
@@ -654,14 +1308,15 @@ func setRoleReplication(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role REPLICATION: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleSuperuser(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleSuperuserAttr) {
 		return nil
 	}
@@ -673,14 +1328,15 @@ func setRoleSuperuser(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 	roleName := d.Get(roleNameAttr).(string)
 	sql := fmt.Sprintf("ALTER ROLE %s WITH %s", pq.QuoteIdentifier(roleName), tok)
-	if _, err := txn.Exec(sql); err != nil {
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role SUPERUSER: {{err}}", err)
 	}
 
 	return nil
 }
 
-func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
+func setRoleValidUntil(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	if !d.HasChange(roleValidUntilAttr) {
 		return nil
 	}
@@ -688,24 +1344,212 @@ func setRoleValidUntil(txn *sql.Tx, d *schema.ResourceData) error {
 	validUntil := d.Get(roleValidUntilAttr).(string)
 	if validUntil == "" {
 		return nil
-	} else if strings.ToLower(validUntil) == "infinity" {
-		validUntil = "infinity"
+	}
+
+	validUntil, err := roleValidUntilSQL(ctx, txn, validUntil)
+	if err != nil {
+		return err
 	}
 
 	roleName := d.Get(roleNameAttr).(string)
-	sql := fmt.Sprintf("ALTER ROLE %s VALID UNTIL '%s'", pq.QuoteIdentifier(roleName), pqQuoteLiteral(validUntil))
-	if _, err := txn.Exec(sql); err != nil {
+	sql := fmt.Sprintf("ALTER ROLE %s VALID UNTIL %s", pq.QuoteIdentifier(roleName), validUntil)
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
 		return errwrap.Wrapf("Error updating role VALID UNTIL: {{err}}", err)
 	}
 
+	d.Set(roleValidUntilDurAttr, roleValidUntilDuration(d.Get(roleValidUntilAttr).(string)))
+
+	return nil
+}
+
+// roleValidUntilNull is the value of valid_until which unsets the expiration
+// of the password (rolvaliduntil is NULL), as opposed to infinity.
+const roleValidUntilNull = "NULL"
+
+// isRoleValidUntilNull returns whether validUntil unsets the expiration of the
+// password.
+func isRoleValidUntilNull(validUntil string) bool {
+	return strings.ToUpper(validUntil) == roleValidUntilNull
+}
+
+// roleValidUntilDurationRe matches the durations from now accepted by
+// valid_until, e.g. +90d.
+var roleValidUntilDurationRe = regexp.MustCompile(`^\+([0-9]+)([smhdw])$`)
+
+// roleValidUntilUnits maps the units of the valid_until durations to
+// their PostgreSQL interval counterpart.
+var roleValidUntilUnits = map[string]string{
+	"s": "seconds",
+	"m": "minutes",
+	"h": "hours",
+	"d": "days",
+	"w": "weeks",
+}
+
+// parseRoleValidUntilDuration returns the PostgreSQL interval of a valid_until
+// duration and whether validUntil is a duration.
+func parseRoleValidUntilDuration(validUntil string) (string, bool) {
+	m := roleValidUntilDurationRe.FindStringSubmatch(validUntil)
+	if m == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s", m[1], roleValidUntilUnits[m[2]]), true
+}
+
+// roleValidUntilDuration returns validUntil if it is a duration, otherwise an
+// empty string.
+func roleValidUntilDuration(validUntil string) string {
+	if _, ok := parseRoleValidUntilDuration(validUntil); ok {
+		return validUntil
+	}
+	return ""
+}
+
+// resolveRoleValidUntil returns the absolute value of valid_until, resolving
+// the durations from the current time of the server.
+func resolveRoleValidUntil(ctx context.Context, txn *sql.Tx, validUntil string) (string, error) {
+	if strings.ToLower(validUntil) == "infinity" {
+		return "infinity", nil
+	}
+
+	interval, ok := parseRoleValidUntilDuration(validUntil)
+	if !ok {
+		return validUntil, nil
+	}
+
+	var resolved string
+	if err := txn.QueryRowContext(ctx, "SELECT (now() + $1::interval)::text", interval).Scan(&resolved); err != nil {
+		return "", errwrap.Wrapf(fmt.Sprintf("could not resolve VALID UNTIL %s: {{err}}", validUntil), err)
+	}
+	return resolved, nil
+}
+
+// roleValidUntilSQL returns the value of valid_until ready to be used in a
+// VALID UNTIL clause.
+func roleValidUntilSQL(ctx context.Context, txn *sql.Tx, validUntil string) (string, error) {
+	if isRoleValidUntilNull(validUntil) {
+		return roleValidUntilNull, nil
+	}
+
+	validUntil, err := resolveRoleValidUntil(ctx, txn, validUntil)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("'%s'", pqQuoteLiteral(validUntil)), nil
+}
+
+func validateRoleValidUntil(v interface{}, key string) (warnings []string, errors []error) {
+	value := v.(string)
+	if strings.HasPrefix(value, "+") {
+		if _, ok := parseRoleValidUntilDuration(value); !ok {
+			errors = append(errors, fmt.Errorf("%s duration must be a number followed by one of the units s, m, h, d or w (e.g. +90d), got: %s", key, value))
+		}
+	}
+	return
+}
+
+// suppressRoleValidUntilDiff suppresses the diff between a duration and the
+// timestamp it has been resolved to, so the role does not expire later at
+// every apply.
+func suppressRoleValidUntilDiff(k, old, new string, d *schema.ResourceData) bool {
+	return roleValidUntilUnchanged(old, new, d.Get(roleValidUntilDurAttr).(string))
+}
+
+// roleValidUntilUnchanged returns whether the configured valid_until, new, is
+// the same as the one of the state, old, duration being the duration old has
+// been resolved from.  A duration is only unchanged if old has been resolved
+// from the same duration.
+func roleValidUntilUnchanged(old, new, duration string) bool {
+	if isRoleValidUntilNull(old) {
+		// An unset expiration, the default of PostgreSQL, never expires like
+		// the infinity default of valid_until.
+		return isRoleValidUntilNull(new) || strings.ToLower(new) == "infinity"
+	}
+	if _, ok := parseRoleValidUntilDuration(new); !ok {
+		return false
+	}
+	return new == duration && old != "" && strings.ToLower(old) != "infinity"
+}
+
+func setRolePassword(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(rolePasswordAttr) && !d.HasChange(rolePasswordNullAttr) {
+		return nil
+	}
+
+	password := d.Get(rolePasswordAttr).(string)
+	if password == "" && !rolePasswordNull(d) {
+		// Roles without a password explicitly set are left alone.
+		return nil
+	}
+
+	roleName := d.Get(roleNameAttr).(string)
+	sql := fmt.Sprintf("ALTER ROLE %s %s", pq.QuoteIdentifier(roleName), strings.Join(rolePasswordOpts(d, password), " "))
+	recordSQL(d, roleSQLStatementsAttr, sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return errwrap.Wrapf("Error updating role password: {{err}}", redactError(err, password))
+	}
+
 	return nil
 }
 
-func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
+// rolePasswordOpts returns the CREATE/ALTER ROLE options setting the role's
+// password to the given value, or clearing it if requested.
+func rolePasswordOpts(d *schema.ResourceData, password string) []string {
+	if rolePasswordNull(d) {
+		return []string{"PASSWORD NULL"}
+	}
+
+	// The legacy sentinel is only honored when password_null is left unset,
+	// password_null = false takes the password literally.
+	if strings.ToUpper(password) == roleDepPasswordNull && d.Get(rolePasswordNullAttr).(string) == "" {
+		log.Printf("[WARN] Setting the PostgreSQL role %q password to %q is deprecated, use %q instead", d.Get(roleNameAttr).(string), password, rolePasswordNullAttr)
+		return []string{"PASSWORD NULL"}
+	}
+
+	encrypted := "UNENCRYPTED"
+	if d.Get(roleEncryptedPassAttr).(bool) {
+		encrypted = "ENCRYPTED"
+	}
+
+	return []string{encrypted, fmt.Sprintf("PASSWORD '%s'", pqQuoteLiteral(password))}
+}
+
+// rolePasswordNull returns true if password_null is set to true.  It is a
+// string attribute, empty when unset, as the SDK cannot tell an unset bool
+// from false.
+func rolePasswordNull(d *schema.ResourceData) bool {
+	null, _ := strconv.ParseBool(d.Get(rolePasswordNullAttr).(string))
+	return null
+}
+
+func validateRolePassword(v interface{}, key string) (warnings []string, errors []error) {
+	if strings.ToUpper(v.(string)) == roleDepPasswordNull {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: the %q value is deprecated, set %q to true to clear the password, or to false to use %q as the password",
+			key, roleDepPasswordNull, rolePasswordNullAttr, v.(string),
+		))
+	}
+	return
+}
+
+func revokeRoles(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
-	query := "SELECT role_name FROM information_schema.applicable_roles WHERE grantee = $1"
-	rows, err := txn.Query(query, role)
+	// The memberships whose options changed are revoked to be granted again.
+	keptRoles := make(map[string]bool)
+	for _, grantedRole := range d.Get(roleRolesAttr).(*schema.Set).List() {
+		keptRoles[grantedRole.(string)] = true
+	}
+	oldMemberships, newMemberships := d.GetChange(roleMembershipAttr)
+	for _, membership := range newMemberships.(*schema.Set).List() {
+		if oldMemberships.(*schema.Set).Contains(membership) {
+			keptRoles[membership.(map[string]interface{})[roleMembershipRoleAttr].(string)] = true
+		}
+	}
+
+	query := "SELECT role_name FROM information_schema.applicable_roles WHERE grantee = $1 ORDER BY role_name"
+	rows, err := txn.QueryContext(ctx, query, role)
 	if err != nil {
 		return errwrap.Wrapf(fmt.Sprintf("could not get roles list for role %s: {{err}}", role), err)
 	}
@@ -725,10 +1569,15 @@ func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
 	}
 
 	for _, grantedRole := range grantedRoles {
+		if keptRoles[grantedRole] {
+			continue
+		}
+
 		query = fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(grantedRole), pq.QuoteIdentifier(role))
 
 		log.Printf("[DEBUG] revoking role %s from %s", grantedRole, role)
-		if _, err := txn.Exec(query); err != nil {
+		recordSQL(d, roleSQLStatementsAttr, query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
 			return errwrap.Wrapf(fmt.Sprintf("could not revoke role %s from %s: {{err}}", string(grantedRole), role), err)
 		}
 	}
@@ -736,16 +1585,448 @@ func revokeRoles(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
-func grantRoles(txn *sql.Tx, d *schema.ResourceData) error {
+// roleMembership is a role to grant to the role with the options of the
+// membership.
+type roleMembership struct {
+	role    string
+	inherit bool
+	set     bool
+	admin   bool
+
+	// withOptions is false for the roles attribute, granted with the
+	// server defaults.
+	withOptions bool
+}
+
+// roleMemberships returns the memberships of the role, either from the
+// roles or the membership attribute.
+func roleMemberships(d *schema.ResourceData) []roleMembership {
+	memberships := []roleMembership{}
+	for _, grantedRole := range d.Get(roleRolesAttr).(*schema.Set).List() {
+		memberships = append(memberships, roleMembership{role: grantedRole.(string)})
+	}
+	for _, raw := range d.Get(roleMembershipAttr).(*schema.Set).List() {
+		membership := raw.(map[string]interface{})
+		memberships = append(memberships, roleMembership{
+			role:    membership[roleMembershipRoleAttr].(string),
+			inherit: membership[roleMembershipInheritAttr].(bool),
+			set:     membership[roleMembershipSetAttr].(bool),
+			admin:   membership[roleMembershipAdminAttr].(bool),
+
+			withOptions: true,
+		})
+	}
+	return memberships
+}
+
+// membershipOptions returns the WITH clause of the GRANT statement of the
+// membership, empty if the defaults are used.
+func membershipOptions(c *Client, membership roleMembership) string {
+	if !membership.withOptions {
+		return ""
+	}
+
+	if c.featureSupported(featureMembershipOptions) {
+		return fmt.Sprintf(
+			" WITH ADMIN %s, INHERIT %s, SET %s",
+			strings.ToUpper(strconv.FormatBool(membership.admin)),
+			strings.ToUpper(strconv.FormatBool(membership.inherit)),
+			strings.ToUpper(strconv.FormatBool(membership.set)),
+		)
+	}
+
+	if !membership.inherit || !membership.set {
+		log.Printf("[WARN] PostgreSQL server (%q) does not support the INHERIT and SET membership options, ignoring them for role %s", c.version.String(), membership.role)
+	}
+	if membership.admin {
+		return " WITH ADMIN OPTION"
+	}
+	return ""
+}
+
+func grantRoles(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData) error {
 	role := d.Get(roleNameAttr).(string)
 
-	for _, grantingRole := range d.Get("roles").(*schema.Set).List() {
+	for _, membership := range roleMemberships(d) {
+		grantingRole := membership.role
+
+		isMember, err := isRoleDirectMember(ctx, txn, grantingRole, role)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			log.Printf("[DEBUG] role %s is already a member of %s", role, grantingRole)
+			continue
+		}
+
+		// PostgreSQL refuses to create a circular membership, check it before
+		// to return a clearer error message.
+		isMember, err = isRoleMember(ctx, txn, role, grantingRole)
+		if err != nil {
+			return err
+		}
+		if isMember || role == grantingRole {
+			return fmt.Errorf(
+				"could not grant role %s to %s: %s is already a member of %s, which would create a circular membership",
+				grantingRole, role, grantingRole, role,
+			)
+		}
+
 		query := fmt.Sprintf(
-			"GRANT %s TO %s", pq.QuoteIdentifier(grantingRole.(string)), pq.QuoteIdentifier(role),
+			"GRANT %s TO %s%s", pq.QuoteIdentifier(grantingRole), pq.QuoteIdentifier(role), membershipOptions(c, membership),
 		)
-		if _, err := txn.Exec(query); err != nil {
+		recordSQL(d, roleSQLStatementsAttr, query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
 			return errwrap.Wrapf(fmt.Sprintf("could not grant role %s to %s: {{err}}", grantingRole, role), err)
 		}
 	}
 	return nil
 }
+
+// getRoleMembers returns the roles which have been directly granted role.
+func getRoleMembers(db *sql.DB, role string) ([]string, error) {
+	query := `SELECT u.rolname FROM pg_catalog.pg_auth_members m ` +
+		`JOIN pg_catalog.pg_roles r ON r.oid = m.roleid ` +
+		`JOIN pg_catalog.pg_roles u ON u.oid = m.member ` +
+		`WHERE r.rolname = $1`
+	rows, err := db.Query(query, role)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("could not get members of role %s: {{err}}", role), err)
+	}
+	defer rows.Close()
+
+	members := []string{}
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("could not scan member of role %s: {{err}}", role), err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("could not get members of role %s: {{err}}", role), err)
+	}
+	return members, nil
+}
+
+func revokeMembers(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleMembersAttr) {
+		return nil
+	}
+
+	role := d.Get(roleNameAttr).(string)
+	wantedMembers := d.Get(roleMembersAttr).(*schema.Set)
+
+	// Only the members removed from the configuration are revoked.
+	o, _ := d.GetChange(roleMembersAttr)
+	for _, member := range o.(*schema.Set).Difference(wantedMembers).List() {
+		query := fmt.Sprintf("REVOKE %s FROM %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member.(string)))
+
+		log.Printf("[DEBUG] revoking role %s from %s", role, member)
+		recordSQL(d, roleSQLStatementsAttr, query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not revoke role %s from %s: {{err}}", role, member), err)
+		}
+	}
+
+	return nil
+}
+
+func grantMembers(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	role := d.Get(roleNameAttr).(string)
+
+	for _, raw := range d.Get(roleMembersAttr).(*schema.Set).List() {
+		member := raw.(string)
+
+		isMember, err := isRoleDirectMember(ctx, txn, role, member)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			log.Printf("[DEBUG] role %s is already a member of %s", member, role)
+			continue
+		}
+
+		isMember, err = isRoleMember(ctx, txn, member, role)
+		if err != nil {
+			return err
+		}
+		if isMember || role == member {
+			return fmt.Errorf(
+				"could not grant role %s to %s: %s is already a member of %s, which would create a circular membership",
+				role, member, role, member,
+			)
+		}
+
+		query := fmt.Sprintf("GRANT %s TO %s", pq.QuoteIdentifier(role), pq.QuoteIdentifier(member))
+		recordSQL(d, roleSQLStatementsAttr, query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not grant role %s to %s: {{err}}", role, member), err)
+		}
+	}
+	return nil
+}
+
+// isRoleDirectMember returns whether member has been granted role.
+func isRoleDirectMember(ctx context.Context, txn *sql.Tx, role, member string) (bool, error) {
+	var isMember bool
+	query := `SELECT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_auth_members m
+			JOIN pg_catalog.pg_roles r ON r.oid = m.roleid
+			JOIN pg_catalog.pg_roles u ON u.oid = m.member
+			WHERE r.rolname = $1 AND u.rolname = $2
+		)`
+	if err := txn.QueryRowContext(ctx, query, role, member).Scan(&isMember); err != nil {
+		return false, errwrap.Wrapf(fmt.Sprintf("could not check if %s is a member of %s: {{err}}", member, role), err)
+	}
+	return isMember, nil
+}
+
+// getRoleSettings returns the run-time parameters set for the role in all the
+// databases.
+func getRoleSettings(db *sql.DB, role string) (map[string]interface{}, error) {
+	var config []string
+	query := "SELECT COALESCE(rolconfig, '{}'::text[]) FROM pg_catalog.pg_roles WHERE rolname = $1"
+	if err := db.QueryRow(query, role).Scan(pq.Array(&config)); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("could not read settings of role %s: {{err}}", role), err)
+	}
+
+	settings := make(map[string]interface{}, len(config))
+	for _, setting := range config {
+		parts := strings.SplitN(setting, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("could not parse setting %q of role %s", setting, role)
+		}
+		settings[parts[0]] = parts[1]
+	}
+
+	return settings, nil
+}
+
+// setRoleSettings sets the changed run-time parameters of the role and resets
+// the removed ones.  When all of them are removed, they are reset at once with
+// RESET ALL, which also clears the ones set outside of Terraform.
+func setRoleSettings(ctx context.Context, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(roleSettingsAttr) {
+		return nil
+	}
+
+	role := d.Get(roleNameAttr).(string)
+	o, n := d.GetChange(roleSettingsAttr)
+	oldSettings := o.(map[string]interface{})
+	newSettings := n.(map[string]interface{})
+
+	queries := []string{}
+	if len(newSettings) == 0 {
+		queries = append(queries, fmt.Sprintf("ALTER ROLE %s RESET ALL", pq.QuoteIdentifier(role)))
+	} else {
+		// The names are safe to use unquoted, see validateRoleSettings.
+		for _, name := range sortedKeys(oldSettings) {
+			if _, ok := newSettings[name]; !ok {
+				queries = append(queries, fmt.Sprintf("ALTER ROLE %s RESET %s", pq.QuoteIdentifier(role), name))
+			}
+		}
+		for _, name := range sortedKeys(newSettings) {
+			value := newSettings[name]
+			if oldValue, ok := oldSettings[name]; ok && oldValue == value {
+				continue
+			}
+			queries = append(queries, fmt.Sprintf(
+				"ALTER ROLE %s SET %s = %s", pq.QuoteIdentifier(role), name, roleSettingValue(name, value.(string)),
+			))
+		}
+	}
+
+	for _, query := range queries {
+		recordSQL(d, roleSQLStatementsAttr, query)
+		if _, err := txn.ExecContext(ctx, query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("could not update settings of role %s: {{err}}", role), err)
+		}
+	}
+
+	return nil
+}
+
+// roleListSettings are the run-time parameters taking a list of names.  Set
+// as a single string literal, their value would be one element, e.g. a
+// search_path made of one schema named "app, public".
+var roleListSettings = map[string]bool{
+	"local_preload_libraries":   true,
+	"search_path":               true,
+	"session_preload_libraries": true,
+	"temp_tablespaces":          true,
+}
+
+// roleSettingListElementRe matches an element of a list run-time parameter as
+// PostgreSQL writes it back in rolconfig: a lower case name, or a quoted one.
+var roleSettingListElementRe = regexp.MustCompile(`^([a-z_][a-z0-9_]*|"([^"]|"")+")$`)
+
+// roleSettingValue returns the SQL of the value of a role setting.  The value
+// of a list parameter is used as is, see validateRoleSettings.
+func roleSettingValue(name, value string) string {
+	if roleListSettings[name] {
+		return value
+	}
+	return fmt.Sprintf("'%s'", pqQuoteLiteral(value))
+}
+
+// splitRoleSettingList splits the value of a list run-time parameter on the
+// commas which are not in a quoted name.
+func splitRoleSettingList(value string) []string {
+	var elements []string
+	quoted := false
+	start := 0
+	for i, c := range value {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			elements = append(elements, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(elements, value[start:])
+}
+
+// validateRoleSettings checks that the names of the role settings are names
+// of run-time parameters, which are used unquoted in ALTER ROLE.  The values of
+// the list parameters are used unquoted too, so they must be lists of names
+// written as PostgreSQL reads them back, e.g. "$user", public.
+func validateRoleSettings(v interface{}, key string) (warnings []string, errors []error) {
+	for name, value := range v.(map[string]interface{}) {
+		if !settingNameRe.MatchString(name) {
+			errors = append(errors, fmt.Errorf("%s must only contain lower case names of run-time parameters, got: %q", key, name))
+			continue
+		}
+		if !roleListSettings[name] {
+			continue
+		}
+
+		elements := splitRoleSettingList(value.(string))
+		for i, element := range elements {
+			if i > 0 {
+				if !strings.HasPrefix(element, " ") {
+					errors = append(errors, fmt.Errorf("%s: the names of %s must be separated by \", \", got: %q", key, name, value))
+					break
+				}
+				element = element[1:]
+			}
+			if !roleSettingListElementRe.MatchString(element) {
+				errors = append(errors, fmt.Errorf("%s: %s must be a list of lower case or quoted names, got: %q", key, name, value))
+				break
+			}
+		}
+	}
+	return
+}
+
+// isRoleMember returns whether member is a member of role, either directly or
+// through other roles.
+func isRoleMember(ctx context.Context, txn *sql.Tx, role, member string) (bool, error) {
+	var isMember bool
+	query := `WITH RECURSIVE members(oid) AS (
+			SELECT m.member FROM pg_catalog.pg_auth_members m
+			JOIN pg_catalog.pg_roles r ON r.oid = m.roleid
+			WHERE r.rolname = $1
+			UNION
+			SELECT m.member FROM pg_catalog.pg_auth_members m
+			JOIN members ON m.roleid = members.oid
+		)
+		SELECT EXISTS (
+			SELECT 1 FROM members JOIN pg_catalog.pg_roles r ON r.oid = members.oid
+			WHERE r.rolname = $2
+		)`
+	if err := txn.QueryRowContext(ctx, query, role, member).Scan(&isMember); err != nil {
+		return false, errwrap.Wrapf(fmt.Sprintf("could not check if %s is a member of %s: {{err}}", member, role), err)
+	}
+	return isMember, nil
+}
+
+// suppressRolePasswordHashDiff suppresses the diff between the configured
+// password and its md5 or SCRAM-SHA-256 hash read from the database.
+func suppressRolePasswordHashDiff(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	return passwordMatchesHash(d.Get(roleNameAttr).(string), new, old)
+}
+
+// passwordMatchesHash returns true if hash is the md5 or SCRAM-SHA-256 hash
+// stored by PostgreSQL for the given role and password.
+func passwordMatchesHash(role, password, hash string) bool {
+	switch {
+	case strings.HasPrefix(hash, "md5"):
+		sum := md5.Sum([]byte(password + role))
+		return subtle.ConstantTimeCompare([]byte(hash), []byte("md5"+hex.EncodeToString(sum[:]))) == 1
+	case strings.HasPrefix(hash, "SCRAM-SHA-256$"):
+		return scramPasswordMatches(password, hash)
+	default:
+		return false
+	}
+}
+
+// scramPasswordMatches checks password against a SCRAM-SHA-256 hash in the
+// format SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey> (RFC 5803).
+func scramPasswordMatches(password, hash string) bool {
+	parts := strings.Split(strings.TrimPrefix(hash, "SCRAM-SHA-256$"), "$")
+	if len(parts) != 2 {
+		return false
+	}
+
+	iterSalt := strings.SplitN(parts[0], ":", 2)
+	keys := strings.SplitN(parts[1], ":", 2)
+	if len(iterSalt) != 2 || len(keys) != 2 {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(iterSalt[0])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(iterSalt[1])
+	if err != nil {
+		return false
+	}
+	storedKey, err := base64.StdEncoding.DecodeString(keys[0])
+	if err != nil {
+		return false
+	}
+	serverKey, err := base64.StdEncoding.DecodeString(keys[1])
+	if err != nil {
+		return false
+	}
+
+	saltedPassword := pbkdf2SHA256([]byte(password), salt, iterations)
+	clientKey := hmacSHA256(saltedPassword, []byte("Client Key"))
+	computedStoredKey := sha256.Sum256(clientKey)
+
+	return subtle.ConstantTimeCompare(storedKey, computedStoredKey[:]) == 1 &&
+		subtle.ConstantTimeCompare(serverKey, hmacSHA256(saltedPassword, []byte("Server Key"))) == 1
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// pbkdf2SHA256 derives a single block key (the size of SCRAM-SHA-256 keys)
+// from password with PBKDF2-HMAC-SHA256.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	mac := hmac.New(sha256.New, password)
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		mac.Reset()
+		mac.Write(u)
+		u = mac.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
@@ -1,14 +1,22 @@
 package postgresql
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
+	"log"
+	"os"
 	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/lib/pq"
 )
 
 func TestAccPostgresqlRole_Basic(t *testing.T) {
@@ -39,6 +47,8 @@ func TestAccPostgresqlRole_Basic(t *testing.T) {
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "valid_until", "infinity"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "skip_drop_role", "false"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "skip_reassign_owned", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "reassign_owned", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "drop_owned", "true"),
 					resource.TestCheckResourceAttr("postgresql_role.role_with_defaults", "roles.#", "0"),
 
 					testAccCheckPostgresqlRoleExists("tf_tests_sub_role", []string{"tf_tests_myrole2", "tf_tests_role_simple"}),
@@ -101,6 +111,1025 @@ func TestAccPostgresqlRole_Update(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlRole_DropDependencies(t *testing.T) {
+	// The database holds a table on which the role has privileges,
+	// which blocks the DROP ROLE as the provider is not connected to it.
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleDependenciesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_dependencies_role", nil),
+					func(*terraform.State) error {
+						dbExecute(t, config.connStr(dbName), "GRANT SELECT ON test_table TO tf_tests_dependencies_role")
+						return nil
+					},
+				),
+			},
+			{
+				Config:      testAccPostgresqlRoleDependenciesConfig,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile(fmt.Sprintf(`(?s)the objects of the other databases.*privileges on 1 object\(s\) of pg_class in database "%s"`, dbName)),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "REVOKE SELECT ON test_table FROM tf_tests_dependencies_role")
+				},
+				Config: testAccPostgresqlRoleDependenciesConfig,
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_PasswordNull(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			// The legacy "NULL" value still clears the password.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRolePasswordConfig, "NULL"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_role", nil),
+					testAccCheckPostgresqlRolePasswordNull("tf_tests_password_role", true),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRolePasswordConfig, "NULL'; --"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_role", nil),
+					testAccCheckPostgresqlRolePasswordNull("tf_tests_password_role", false),
+					resource.TestCheckResourceAttr("postgresql_role.password_role", "password", "NULL'; --"),
+				),
+			},
+			// With password_null = false, "NULL" is a literal password.
+			{
+				Config: testAccPostgresqlRoleLiteralNullPasswordConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_role", nil),
+					testAccCheckPostgresqlRolePasswordNull("tf_tests_password_role", false),
+					resource.TestCheckResourceAttr("postgresql_role.password_role", "password", "NULL"),
+				),
+			},
+			{
+				Config: testAccPostgresqlRolePasswordNullConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_role", nil),
+					testAccCheckPostgresqlRolePasswordNull("tf_tests_password_role", true),
+					resource.TestCheckResourceAttr("postgresql_role.password_role", "password_null", "true"),
+				),
+			},
+		},
+	})
+}
+
+// The role DDL runs as the connection user even if the role assumed by the
+// provider cannot create roles.
+func TestAccPostgresqlRole_AssumeRole(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, false, true, false)
+	defer teardown()
+
+	_, roleName := getTestDBNames(dbSuffix)
+
+	testAccPostgresqlRoleAssumeRoleConfig := `
+provider "postgresql" {
+  assume_role = "%s"
+}
+
+resource "postgresql_role" "assume_role" {
+  name             = "tf_tests_assume_role"
+  login            = true
+  connection_limit = %d
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleAssumeRoleConfig, roleName, 5),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_assume_role", nil),
+					resource.TestCheckResourceAttr("postgresql_role.assume_role", "connection_limit", "5"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleAssumeRoleConfig, roleName, 10),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_assume_role", nil),
+					resource.TestCheckResourceAttr("postgresql_role.assume_role", "connection_limit", "10"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_AlreadyMember(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleMembershipConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_member_role", []string{"tf_tests_member_group"}),
+				),
+			},
+			// The existing membership is kept as is.
+			{
+				Config: testAccPostgresqlRoleMembershipUpdateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_member_role", []string{"tf_tests_member_group"}),
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "connection_limit", "5"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_CircularMembership(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleMembershipConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_member_role", []string{"tf_tests_member_group"}),
+				),
+			},
+			{
+				Config:      testAccPostgresqlRoleCircularMembershipConfig,
+				ExpectError: regexp.MustCompile("would create a circular membership"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_MembershipOptions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleMembershipOptionsConfig, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_member_role", []string{"tf_tests_member_group"}),
+					testAccCheckPostgresqlRoleMembershipOptions("tf_tests_member_group", "tf_tests_member_role", true, false),
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "membership.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "roles.#", "0"),
+				),
+			},
+			// Changing the options grants the role again.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleMembershipOptionsConfig, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_member_role", []string{"tf_tests_member_group"}),
+					testAccCheckPostgresqlRoleMembershipOptions("tf_tests_member_group", "tf_tests_member_role", true, true),
+					resource.TestCheckResourceAttr("postgresql_role.member_role", "membership.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_DropOwnedWithoutReassign(t *testing.T) {
+	config := getTestConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			if err := testAccCheckPostgresqlRoleDestroy(s); err != nil {
+				return err
+			}
+
+			// The table owned by the role has been dropped, not reassigned.
+			client := testAccProvider.Meta().(*Client)
+			var exists bool
+			err := client.DB().QueryRow("SELECT to_regclass('tf_tests_drop_owned_table') IS NOT NULL").Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("Error checking table: %s", err)
+			}
+			if exists {
+				return fmt.Errorf("Table owned by the role still exists after destroy")
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleDropOwnedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_drop_owned_role", nil),
+					resource.TestCheckResourceAttr("postgresql_role.drop_owned_role", "reassign_owned", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.drop_owned_role", "drop_owned", "true"),
+					func(*terraform.State) error {
+						dbExecute(t, config.connStr("postgres"), "CREATE TABLE tf_tests_drop_owned_table (val text)")
+						dbExecute(t, config.connStr("postgres"), "ALTER TABLE tf_tests_drop_owned_table OWNER TO tf_tests_drop_owned_role")
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_Members(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleMembersConfig, `"${postgresql_role.member1.name}"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member1", []string{"tf_tests_members_group"}),
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member2", []string{}),
+					resource.TestCheckResourceAttr("postgresql_role.group", "members.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleMembersConfig, `"${postgresql_role.member2.name}"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member1", []string{}),
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member2", []string{"tf_tests_members_group"}),
+					resource.TestCheckResourceAttr("postgresql_role.group", "members.#", "1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleMembersConfig, `"${postgresql_role.member1.name}", "${postgresql_role.member2.name}"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member1", []string{"tf_tests_members_group"}),
+					testAccCheckPostgresqlRoleExists("tf_tests_members_member2", []string{"tf_tests_members_group"}),
+					resource.TestCheckResourceAttr("postgresql_role.group", "members.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// A role renamed outside of Terraform is found by its OID and renamed back.
+func TestAccPostgresqlRole_RenamedOutOfBand(t *testing.T) {
+	config := getTestConfig(t)
+	var roleOID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleRenamedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_renamed_role", nil),
+					testAccCheckRoleOID("postgresql_role.role", &roleOID),
+				),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr("postgres"),
+						"ALTER ROLE tf_tests_renamed_role RENAME TO tf_tests_renamed_role_new")
+				},
+				Config: testAccPostgresqlRoleRenamedConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_renamed_role", nil),
+					testAccCheckRoleOID("postgresql_role.role", &roleOID),
+					resource.TestCheckResourceAttr("postgresql_role.role", "name", "tf_tests_renamed_role"),
+					resource.TestCheckResourceAttr("postgresql_role.role", "id", "tf_tests_renamed_role"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckRoleOID stores the OID of the role in oid on the first call,
+// and checks the role still has the same OID, i.e. it has not been created
+// again, on the next ones.
+func testAccCheckRoleOID(n string, oid *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", n)
+		}
+
+		current := rs.Primary.Attributes["oid"]
+		if current == "" || current == "0" {
+			return fmt.Errorf("expected the OID of the role in the state")
+		}
+		if *oid == "" {
+			*oid = current
+		} else if current != *oid {
+			return fmt.Errorf("expected the role to keep OID %s, got %s", *oid, current)
+		}
+		return nil
+	}
+}
+
+// The password hash of a superuser role is read when the provider connects as
+// a superuser, and the configured password is kept otherwise.
+func TestAccPostgresqlRole_PasswordHashPrivileges(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	config := getTestConfig(t)
+	readerName := "tf_tests_password_reader"
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+		"CREATE ROLE %s LOGIN PASSWORD '%s'", readerName, testRolePassword,
+	))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP ROLE IF EXISTS %s", readerName))
+
+	unprivilegedProvider := fmt.Sprintf(`
+	provider "postgresql" {
+		username = "%s"
+		password = "%s"
+	}
+	`, readerName, testRolePassword)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRolePasswordHashConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_hash", nil),
+					testAccCheckRolePasswordHashInState("postgresql_role.role"),
+				),
+			},
+			// Without access to pg_authid, the refresh succeeds and the
+			// password is not seen as changed.
+			{
+				Config:   unprivilegedProvider + testAccPostgresqlRolePasswordHashConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: testAccPostgresqlRolePasswordHashConfig,
+				Check:  testAccCheckRolePasswordHashInState("postgresql_role.role"),
+			},
+		},
+	})
+}
+
+// testAccCheckRolePasswordHashInState checks the password in the state is the
+// hash read from the database rather than the configured password.
+func testAccCheckRolePasswordHashInState(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", n)
+		}
+
+		password := rs.Primary.Attributes["password"]
+		if !strings.HasPrefix(password, "md5") && !strings.HasPrefix(password, "SCRAM-SHA-256$") {
+			return fmt.Errorf("expected the password hash in the state, got %q", password)
+		}
+		return nil
+	}
+}
+
+func TestAccPostgresqlRole_NoPasswordInState(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleNoPasswordInStateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_no_password_in_state", nil),
+					resource.TestCheckResourceAttr("postgresql_role.role", "store_password_in_state", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.role", "password", "mypass"),
+				),
+			},
+		},
+	})
+}
+
+// The password of the role never appears in the logs of the provider.
+func TestAccPostgresqlRole_PasswordNotLogged(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(os.Stderr)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				// resource.Test sets the output of the logs, so it is
+				// captured once the test has started.
+				PreConfig: func() {
+					log.SetOutput(&logs)
+				},
+				Config: fmt.Sprintf(testAccPostgresqlRolePasswordNotLoggedConfig, "tf-tests-secret-password"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_password_not_logged", nil),
+					func(*terraform.State) error {
+						if !strings.Contains(logs.String(), "CREATE ROLE") {
+							return fmt.Errorf("expected the CREATE ROLE statement to be logged")
+						}
+						if strings.Contains(logs.String(), "tf-tests-secret-password") {
+							return fmt.Errorf("the password of the role has been logged")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_SQLStatements(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleSQLStatementsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.role", "sql_statements.#", "2"),
+					resource.TestMatchResourceAttr(
+						"postgresql_role.role", "sql_statements.0",
+						regexp.MustCompile(`^CREATE ROLE "tf_tests_sql_statements" WITH ENCRYPTED PASSWORD '<redacted>' .* LOGIN `),
+					),
+					resource.TestCheckResourceAttr(
+						"postgresql_role.role", "sql_statements.1",
+						`GRANT "tf_tests_sql_statements_group" TO "tf_tests_sql_statements"`,
+					),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ValidUntilDuration(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilDurationConfig, "+90d"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_valid_until_duration", nil),
+					testAccCheckPostgresqlRoleValidUntilResolved("tf_tests_valid_until_duration", 90),
+					resource.TestCheckResourceAttr("postgresql_role.role", "valid_until_duration", "+90d"),
+				),
+			},
+			// A changed duration is resolved again.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilDurationConfig, "+30d"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleValidUntilResolved("tf_tests_valid_until_duration", 30),
+					resource.TestCheckResourceAttr("postgresql_role.role", "valid_until_duration", "+30d"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ValidUntilNull(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilConfig, "NULL"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_valid_until_null", nil),
+					testAccCheckPostgresqlRoleValidUntil("tf_tests_valid_until_null", ""),
+					resource.TestCheckResourceAttr("postgresql_role.role", "valid_until", "NULL"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilConfig, "2100-01-01 00:00:00+00"),
+				Check:  testAccCheckPostgresqlRoleValidUntil("tf_tests_valid_until_null", "2100-01-01 00:00:00+00"),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilConfig, "null"),
+				Check:  testAccCheckPostgresqlRoleValidUntil("tf_tests_valid_until_null", ""),
+			},
+			// An unset expiration never expires like infinity, the role is
+			// left alone.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilConfig, "infinity"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleValidUntil("tf_tests_valid_until_null", ""),
+					resource.TestCheckResourceAttr("postgresql_role.role", "valid_until", "NULL"),
+				),
+			},
+			// Removing valid_until resets the expiration to the infinity
+			// default.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleValidUntilConfig, "2000-01-01 00:00:00+00"),
+				Check:  testAccCheckPostgresqlRoleValidUntil("tf_tests_valid_until_null", "2000-01-01 00:00:00+00"),
+			},
+			{
+				Config: testAccPostgresqlRoleValidUntilDefaultConfig,
+				Check:  resource.TestCheckResourceAttr("postgresql_role.role", "valid_until", "infinity"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_ConnectionLimit(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			// A connection limit of 0 prevents the role from connecting.
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleConnLimitConfig, 0),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.role", "connection_limit", "0"),
+					testAccCheckPostgresqlRoleCanConnect("tf_tests_conn_limit", false),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleConnLimitConfig, -1),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("postgresql_role.role", "connection_limit", "-1"),
+					testAccCheckPostgresqlRoleCanConnect("tf_tests_conn_limit", true),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleConnLimitConfig, 0),
+				Check:  testAccCheckPostgresqlRoleCanConnect("tf_tests_conn_limit", false),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_Settings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleSettingsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_role_settings", nil),
+					testAccCheckPostgresqlRoleConfig("tf_tests_role_settings", []string{
+						`search_path="$user", app, public`, "statement_timeout=30s", "work_mem=64MB",
+					}),
+					resource.TestCheckResourceAttr("postgresql_role.role", "role_settings.%", "3"),
+					resource.TestCheckResourceAttr("postgresql_role.role", "role_settings.work_mem", "64MB"),
+					resource.TestCheckResourceAttr("postgresql_role.role", "role_settings.statement_timeout", "30s"),
+					resource.TestCheckResourceAttr("postgresql_role.role", "role_settings.search_path", `"$user", app, public`),
+				),
+			},
+			// Removing all the settings also clears the ones set out-of-band.
+			{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					if _, err := client.DB().Exec("ALTER ROLE tf_tests_role_settings SET lock_timeout = '1s'"); err != nil {
+						t.Fatalf("could not set lock_timeout of role: %v", err)
+					}
+				},
+				Config: testAccPostgresqlRoleNoSettingsConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleConfig("tf_tests_role_settings", nil),
+					resource.TestCheckResourceAttr("postgresql_role.role", "role_settings.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlRole_Group(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlRoleGroupConfig, "true", "login = true"),
+				ExpectError: regexp.MustCompile(`group role \(group is true\) and cannot log in`),
+			},
+			{
+				Config:      fmt.Sprintf(testAccPostgresqlRoleGroupConfig, "true", `password = "mypass"`),
+				ExpectError: regexp.MustCompile(`group role \(group is true\) and cannot have a password`),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleGroupConfig, "false", "login = true"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_group_role", nil),
+					resource.TestCheckResourceAttr("postgresql_role.group", "group", "false"),
+					resource.TestCheckResourceAttr("postgresql_role.group", "login", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlRoleGroupConfig, "true", "login = false"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_group_role", nil),
+					testAccCheckPostgresqlRoleCanConnect("tf_tests_group_role", false),
+					resource.TestCheckResourceAttr("postgresql_role.group", "group", "true"),
+					resource.TestCheckResourceAttr("postgresql_role.group", "login", "false"),
+				),
+			},
+		},
+	})
+}
+
+// The imported roles match the managed ones, with their memberships and
+// settings.
+func TestAccPostgresqlRole_Import(t *testing.T) {
+	ignore := []string{"password", "reassign_owned", "drop_owned", "store_password_in_state", "sql_statements"}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRoleImportConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_import_admin", []string{"tf_tests_import_group"}),
+					testAccCheckPostgresqlRoleExists("tf_tests_import_member", []string{"tf_tests_import_group"}),
+				),
+			},
+			{
+				ResourceName:            "postgresql_role.admin",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: ignore,
+			},
+			{
+				ResourceName:            "postgresql_role.member",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: ignore,
+			},
+		},
+	})
+}
+
+func TestCheckRoleAttributes(t *testing.T) {
+	cases := []struct {
+		raw      map[string]interface{}
+		err      string
+		warnings []string
+	}{
+		{
+			raw: map[string]interface{}{"name": "role", "login": true, "password": "mypass", "replication": true},
+		},
+		{
+			raw:      map[string]interface{}{"name": "role", "password": "mypass"},
+			warnings: []string{"role role has a password but cannot log in (login is false)"},
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "replication": true, "connection_limit": 5},
+			warnings: []string{
+				"role role has the replication attribute but cannot log in to start a replication (login is false)",
+				"the connection_limit of role role is ignored as it cannot log in (login is false)",
+			},
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "superuser": true, "create_database": true, "create_role": true},
+			warnings: []string{
+				"the create_database attribute of role role is redundant as it is a superuser",
+				"the create_role attribute of role role is redundant as it is a superuser",
+			},
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "group": true, "login": true},
+			err: "role role is a group role (group is true) and cannot log in",
+		},
+		{
+			raw:      map[string]interface{}{"name": "role", "group": true, "password": "mypass"},
+			err:      "role role is a group role (group is true) and cannot have a password",
+			warnings: []string{"role role has a password but cannot log in (login is false)"},
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "login": true, "password": "mypass", "password_null": true},
+			err: "role role cannot have a password when password_null is true",
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "roles": []interface{}{"role"}},
+			err: "role role cannot be a member of itself",
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "members": []interface{}{"role"}},
+			err: "role role cannot be a member of itself",
+		},
+		{
+			raw: map[string]interface{}{"name": "role", "roles": []interface{}{"group"}, "members": []interface{}{"group"}},
+			err: "role group cannot be both granted to and a member of role",
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, c.raw)
+
+		err := checkRoleAttributes(d)
+		switch {
+		case c.err != "":
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("checkRoleAttributes(%v): expected error %q, got %v", c.raw, c.err, err)
+			}
+		case err != nil:
+			t.Errorf("checkRoleAttributes(%v): unexpected error %v", c.raw, err)
+		}
+
+		if warnings := roleAttributesWarnings(d); !reflect.DeepEqual(warnings, c.warnings) {
+			t.Errorf("roleAttributesWarnings(%v) = %q, expected %q", c.raw, warnings, c.warnings)
+		}
+	}
+}
+
+// TestCheckRoleAttributesPGPassword checks that a password explicitly set on
+// a group role is rejected, even if it is the one of PGPASSWORD, which is not
+// the default of the password.
+func TestCheckRoleAttributesPGPassword(t *testing.T) {
+	if v, ok := os.LookupEnv("PGPASSWORD"); ok {
+		defer os.Setenv("PGPASSWORD", v)
+	} else {
+		defer os.Unsetenv("PGPASSWORD")
+	}
+	os.Setenv("PGPASSWORD", "providerpass")
+
+	d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{"name": "role", "group": true})
+	if d.Get("password").(string) != "" {
+		t.Fatalf("expected the password not to default to PGPASSWORD, got %q", d.Get("password"))
+	}
+	if err := checkRoleAttributes(d); err != nil {
+		t.Errorf("checkRoleAttributes: unexpected error %v", err)
+	}
+	if warnings := roleAttributesWarnings(d); len(warnings) != 0 {
+		t.Errorf("roleAttributesWarnings = %q, expected none", warnings)
+	}
+
+	d = schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, map[string]interface{}{"name": "role", "group": true, "password": "providerpass"})
+	if err := checkRoleAttributes(d); err == nil || !strings.Contains(err.Error(), "cannot have a password") {
+		t.Errorf("checkRoleAttributes: expected the password of the group role to be rejected, got %v", err)
+	}
+}
+
+func TestRoleReadSQL(t *testing.T) {
+	query := roleReadSQL(&Client{version: semver.MustParse("16.0.0")})
+	if !strings.Contains(query, "pg_catalog.pg_roles") || !strings.Contains(query, "information_schema.applicable_roles") {
+		t.Errorf("expected the role to be read from pg_roles and applicable_roles, got %q", query)
+	}
+
+	query = roleReadSQL(&Client{version: semver.MustParse("8.0.2"), flavor: flavorRedshift})
+	expected := `SELECT usesysid, usename, usesuper, true, false, usecreatedb, true, -1, ` +
+		`COALESCE(valuntil::TEXT, 'NULL'), ` +
+		`ARRAY(SELECT groname::text FROM pg_catalog.pg_group WHERE usesysid = ANY(grolist)) ` +
+		`FROM pg_catalog.pg_user WHERE usename=$1`
+	if query != expected {
+		t.Errorf("unexpected Redshift query %q, expected %q", query, expected)
+	}
+}
+
+func TestValidateRolePassword(t *testing.T) {
+	for _, tt := range []struct {
+		password string
+		warns    bool
+	}{
+		{"NULL", true},
+		{"null", true},
+		{"NULL'; --", false},
+		{"secret", false},
+	} {
+		warnings, errors := validateRolePassword(tt.password, rolePasswordAttr)
+		if len(errors) != 0 {
+			t.Errorf("unexpected errors for password %q: %v", tt.password, errors)
+		}
+		if warns := len(warnings) != 0; warns != tt.warns {
+			t.Errorf("password %q: expected a deprecation warning %t, got %v", tt.password, tt.warns, warnings)
+		}
+	}
+}
+
+func TestRolePasswordOpts(t *testing.T) {
+	for _, tt := range []struct {
+		raw      map[string]interface{}
+		expected []string
+	}{
+		{map[string]interface{}{"name": "role", "password": "NULL"}, []string{"PASSWORD NULL"}},
+		{map[string]interface{}{"name": "role", "password": "null"}, []string{"PASSWORD NULL"}},
+		{map[string]interface{}{"name": "role", "password": "NULL", "password_null": false}, []string{"ENCRYPTED", "PASSWORD 'NULL'"}},
+		{map[string]interface{}{"name": "role", "password": "NULL", "password_null": "false"}, []string{"ENCRYPTED", "PASSWORD 'NULL'"}},
+		{map[string]interface{}{"name": "role", "password_null": true}, []string{"PASSWORD NULL"}},
+		{map[string]interface{}{"name": "role", "password_null": "true"}, []string{"PASSWORD NULL"}},
+		{map[string]interface{}{"name": "role", "password": "NULL'; --"}, []string{"ENCRYPTED", "PASSWORD 'NULL''; --'"}},
+	} {
+		d := schema.TestResourceDataRaw(t, resourcePostgreSQLRole().Schema, tt.raw)
+		if opts := rolePasswordOpts(d, d.Get("password").(string)); !reflect.DeepEqual(opts, tt.expected) {
+			t.Errorf("rolePasswordOpts(%v) = %q, expected %q", tt.raw, opts, tt.expected)
+		}
+	}
+}
+
+func TestValidateRoleSettings(t *testing.T) {
+	for _, tt := range []struct {
+		settings map[string]interface{}
+		valid    bool
+	}{
+		{map[string]interface{}{"work_mem": "64MB"}, true},
+		{map[string]interface{}{"application_name": "app, public"}, true},
+		{map[string]interface{}{"auto_explain.log_min_duration": "1s"}, true},
+		{map[string]interface{}{"Work_Mem": "64MB"}, false},
+		{map[string]interface{}{"work_mem = '1MB'; --": "64MB"}, false},
+		{map[string]interface{}{"search_path": "app"}, true},
+		{map[string]interface{}{"search_path": `"$user", app, public`}, true},
+		{map[string]interface{}{"search_path": `"a, b", "Sales"`}, true},
+		{map[string]interface{}{"temp_tablespaces": "fast_ts"}, true},
+		{map[string]interface{}{"search_path": "app,public"}, false},
+		{map[string]interface{}{"search_path": "App"}, false},
+		{map[string]interface{}{"search_path": ""}, false},
+		{map[string]interface{}{"search_path": "app; DROP ROLE app"}, false},
+		{map[string]interface{}{"search_path": `"app`}, false},
+	} {
+		_, errors := validateRoleSettings(tt.settings, roleSettingsAttr)
+		if valid := len(errors) == 0; valid != tt.valid {
+			t.Errorf("validateRoleSettings(%v): expected valid %t, got errors %v", tt.settings, tt.valid, errors)
+		}
+	}
+}
+
+func TestRoleSettingValue(t *testing.T) {
+	for _, tt := range []struct {
+		name, value, expected string
+	}{
+		{"work_mem", "64MB", "'64MB'"},
+		{"application_name", "it's, app", "'it''s, app'"},
+		{"search_path", `"$user", public`, `"$user", public`},
+	} {
+		if value := roleSettingValue(tt.name, tt.value); value != tt.expected {
+			t.Errorf("roleSettingValue(%q, %q) = %q, expected %q", tt.name, tt.value, value, tt.expected)
+		}
+	}
+}
+
+func TestParseRoleValidUntilDuration(t *testing.T) {
+	cases := []struct {
+		validUntil string
+		interval   string
+		ok         bool
+	}{
+		{"+90d", "90 days", true},
+		{"+12h", "12 hours", true},
+		{"+2w", "2 weeks", true},
+		{"+30m", "30 minutes", true},
+		{"+10s", "10 seconds", true},
+		{"+90", "", false},
+		{"+d", "", false},
+		{"-90d", "", false},
+		{"+90y", "", false},
+		{"90d", "", false},
+		{"infinity", "", false},
+		{"2030-01-01 00:00:00+00", "", false},
+	}
+
+	for _, c := range cases {
+		interval, ok := parseRoleValidUntilDuration(c.validUntil)
+		if interval != c.interval || ok != c.ok {
+			t.Errorf("parseRoleValidUntilDuration(%q) = (%q, %t), expected (%q, %t)", c.validUntil, interval, ok, c.interval, c.ok)
+		}
+	}
+}
+
+func TestSuppressRoleValidUntilDiff(t *testing.T) {
+	cases := []struct {
+		old      string
+		new      string
+		duration string
+		suppress bool
+	}{
+		{"2030-01-01 00:00:00+00", "+90d", "+90d", true},
+		// The duration or the timestamp have changed.
+		{"2030-01-01 00:00:00+00", "+30d", "+90d", false},
+		{"2030-01-01 00:00:00+00", "+90d", "", false},
+		{"infinity", "+90d", "+90d", false},
+		{"", "+90d", "", false},
+		{"NULL", "+90d", "", false},
+		{"NULL", "infinity", "", true},
+		{"NULL", "null", "", true},
+		{"infinity", "NULL", "", false},
+		{"2030-01-01 00:00:00+00", "NULL", "+90d", false},
+		{"NULL", "2030-01-01 00:00:00+00", "", false},
+	}
+
+	for _, c := range cases {
+		if suppress := roleValidUntilUnchanged(c.old, c.new, c.duration); suppress != c.suppress {
+			t.Errorf("roleValidUntilUnchanged(%q, %q, %q) = %t, expected %t", c.old, c.new, c.duration, suppress, c.suppress)
+		}
+	}
+}
+
+func TestPasswordMatchesHash(t *testing.T) {
+	cases := []struct {
+		role     string
+		password string
+		hash     string
+		expected bool
+	}{
+		{"bar", "foo", "md53858f62230ac3c915f300c664312c63f", true},
+		{"baz", "foo", "md53858f62230ac3c915f300c664312c63f", false},
+		{"bar", "bar", "md53858f62230ac3c915f300c664312c63f", false},
+		{"bar", "foo", "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$0GHuIMGzDUl7+wctlDWh7a3ccAcZXluM1PXmPc9Lz4I=:f+4wgW1puRYcapIt2qkpTkq5J0oIvaKPYg1frBR7KpE=", true},
+		{"bar", "bar", "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$0GHuIMGzDUl7+wctlDWh7a3ccAcZXluM1PXmPc9Lz4I=:f+4wgW1puRYcapIt2qkpTkq5J0oIvaKPYg1frBR7KpE=", false},
+		{"bar", "foo", "SCRAM-SHA-256$4096:invalid", false},
+		{"bar", "foo", "foo", false},
+	}
+
+	for _, c := range cases {
+		if got := passwordMatchesHash(c.role, c.password, c.hash); got != c.expected {
+			t.Errorf("passwordMatchesHash(%q, %q, %q) = %t, expected %t", c.role, c.password, c.hash, got, c.expected)
+		}
+	}
+}
+
+func testAccCheckPostgresqlRoleValidUntilResolved(roleName string, days int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var inRange bool
+		err := client.DB().QueryRow(
+			"SELECT rolvaliduntil BETWEEN now() + make_interval(days => $2 - 1) AND now() + make_interval(days => $2 + 1) FROM pg_catalog.pg_roles WHERE rolname = $1",
+			roleName, days,
+		).Scan(&inRange)
+		if err != nil {
+			return fmt.Errorf("Error reading VALID UNTIL of role %s: %s", roleName, err)
+		}
+
+		if !inRange {
+			return fmt.Errorf("VALID UNTIL of role %s has not been resolved to now + %d days", roleName, days)
+		}
+		return nil
+	}
+}
+
+// testAccCheckPostgresqlRoleValidUntil checks the rolvaliduntil of the role in
+// UTC, "" if it is NULL.
+func testAccCheckPostgresqlRoleValidUntil(roleName, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var validUntil sql.NullString
+		err := client.DB().QueryRow(
+			"SELECT to_char(rolvaliduntil AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS') || '+00' FROM pg_catalog.pg_roles WHERE rolname = $1",
+			roleName,
+		).Scan(&validUntil)
+		if err != nil {
+			return fmt.Errorf("Error reading VALID UNTIL of role %s: %s", roleName, err)
+		}
+
+		if validUntil.String != expected {
+			return fmt.Errorf("expected VALID UNTIL of role %s to be %q, got %q", roleName, expected, validUntil.String)
+		}
+		return nil
+	}
+}
+
+// testAccCheckPostgresqlRoleCanConnect checks whether the role can connect to
+// the database of the provider with the password of testAccPostgresqlRoleConnLimitConfig.
+func testAccCheckPostgresqlRoleCanConnect(roleName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+		config := client.config
+		config.Username = roleName
+		config.Password = "mypass"
+
+		db, err := sql.Open("postgres", config.connStr(client.databaseName))
+		if err != nil {
+			return fmt.Errorf("could not open connection pool for role %s: %v", roleName, err)
+		}
+		defer db.Close()
+
+		err = db.Ping()
+		switch {
+		case expected && err != nil:
+			return fmt.Errorf("expected role %s to connect, got: %v", roleName, err)
+		case !expected && err == nil:
+			return fmt.Errorf("expected role %s not to connect", roleName)
+		}
+		return nil
+	}
+}
+
 func testAccCheckPostgresqlRoleDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -156,6 +1185,87 @@ func checkRoleExists(client *Client, roleName string) (bool, error) {
 	return true, nil
 }
 
+func testAccCheckPostgresqlRolePasswordNull(roleName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var passwordNull bool
+		err := client.DB().QueryRow("SELECT rolpassword IS NULL FROM pg_catalog.pg_authid WHERE rolname=$1", roleName).Scan(&passwordNull)
+		if err != nil {
+			return fmt.Errorf("Error reading role password: %s", err)
+		}
+
+		if passwordNull != expected {
+			return fmt.Errorf("Expected role %s password to be NULL: %t, got %t", roleName, expected, passwordNull)
+		}
+		return nil
+	}
+}
+
+// testAccCheckPostgresqlRoleConfig checks the settings of the role, expected
+// is nil when rolconfig must be NULL.
+func testAccCheckPostgresqlRoleConfig(roleName string, expected []string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var config []string
+		var configNull bool
+		err := client.DB().QueryRow(
+			"SELECT rolconfig IS NULL, COALESCE(rolconfig, '{}'::text[]) FROM pg_catalog.pg_roles WHERE rolname=$1", roleName,
+		).Scan(&configNull, pq.Array(&config))
+		if err != nil {
+			return fmt.Errorf("Error reading role settings: %s", err)
+		}
+
+		if expected == nil {
+			if !configNull {
+				return fmt.Errorf("Expected role %s settings to be NULL, got %v", roleName, config)
+			}
+			return nil
+		}
+
+		sort.Strings(config)
+		sort.Strings(expected)
+		if !reflect.DeepEqual(config, expected) {
+			return fmt.Errorf("Expected role %s settings to be %v, got %v", roleName, expected, config)
+		}
+		return nil
+	}
+}
+
+// testAccCheckPostgresqlRoleMembershipOptions checks the options of the
+// membership, the inherit option only on servers supporting it.
+func testAccCheckPostgresqlRoleMembershipOptions(roleName, memberName string, admin, inherit bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		options := "true"
+		if client.featureSupported(featureMembershipOptions) {
+			options = "m.inherit_option"
+		}
+
+		var adminOption, inheritOption bool
+		err := client.DB().QueryRow(fmt.Sprintf(
+			`SELECT m.admin_option, %s FROM pg_catalog.pg_auth_members m
+			JOIN pg_catalog.pg_roles r ON r.oid = m.roleid
+			JOIN pg_catalog.pg_roles u ON u.oid = m.member
+			WHERE r.rolname = $1 AND u.rolname = $2`, options),
+			roleName, memberName,
+		).Scan(&adminOption, &inheritOption)
+		if err != nil {
+			return fmt.Errorf("Error reading membership of %s in %s: %s", memberName, roleName, err)
+		}
+
+		if adminOption != admin {
+			return fmt.Errorf("Expected membership of %s in %s admin option to be %t", memberName, roleName, admin)
+		}
+		if client.featureSupported(featureMembershipOptions) && inheritOption != inherit {
+			return fmt.Errorf("Expected membership of %s in %s inherit option to be %t", memberName, roleName, inherit)
+		}
+		return nil
+	}
+}
+
 func checkGrantedRoles(client *Client, roleName string, expectedRoles []string) error {
 	rows, err := client.DB().Query(
 		"SELECT role_name FROM information_schema.applicable_roles WHERE grantee=$1 ORDER BY role_name",
@@ -257,3 +1367,244 @@ resource "postgresql_role" "update_role" {
   roles = ["${postgresql_role.group_role.name}"]
 }
 `
+
+var testAccPostgresqlRoleDependenciesConfig = `
+# A single connection checks that the dependencies are not listed while the
+# failed DROP still holds it.
+provider "postgresql" {
+  max_connections = 1
+}
+
+resource "postgresql_role" "dependencies_role" {
+  name = "tf_tests_dependencies_role"
+}
+`
+
+var testAccPostgresqlRolePasswordConfig = `
+resource "postgresql_role" "password_role" {
+  name = "tf_tests_password_role"
+  login = true
+  password = "%s"
+}
+`
+
+var testAccPostgresqlRoleLiteralNullPasswordConfig = `
+resource "postgresql_role" "password_role" {
+  name = "tf_tests_password_role"
+  login = true
+  password = "NULL"
+  password_null = false
+}
+`
+
+var testAccPostgresqlRolePasswordNullConfig = `
+resource "postgresql_role" "password_role" {
+  name = "tf_tests_password_role"
+  login = true
+  password_null = true
+}
+`
+
+var testAccPostgresqlRoleMembershipConfig = `
+resource "postgresql_role" "member_group" {
+  name = "tf_tests_member_group"
+}
+
+resource "postgresql_role" "member_role" {
+  name = "tf_tests_member_role"
+  roles = ["${postgresql_role.member_group.name}"]
+}
+`
+
+var testAccPostgresqlRoleMembershipUpdateConfig = `
+resource "postgresql_role" "member_group" {
+  name = "tf_tests_member_group"
+}
+
+resource "postgresql_role" "member_role" {
+  name = "tf_tests_member_role"
+  connection_limit = 5
+  roles = ["${postgresql_role.member_group.name}"]
+}
+`
+
+var testAccPostgresqlRoleCircularMembershipConfig = `
+resource "postgresql_role" "member_group" {
+  name = "tf_tests_member_group"
+  roles = ["tf_tests_member_role"]
+}
+
+resource "postgresql_role" "member_role" {
+  name = "tf_tests_member_role"
+  roles = ["${postgresql_role.member_group.name}"]
+}
+`
+
+var testAccPostgresqlRoleMembershipOptionsConfig = `
+resource "postgresql_role" "member_group" {
+  name = "tf_tests_member_group"
+}
+
+resource "postgresql_role" "member_role" {
+  name = "tf_tests_member_role"
+
+  membership {
+    role    = "${postgresql_role.member_group.name}"
+    inherit = %t
+    admin   = true
+  }
+}
+`
+
+var testAccPostgresqlRoleDropOwnedConfig = `
+resource "postgresql_role" "drop_owned_role" {
+  name           = "tf_tests_drop_owned_role"
+  reassign_owned = false
+  drop_owned     = true
+}
+`
+
+var testAccPostgresqlRoleMembersConfig = `
+resource "postgresql_role" "member1" {
+  name = "tf_tests_members_member1"
+}
+
+resource "postgresql_role" "member2" {
+  name = "tf_tests_members_member2"
+}
+
+resource "postgresql_role" "group" {
+  name    = "tf_tests_members_group"
+  members = [%s]
+}
+`
+
+var testAccPostgresqlRoleNoPasswordInStateConfig = `
+resource "postgresql_role" "role" {
+  name                    = "tf_tests_no_password_in_state"
+  login                   = true
+  superuser               = true
+  password                = "mypass"
+  store_password_in_state = false
+}
+`
+
+var testAccPostgresqlRoleValidUntilDurationConfig = `
+resource "postgresql_role" "role" {
+  name        = "tf_tests_valid_until_duration"
+  login       = true
+  valid_until = "%s"
+}
+`
+
+var testAccPostgresqlRoleValidUntilConfig = `
+resource "postgresql_role" "role" {
+  name        = "tf_tests_valid_until_null"
+  login       = true
+  valid_until = "%s"
+}
+`
+
+var testAccPostgresqlRoleValidUntilDefaultConfig = `
+resource "postgresql_role" "role" {
+  name  = "tf_tests_valid_until_null"
+  login = true
+}
+`
+
+var testAccPostgresqlRoleConnLimitConfig = `
+resource "postgresql_role" "role" {
+  name             = "tf_tests_conn_limit"
+  login            = true
+  password         = "mypass"
+  connection_limit = %d
+}
+`
+
+var testAccPostgresqlRoleSettingsConfig = `
+resource "postgresql_role" "role" {
+  name = "tf_tests_role_settings"
+
+  role_settings = {
+    work_mem          = "64MB"
+    statement_timeout = "30s"
+    search_path       = "\"$user\", app, public"
+  }
+}
+`
+
+var testAccPostgresqlRoleNoSettingsConfig = `
+resource "postgresql_role" "role" {
+  name = "tf_tests_role_settings"
+}
+`
+
+var testAccPostgresqlRoleImportConfig = `
+resource "postgresql_role" "group" {
+  name = "tf_tests_import_group"
+}
+
+resource "postgresql_role" "admin" {
+  name             = "tf_tests_import_admin"
+  login            = true
+  connection_limit = 5
+
+  membership {
+    role  = "${postgresql_role.group.name}"
+    admin = true
+  }
+
+  role_settings = {
+    work_mem = "64MB"
+  }
+}
+
+resource "postgresql_role" "member" {
+  name  = "tf_tests_import_member"
+  roles = ["${postgresql_role.group.name}"]
+}
+`
+
+var testAccPostgresqlRoleGroupConfig = `
+resource "postgresql_role" "group" {
+  name  = "tf_tests_group_role"
+  group = %s
+  %s
+}
+`
+
+var testAccPostgresqlRolePasswordNotLoggedConfig = `
+resource "postgresql_role" "role" {
+  name     = "tf_tests_password_not_logged"
+  login    = true
+  password = "%s"
+}
+`
+
+var testAccPostgresqlRoleSQLStatementsConfig = `
+resource "postgresql_role" "group" {
+  name = "tf_tests_sql_statements_group"
+}
+
+resource "postgresql_role" "role" {
+  name     = "tf_tests_sql_statements"
+  login    = true
+  password = "mypass"
+  roles    = ["${postgresql_role.group.name}"]
+}
+`
+
+var testAccPostgresqlRolePasswordHashConfig = `
+resource "postgresql_role" "role" {
+  name      = "tf_tests_password_hash"
+  login     = true
+  superuser = true
+  password  = "mypass"
+}
+`
+
+var testAccPostgresqlRoleRenamedConfig = `
+resource "postgresql_role" "role" {
+  name = "tf_tests_renamed_role"
+}
+`
@@ -0,0 +1,364 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	rolesRoleAttr = "role"
+)
+
+// bulkRole is a role managed by the postgresql_roles resource.
+type bulkRole struct {
+	name            string
+	login           bool
+	password        string
+	inherit         bool
+	createDatabase  bool
+	createRole      bool
+	connectionLimit int
+}
+
+func resourcePostgreSQLRoles() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLRolesCreate,
+		Read:   resourcePostgreSQLRolesRead,
+		Update: resourcePostgreSQLRolesUpdate,
+		Delete: resourcePostgreSQLRolesDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(roleDefaultTimeout),
+			Update: schema.DefaultTimeout(roleDefaultTimeout),
+			Delete: schema.DefaultTimeout(roleDefaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			rolesRoleAttr: {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						roleNameAttr: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateIdentifier,
+							Description:  "The name of the role",
+						},
+						roleLoginAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Determine whether a role is allowed to log in",
+						},
+						rolePasswordAttr: {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Sets the role's password",
+						},
+						roleInheritAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: `Determine whether a role "inherits" the privileges of roles it is a member of`,
+						},
+						roleCreateDBAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Define a role's ability to create databases",
+						},
+						roleCreateRoleAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Determine whether this role will be permitted to create new roles",
+						},
+						roleConnLimitAttr: {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      -1,
+							ValidateFunc: validateConnLimit,
+							Description:  "How many concurrent connections can be made with this role",
+						},
+					},
+				},
+				Description: "The roles to manage in bulk",
+			},
+			roleReassignOwnedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Run the REASSIGN OWNED command when removing a role from PostgreSQL",
+			},
+			roleDropOwnedAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Run the DROP OWNED command when removing a role from PostgreSQL",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLRolesCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutCreate)
+	defer cancel()
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
+	defer txn.Rollback()
+
+	for _, role := range bulkRoles(d.Get(rolesRoleAttr).(*schema.Set)) {
+		if err := createBulkRole(ctx, txn, role); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	d.SetId(resource.PrefixedUniqueId("roles-"))
+
+	return readBulkRoles(c, d)
+}
+
+func resourcePostgreSQLRolesRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readBulkRoles(c, d)
+}
+
+// readBulkRoles reads all the roles of the resource with a single query.  The
+// roles which do not exist anymore are removed from the state to be created
+// again.
+func readBulkRoles(c *Client, d *schema.ResourceData) error {
+	configured := bulkRoles(d.Get(rolesRoleAttr).(*schema.Set))
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+
+	query := `SELECT rolname, rolcanlogin, rolinherit, rolcreatedb, rolcreaterole, rolconnlimit ` +
+		`FROM pg_catalog.pg_roles WHERE rolname = ANY($1)`
+	rows, err := c.DB().Query(query, pq.Array(names))
+	if err != nil {
+		return errwrap.Wrapf("Error reading roles: {{err}}", err)
+	}
+	defer rows.Close()
+
+	roles := make([]interface{}, 0, len(names))
+	for rows.Next() {
+		var role bulkRole
+		if err := rows.Scan(&role.name, &role.login, &role.inherit, &role.createDatabase, &role.createRole, &role.connectionLimit); err != nil {
+			return errwrap.Wrapf("Error reading roles: {{err}}", err)
+		}
+
+		// The password is kept as configured, it is never read back.
+		role.password = configured[role.name].password
+
+		roles = append(roles, map[string]interface{}{
+			roleNameAttr:       role.name,
+			roleLoginAttr:      role.login,
+			rolePasswordAttr:   role.password,
+			roleInheritAttr:    role.inherit,
+			roleCreateDBAttr:   role.createDatabase,
+			roleCreateRoleAttr: role.createRole,
+			roleConnLimitAttr:  role.connectionLimit,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return errwrap.Wrapf("Error reading roles: {{err}}", err)
+	}
+
+	if len(roles) == 0 {
+		log.Printf("[WARN] PostgreSQL roles (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	d.Set(rolesRoleAttr, roles)
+
+	return nil
+}
+
+func resourcePostgreSQLRolesUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if !d.HasChange(rolesRoleAttr) {
+		return readBulkRoles(c, d)
+	}
+
+	o, n := d.GetChange(rolesRoleAttr)
+	oldRoles := bulkRoles(o.(*schema.Set))
+	newRoles := bulkRoles(n.(*schema.Set))
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+	defer txn.Rollback()
+
+	for name := range oldRoles {
+		if _, ok := newRoles[name]; ok {
+			continue
+		}
+		if err := dropBulkRole(ctx, c, txn, d, name); err != nil {
+			return operationError(ctx, schema.TimeoutUpdate, err)
+		}
+	}
+
+	for name, role := range newRoles {
+		oldRole, ok := oldRoles[name]
+		switch {
+		case !ok:
+			if err := createBulkRole(ctx, txn, role); err != nil {
+				return operationError(ctx, schema.TimeoutUpdate, err)
+			}
+		case oldRole != role:
+			if err := alterBulkRole(ctx, txn, oldRole, role); err != nil {
+				return operationError(ctx, schema.TimeoutUpdate, err)
+			}
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	return readBulkRoles(c, d)
+}
+
+func resourcePostgreSQLRolesDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutDelete)
+	defer cancel()
+
+	txn, err := startConnectionUserTransactionContext(ctx, c, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutDelete, err)
+	}
+	defer txn.Rollback()
+
+	for name := range bulkRoles(d.Get(rolesRoleAttr).(*schema.Set)) {
+		if err := dropBulkRole(ctx, c, txn, d, name); err != nil {
+			return operationError(ctx, schema.TimeoutDelete, err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("could not commit transaction: {{err}}", err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// bulkRoles returns the roles of the set indexed by name.
+func bulkRoles(set *schema.Set) map[string]bulkRole {
+	roles := make(map[string]bulkRole, set.Len())
+	for _, raw := range set.List() {
+		m := raw.(map[string]interface{})
+		role := bulkRole{
+			name:            m[roleNameAttr].(string),
+			login:           m[roleLoginAttr].(bool),
+			password:        m[rolePasswordAttr].(string),
+			inherit:         m[roleInheritAttr].(bool),
+			createDatabase:  m[roleCreateDBAttr].(bool),
+			createRole:      m[roleCreateRoleAttr].(bool),
+			connectionLimit: m[roleConnLimitAttr].(int),
+		}
+		roles[role.name] = role
+	}
+	return roles
+}
+
+// bulkRoleOptions returns the options of the CREATE/ALTER ROLE statements of
+// the role, except its password.
+func bulkRoleOptions(role bulkRole) string {
+	b := &bytes.Buffer{}
+	for _, opt := range []struct {
+		value   bool
+		enable  string
+		disable string
+	}{
+		{role.login, "LOGIN", "NOLOGIN"},
+		{role.inherit, "INHERIT", "NOINHERIT"},
+		{role.createDatabase, "CREATEDB", "NOCREATEDB"},
+		{role.createRole, "CREATEROLE", "NOCREATEROLE"},
+	} {
+		if opt.value {
+			fmt.Fprint(b, " ", opt.enable)
+		} else {
+			fmt.Fprint(b, " ", opt.disable)
+		}
+	}
+	fmt.Fprintf(b, " CONNECTION LIMIT %d", role.connectionLimit)
+	return b.String()
+}
+
+func createBulkRole(ctx context.Context, txn *sql.Tx, role bulkRole) error {
+	sql := fmt.Sprintf("CREATE ROLE %s WITH%s", pq.QuoteIdentifier(role.name), bulkRoleOptions(role))
+	if role.password != "" {
+		sql += fmt.Sprintf(" PASSWORD '%s'", pqQuoteLiteral(role.password))
+	}
+
+	logSQL(sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error creating role %s: {{err}}", role.name), redactError(err, role.password))
+	}
+	return nil
+}
+
+func alterBulkRole(ctx context.Context, txn *sql.Tx, oldRole, role bulkRole) error {
+	sql := fmt.Sprintf("ALTER ROLE %s WITH%s", pq.QuoteIdentifier(role.name), bulkRoleOptions(role))
+	if role.password != oldRole.password {
+		if role.password == "" {
+			sql += " PASSWORD NULL"
+		} else {
+			sql += fmt.Sprintf(" PASSWORD '%s'", pqQuoteLiteral(role.password))
+		}
+	}
+
+	logSQL(sql)
+	if _, err := txn.ExecContext(ctx, sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error updating role %s: {{err}}", role.name), redactError(err, role.password))
+	}
+	return nil
+}
+
+// dropBulkRole drops a role like postgresql_role: the objects it owns are
+// reassigned and dropped first, unless disabled by reassign_owned and
+// drop_owned.
+func dropBulkRole(ctx context.Context, c *Client, txn *sql.Tx, d *schema.ResourceData, name string) error {
+	queries := roleDropQueries(c, name, d.Get(roleReassignOwnedAttr).(bool), d.Get(roleDropOwnedAttr).(bool), true)
+	return execRoleDropQueries(ctx, c, txn, name, queries)
+}
@@ -0,0 +1,194 @@
+package postgresql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlRoles_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRolesDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRolesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_bulk_role1", nil),
+					testAccCheckPostgresqlRoleExists("tf_tests_bulk_role2", nil),
+					resource.TestCheckResourceAttr("postgresql_roles.bulk", "role.#", "2"),
+				),
+			},
+			{
+				Config: testAccPostgresqlRolesUpdateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_bulk_role1", nil),
+					testAccCheckPostgresqlRoleExists("tf_tests_bulk_role3", nil),
+					testAccCheckPostgresqlRolesNotExist("tf_tests_bulk_role2"),
+					resource.TestCheckResourceAttr("postgresql_roles.bulk", "role.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+// A role which owns objects is dropped like with postgresql_role, its objects
+// are reassigned and dropped first.
+func TestAccPostgresqlRoles_DropOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlRolesDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlRolesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRoleExists("tf_tests_bulk_role2", nil),
+				),
+			},
+			{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					for _, sql := range []string{
+						"CREATE TABLE tf_tests_bulk_owned (id int)",
+						"ALTER TABLE tf_tests_bulk_owned OWNER TO tf_tests_bulk_role2",
+					} {
+						if _, err := client.DB().Exec(sql); err != nil {
+							t.Fatalf("could not prepare the owned table: %v", err)
+						}
+					}
+				},
+				Config: testAccPostgresqlRolesUpdateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlRolesNotExist("tf_tests_bulk_role2"),
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+						_, err := client.DB().Exec("DROP TABLE IF EXISTS tf_tests_bulk_owned")
+						return err
+					},
+				),
+			},
+		},
+	})
+}
+
+// TestAccPostgresqlRoles_Hundred creates 100 roles in a single transaction,
+// the duration of the steps is logged to compare with 100 postgresql_role
+// resources.
+func TestAccPostgresqlRoles_Hundred(t *testing.T) {
+	bulk := &bytes.Buffer{}
+	single := &bytes.Buffer{}
+	fmt.Fprint(bulk, "resource \"postgresql_roles\" \"bulk\" {\n")
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(bulk, "  role {\n    name = \"tf_tests_bulk_%d\"\n  }\n", i)
+		fmt.Fprintf(single, "resource \"postgresql_role\" \"role_%d\" {\n  name = \"tf_tests_single_%d\"\n}\n", i, i)
+	}
+	fmt.Fprint(bulk, "}\n")
+
+	for _, c := range []struct {
+		name   string
+		config string
+	}{
+		{"postgresql_roles", bulk.String()},
+		{"postgresql_role", single.String()},
+	} {
+		start := time.Now()
+		resource.Test(t, resource.TestCase{
+			PreCheck:     func() { testAccPreCheck(t) },
+			Providers:    testAccProviders,
+			CheckDestroy: testAccCheckPostgresqlRolesDestroy,
+			Steps: []resource.TestStep{
+				{
+					Config: c.config,
+				},
+			},
+		})
+		t.Logf("100 roles created and dropped with %s in %s", c.name, time.Since(start))
+	}
+}
+
+func testAccCheckPostgresqlRolesDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_roles" && rs.Type != "postgresql_role" {
+			continue
+		}
+
+		for key, name := range rs.Primary.Attributes {
+			if rs.Type == "postgresql_roles" && !isBulkRoleNameKey(key) {
+				continue
+			}
+			if rs.Type == "postgresql_role" && key != "name" {
+				continue
+			}
+
+			exists, err := checkRoleExists(client, name)
+			if err != nil {
+				return fmt.Errorf("Error checking role %s", err)
+			}
+
+			if exists {
+				return fmt.Errorf("Role %s still exists after destroy", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isBulkRoleNameKey returns true if key is the state key of the name of one of
+// the roles of a postgresql_roles resource (role.<hash>.name).
+func isBulkRoleNameKey(key string) bool {
+	return strings.HasPrefix(key, "role.") && strings.HasSuffix(key, ".name")
+}
+
+func testAccCheckPostgresqlRolesNotExist(roleName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		exists, err := checkRoleExists(client, roleName)
+		if err != nil {
+			return fmt.Errorf("Error checking role %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Role %s still exists", roleName)
+		}
+		return nil
+	}
+}
+
+var testAccPostgresqlRolesConfig = `
+resource "postgresql_roles" "bulk" {
+  role {
+    name  = "tf_tests_bulk_role1"
+    login = true
+  }
+
+  role {
+    name             = "tf_tests_bulk_role2"
+    connection_limit = 5
+  }
+}
+`
+
+var testAccPostgresqlRolesUpdateConfig = `
+resource "postgresql_roles" "bulk" {
+  role {
+    name     = "tf_tests_bulk_role1"
+    login    = true
+    password = "mypass"
+  }
+
+  role {
+    name = "tf_tests_bulk_role3"
+  }
+}
+`
@@ -0,0 +1,368 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/lib/pq"
+)
+
+const (
+	policyNameAttr       = "name"
+	policyDatabaseAttr   = "database"
+	policySchemaAttr     = "schema"
+	policyTableAttr      = "table"
+	policyCommandAttr    = "command"
+	policyRolesAttr      = "roles"
+	policyUsingAttr      = "using"
+	policyCheckAttr      = "check"
+	policyPermissiveAttr = "permissive"
+)
+
+// policyCommands maps the pg_policy polcmd values to the command of the policy.
+var policyCommands = map[string]string{
+	"*": "ALL",
+	"r": "SELECT",
+	"a": "INSERT",
+	"w": "UPDATE",
+	"d": "DELETE",
+}
+
+func resourcePostgreSQLRowSecurityPolicy() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLRowSecurityPolicyCreate,
+		Read:   resourcePostgreSQLRowSecurityPolicyRead,
+		Update: resourcePostgreSQLRowSecurityPolicyUpdate,
+		Delete: resourcePostgreSQLRowSecurityPolicyDelete,
+
+		Schema: map[string]*schema.Schema{
+			policyNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the policy",
+			},
+			policyDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database of the table to create the policy on",
+			},
+			policySchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table to create the policy on",
+			},
+			policyTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The table to create the policy on",
+			},
+			policyCommandAttr: {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  "ALL",
+				ValidateFunc: validation.StringInSlice([]string{
+					"ALL",
+					"SELECT",
+					"INSERT",
+					"UPDATE",
+					"DELETE",
+				}, false),
+				Description: "The command to which the policy applies (one of: ALL, SELECT, INSERT, UPDATE, DELETE)",
+			},
+			policyRolesAttr: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Set:         schema.HashString,
+				Description: "The roles to which the policy applies (default: PUBLIC)",
+			},
+			policyUsingAttr: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentSQL,
+				Description:      "The expression checked against the existing rows of the table",
+			},
+			policyCheckAttr: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentSQL,
+				Description:      "The expression checked against the rows added or updated in the table",
+			},
+			policyPermissiveAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: "Whether the policy is permissive or restrictive",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLRowSecurityPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureRLS) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support row-level security policies", c.version.String())
+	}
+
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	b := bytes.NewBufferString("CREATE POLICY ")
+	fmt.Fprint(b, pq.QuoteIdentifier(d.Get(policyNameAttr).(string)), " ON ", policyTableName(d))
+
+	if !d.Get(policyPermissiveAttr).(bool) {
+		if !c.featureSupported(featureRLSRestrictive) {
+			return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support restrictive policies", c.version.String())
+		}
+		fmt.Fprint(b, " AS RESTRICTIVE")
+	}
+
+	fmt.Fprint(b, " FOR ", d.Get(policyCommandAttr).(string))
+	fmt.Fprint(b, " TO ", policyRoles(d))
+
+	if v, ok := d.GetOk(policyUsingAttr); ok {
+		fmt.Fprint(b, " USING (", v.(string), ")")
+	}
+
+	if v, ok := d.GetOk(policyCheckAttr); ok {
+		fmt.Fprint(b, " WITH CHECK (", v.(string), ")")
+	}
+
+	txn, err := startTransaction(c, d.Get(policyDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if _, err := txn.Exec(b.String()); err != nil {
+		return errwrap.Wrapf("Error creating policy: {{err}}", err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId(generatePolicyID(d))
+
+	return readRowSecurityPolicy(c, d)
+}
+
+func resourcePostgreSQLRowSecurityPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readRowSecurityPolicy(c, d)
+}
+
+func readRowSecurityPolicy(c *Client, d *schema.ResourceData) error {
+	txn, err := startTransaction(c, d.Get(policyDatabaseAttr).(string))
+	switch {
+	case isDatabaseNotFoundError(err):
+		log.Printf("[WARN] PostgreSQL database of policy (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return err
+	}
+	defer txn.Rollback()
+
+	permissive := "true"
+	if c.featureSupported(featureRLSRestrictive) {
+		permissive = "p.polpermissive"
+	}
+
+	// The PUBLIC pseudo-role is represented by the 0 oid in polroles.
+	query := fmt.Sprintf(`SELECT p.polcmd::text, %s, `+
+		`ARRAY(SELECT CASE WHEN r = 0 THEN 'PUBLIC' ELSE pg_catalog.pg_get_userbyid(r) END FROM unnest(p.polroles) r)::text[], `+
+		`COALESCE(pg_catalog.pg_get_expr(p.polqual, p.polrelid), ''), `+
+		`COALESCE(pg_catalog.pg_get_expr(p.polwithcheck, p.polrelid), '') `+
+		`FROM pg_catalog.pg_policy p `+
+		`JOIN pg_catalog.pg_class c ON c.oid = p.polrelid `+
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace `+
+		`WHERE n.nspname = $1 AND c.relname = $2 AND p.polname = $3`, permissive)
+
+	var polCmd, using, check string
+	var polPermissive bool
+	var roles []string
+	err = txn.QueryRow(
+		query,
+		d.Get(policySchemaAttr).(string),
+		d.Get(policyTableAttr).(string),
+		d.Get(policyNameAttr).(string),
+	).Scan(&polCmd, &polPermissive, pq.Array(&roles), &using, &check)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL policy (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading policy: {{err}}", err)
+	}
+
+	d.Set(policyCommandAttr, policyCommands[polCmd])
+	d.Set(policyPermissiveAttr, polPermissive)
+	d.Set(policyRolesAttr, schema.NewSet(schema.HashString, stringsToInterfaces(roles)))
+	d.Set(policyUsingAttr, using)
+	d.Set(policyCheckAttr, check)
+	d.SetId(generatePolicyID(d))
+
+	return nil
+}
+
+func resourcePostgreSQLRowSecurityPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(policyDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if err := setPolicyName(txn, d); err != nil {
+		return err
+	}
+
+	if err := setPolicyDefinition(txn, d); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	return readRowSecurityPolicy(c, d)
+}
+
+func setPolicyName(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(policyNameAttr) {
+		return nil
+	}
+
+	o, n := d.GetChange(policyNameAttr)
+	sql := fmt.Sprintf(
+		"ALTER POLICY %s ON %s RENAME TO %s",
+		pq.QuoteIdentifier(o.(string)), policyTableName(d), pq.QuoteIdentifier(n.(string)),
+	)
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating policy name: {{err}}", err)
+	}
+
+	d.SetId(generatePolicyID(d))
+
+	return nil
+}
+
+func setPolicyDefinition(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(policyRolesAttr) && !d.HasChange(policyUsingAttr) && !d.HasChange(policyCheckAttr) {
+		return nil
+	}
+
+	b := bytes.NewBufferString("ALTER POLICY ")
+	fmt.Fprint(b, pq.QuoteIdentifier(d.Get(policyNameAttr).(string)), " ON ", policyTableName(d))
+
+	if d.HasChange(policyRolesAttr) {
+		fmt.Fprint(b, " TO ", policyRoles(d))
+	}
+
+	// ALTER POLICY can only replace the expressions, not remove them.
+	for _, expr := range []struct {
+		attr   string
+		clause string
+	}{
+		{policyUsingAttr, "USING"},
+		{policyCheckAttr, "WITH CHECK"},
+	} {
+		if !d.HasChange(expr.attr) {
+			continue
+		}
+		v := d.Get(expr.attr).(string)
+		if v == "" {
+			return fmt.Errorf("Error updating policy: the %s expression can not be removed from an existing policy", expr.clause)
+		}
+		fmt.Fprint(b, " ", expr.clause, " (", v, ")")
+	}
+
+	if _, err := txn.Exec(b.String()); err != nil {
+		return errwrap.Wrapf("Error updating policy: {{err}}", err)
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLRowSecurityPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(policyDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	sql := fmt.Sprintf(
+		"DROP POLICY %s ON %s",
+		pq.QuoteIdentifier(d.Get(policyNameAttr).(string)), policyTableName(d),
+	)
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error deleting policy: {{err}}", err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// policyTableName returns the quoted schema qualified name of the table of the policy.
+func policyTableName(d *schema.ResourceData) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		pq.QuoteIdentifier(d.Get(policySchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(policyTableAttr).(string)),
+	)
+}
+
+// policyRoles returns the roles of the policy ready to be used in a
+// CREATE/ALTER POLICY statement, PUBLIC if no role is set.
+func policyRoles(d *schema.ResourceData) string {
+	roles := d.Get(policyRolesAttr).(*schema.Set).List()
+	if len(roles) == 0 {
+		return publicRole
+	}
+
+	quotedRoles := make([]string, 0, len(roles))
+	for _, role := range roles {
+		quotedRoles = append(quotedRoles, quoteRoleName(role.(string)))
+	}
+	return strings.Join(quotedRoles, ", ")
+}
+
+func generatePolicyID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(policyDatabaseAttr).(string),
+		d.Get(policySchemaAttr).(string),
+		d.Get(policyTableAttr).(string),
+		d.Get(policyNameAttr).(string),
+	}, ".")
+}
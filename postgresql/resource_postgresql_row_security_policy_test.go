@@ -0,0 +1,117 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlRowSecurityPolicy(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, true)
+	defer teardown()
+
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testPolicySelect = fmt.Sprintf(`
+	resource "postgresql_row_security_policy" "test" {
+		name     = "test_policy"
+		database = "%s"
+		table    = "test_table"
+		command  = "SELECT"
+		roles    = ["%s"]
+		using    = "(val = 'test'::text)"
+	}
+	`, dbName, roleName)
+
+	var testPolicySelectUpdated = fmt.Sprintf(`
+	resource "postgresql_row_security_policy" "test" {
+		name     = "test_policy_renamed"
+		database = "%s"
+		table    = "test_table"
+		command  = "SELECT"
+		roles    = ["PUBLIC"]
+		using    = "(val = CURRENT_USER)"
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckRowSecurityPolicyDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: testPolicySelect,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRowSecurityPolicyExists(dbName, "test_policy"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "command", "SELECT"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "permissive", "true"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "roles.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "using", "(val = 'test'::text)"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "check", ""),
+				),
+			},
+			{
+				Config: testPolicySelectUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckRowSecurityPolicyExists(dbName, "test_policy_renamed"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "roles.#", "1"),
+					resource.TestCheckResourceAttr("postgresql_row_security_policy.test", "using", "(val = CURRENT_USER)"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckRowSecurityPolicyExists(dbName, policyName string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		exists, err := checkRowSecurityPolicyExists(dbName, policyName)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("Policy %s not found", policyName)
+		}
+		return nil
+	}
+}
+
+func testAccCheckRowSecurityPolicyDestroy(dbName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "postgresql_row_security_policy" {
+				continue
+			}
+
+			exists, err := checkRowSecurityPolicyExists(dbName, rs.Primary.Attributes["name"])
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				return fmt.Errorf("Policy still exists after destroy")
+			}
+		}
+		return nil
+	}
+}
+
+func checkRowSecurityPolicyExists(dbName, policyName string) (bool, error) {
+	client := testAccProvider.Meta().(*Client)
+
+	txn, err := startTransaction(client, dbName)
+	if err != nil {
+		return false, err
+	}
+	defer txn.Rollback()
+
+	var exists bool
+	err = txn.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_policy WHERE polname = $1)", policyName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("Error reading info about policy: %s", err)
+	}
+	return exists, nil
+}
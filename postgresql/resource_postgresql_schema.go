@@ -8,6 +8,7 @@ import (
 	"log"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/terraform/helper/schema"
@@ -21,11 +22,17 @@ const (
 	schemaPolicyAttr  = "policy"
 	schemaIfNotExists = "if_not_exists"
 
+	schemaRevokePublicAttr = "revoke_public"
+
 	schemaPolicyCreateAttr          = "create"
 	schemaPolicyCreateWithGrantAttr = "create_with_grant"
 	schemaPolicyRoleAttr            = "role"
 	schemaPolicyUsageAttr           = "usage"
 	schemaPolicyUsageWithGrantAttr  = "usage_with_grant"
+
+	// schemaDefaultTimeout is the default timeout of the operations on a
+	// schema.
+	schemaDefaultTimeout = 5 * time.Minute
 )
 
 func resourcePostgreSQLSchema() *schema.Resource {
@@ -39,17 +46,24 @@ func resourcePostgreSQLSchema() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(schemaDefaultTimeout),
+			Update: schema.DefaultTimeout(schemaDefaultTimeout),
+			Delete: schema.DefaultTimeout(schemaDefaultTimeout),
+		},
+
 		Schema: map[string]*schema.Schema{
 			schemaNameAttr: {
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "The name of the schema",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateSchemaName,
+				Description:  "The name of the schema",
 			},
 			schemaOwnerAttr: {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Computed:    true,
-				Description: "The ROLE name who owns the schema",
+				Description: "The ROLE name who owns the schema, the user creating it when not set",
 			},
 			schemaIfNotExists: {
 				Type:        schema.TypeBool,
@@ -57,6 +71,13 @@ func resourcePostgreSQLSchema() *schema.Resource {
 				Default:     true,
 				Description: "When true, use the existing schema if it exists",
 			},
+			schemaRevokePublicAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, revoke all the privileges of PUBLIC on the schema",
+			},
+			connectionAttr: connectionSchema(),
 			schemaPolicyAttr: &schema.Schema{
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -120,9 +141,8 @@ func resourcePostgreSQLSchemaCreate(d *schema.ResourceData, meta interface{}) er
 		}
 		fmt.Fprint(b, pq.QuoteIdentifier(schemaName))
 
-		switch v, ok := d.GetOk(schemaOwnerAttr); {
-		case ok:
-			fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(v.(string)))
+		if owner := resourceOwner(c, d, schemaOwnerAttr); owner != "" {
+			fmt.Fprint(b, " AUTHORIZATION ", pq.QuoteIdentifier(owner))
 		}
 		queries = append(queries, b.String())
 	}
@@ -151,6 +171,12 @@ func resourcePostgreSQLSchemaCreate(d *schema.ResourceData, meta interface{}) er
 		}
 	}
 
+	// PUBLIC is revoked before the policies are granted, which may grant
+	// privileges to PUBLIC.
+	if d.Get(schemaRevokePublicAttr).(bool) {
+		queries = append(queries, fmt.Sprintf("REVOKE ALL ON SCHEMA %s FROM PUBLIC", pq.QuoteIdentifier(schemaName)))
+	}
+
 	for _, policy := range schemaPolicies {
 		queries = append(queries, policy.Grants(schemaName)...)
 	}
@@ -158,20 +184,55 @@ func resourcePostgreSQLSchemaCreate(d *schema.ResourceData, meta interface{}) er
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
+	ctx, cancel := operationContext(c, d, schema.TimeoutCreate)
+	defer cancel()
+
+	connClient, closeConn, err := connectionClient(c, d)
 	if err != nil {
 		return err
 	}
+	defer closeConn()
+
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutCreate, err)
+	}
 	defer txn.Rollback()
 
-	for _, query := range queries {
-		if _, err = txn.Exec(query); err != nil {
-			return errwrap.Wrapf(fmt.Sprintf("Error creating schema %s: {{err}}", schemaName), err)
+	// CREATE SCHEMA IF NOT EXISTS ignores the AUTHORIZATION clause of an
+	// existing schema, which is adopted through the update paths instead:
+	// the configured owner and policies are applied, and the privileges of
+	// the roles which are not in the configuration are kept.
+	adopt := false
+	if d.Get(schemaIfNotExists).(bool) && c.featureSupported(featureSchemaCreateIfNotExist) {
+		if adopt, err = schemaExists(txn, schemaName); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+	}
+
+	if adopt {
+		log.Printf("[INFO] PostgreSQL schema (%s) already exists, adopting it", schemaName)
+		if err := setSchemaOwner(txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+
+		if err := setSchemaRevokePublic(c, txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+
+		if err := setSchemaPolicy(txn, d); err != nil {
+			return operationError(ctx, schema.TimeoutCreate, err)
+		}
+	} else {
+		for _, query := range queries {
+			if _, err = txn.ExecContext(ctx, query); err != nil {
+				return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf(fmt.Sprintf("Error creating schema %s: {{err}}", schemaName), err))
+			}
 		}
 	}
 
 	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return operationError(ctx, schema.TimeoutCreate, errwrap.Wrapf("Error committing schema: {{err}}", err))
 	}
 
 	d.SetId(schemaName)
@@ -184,22 +245,31 @@ func resourcePostgreSQLSchemaDelete(d *schema.ResourceData, meta interface{}) er
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
+	ctx, cancel := operationContext(c, d, schema.TimeoutDelete)
+	defer cancel()
+
+	connClient, closeConn, err := connectionClient(c, d)
 	if err != nil {
 		return err
 	}
+	defer closeConn()
+
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutDelete, err)
+	}
 	defer txn.Rollback()
 
 	schemaName := d.Get(schemaNameAttr).(string)
 
 	// NOTE(sean@): Deliberately not performing a cascading drop.
 	sql := fmt.Sprintf("DROP SCHEMA %s", pq.QuoteIdentifier(schemaName))
-	if _, err = txn.Exec(sql); err != nil {
-		return errwrap.Wrapf("Error deleting schema: {{err}}", err)
+	if _, err = txn.ExecContext(ctx, sql); err != nil {
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("Error deleting schema: {{err}}", err))
 	}
 
 	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return operationError(ctx, schema.TimeoutDelete, errwrap.Wrapf("Error committing schema: {{err}}", err))
 	}
 
 	d.SetId("")
@@ -238,9 +308,12 @@ func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{})
 	schemaId := d.Id()
 	var schemaName, schemaOwner string
 	var schemaACLs []string
-	err := c.DB().QueryRow("SELECT n.nspname, pg_catalog.pg_get_userbyid(n.nspowner), COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] FROM pg_catalog.pg_namespace n WHERE n.nspname=$1", schemaId).Scan(&schemaName, &schemaOwner, pq.Array(&schemaACLs))
+	query := `SELECT n.nspname, r.rolname, COALESCE(n.nspacl, '{}'::aclitem[])::TEXT[] ` +
+		`FROM pg_catalog.pg_namespace n JOIN pg_catalog.pg_roles r ON r.oid = n.nspowner ` +
+		`WHERE n.nspname=$1`
+	err := c.DB().QueryRow(query, schemaId).Scan(&schemaName, &schemaOwner, pq.Array(&schemaACLs))
 	switch {
-	case err == sql.ErrNoRows:
+	case err == sql.ErrNoRows, pqErrorType(err) == ErrObjectNotFound:
 		log.Printf("[WARN] PostgreSQL schema (%s) not found", schemaId)
 		d.SetId("")
 		return nil
@@ -249,6 +322,8 @@ func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{})
 	default:
 		type RoleKey string
 		schemaPolicies := make(map[RoleKey]acl.Schema, len(schemaACLs))
+		publicPolicy := schemaPublicPolicy(d)
+		publicAccess := false
 		for _, aclStr := range schemaACLs {
 			aclItem, err := acl.Parse(aclStr)
 			if err != nil {
@@ -260,6 +335,12 @@ func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{})
 				return errwrap.Wrapf("invalid perms for schema: {{err}}", err)
 			}
 
+			// In an aclitem, the PUBLIC pseudo-role is represented by an
+			// empty role name.  The privileges of its policy are expected.
+			if schemaACL.Role == "" && schemaACL.Privileges&^publicPolicy.Privileges != 0 {
+				publicAccess = true
+			}
+
 			roleKey := RoleKey(strings.ToLower(schemaACL.Role))
 			var mergedPolicy acl.Schema
 			if existingRolePolicy, ok := schemaPolicies[roleKey]; ok {
@@ -270,6 +351,13 @@ func resourcePostgreSQLSchemaReadImpl(d *schema.ResourceData, meta interface{})
 			schemaPolicies[roleKey] = mergedPolicy
 		}
 
+		// PUBLIC got privileges back outside of Terraform, they will be
+		// revoked again.
+		if d.Get(schemaRevokePublicAttr).(bool) && publicAccess {
+			log.Printf("[WARN] PUBLIC has privileges on PostgreSQL schema (%s)", schemaName)
+			d.Set(schemaRevokePublicAttr, false)
+		}
+
 		d.Set(schemaNameAttr, schemaName)
 		d.Set(schemaOwnerAttr, schemaOwner)
 		d.SetId(schemaName)
@@ -282,28 +370,45 @@ func resourcePostgreSQLSchemaUpdate(d *schema.ResourceData, meta interface{}) er
 	c.catalogLock.Lock()
 	defer c.catalogLock.Unlock()
 
-	txn, err := c.DB().Begin()
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
+
+	connClient, closeConn, err := connectionClient(c, d)
 	if err != nil {
 		return err
 	}
+	defer closeConn()
+
+	txn, err := startTransactionContext(ctx, connClient, "")
+	if err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
 	defer txn.Rollback()
 
 	if err := setSchemaName(txn, d); err != nil {
-		return err
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
 	if err := setSchemaOwner(txn, d); err != nil {
-		return err
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	if err := setSchemaRevokePublic(c, txn, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
 	if err := setSchemaPolicy(txn, d); err != nil {
-		return err
+		return operationError(ctx, schema.TimeoutUpdate, err)
 	}
 
 	if err := txn.Commit(); err != nil {
-		return errwrap.Wrapf("Error committing schema: {{err}}", err)
+		return operationError(ctx, schema.TimeoutUpdate, errwrap.Wrapf("Error committing schema: {{err}}", err))
 	}
 
+	// The ID only follows a rename once it is committed, so that a retried
+	// or failed update still refers to the schema by its current name.
+	d.SetId(d.Get(schemaNameAttr).(string))
+
 	return resourcePostgreSQLSchemaReadImpl(d, meta)
 }
 
@@ -323,8 +428,6 @@ func setSchemaName(txn *sql.Tx, d *schema.ResourceData) error {
 	if _, err := txn.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating schema NAME: {{err}}", err)
 	}
-	d.SetId(n)
-
 	return nil
 }
 
@@ -333,14 +436,23 @@ func setSchemaOwner(txn *sql.Tx, d *schema.ResourceData) error {
 		return nil
 	}
 
-	oraw, nraw := d.GetChange(schemaOwnerAttr)
-	o := oraw.(string)
+	_, nraw := d.GetChange(schemaOwnerAttr)
 	n := nraw.(string)
 	if n == "" {
 		return errors.New("Error setting schema owner to an empty string")
 	}
 
-	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(o), pq.QuoteIdentifier(n))
+	exists, err := roleExists(txn, n)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("Error updating schema OWNER: role %q does not exist", n)
+	}
+
+	// The schema may have been renamed in the same update by setSchemaName.
+	schemaName := d.Get(schemaNameAttr).(string)
+	sql := fmt.Sprintf("ALTER SCHEMA %s OWNER TO %s", pq.QuoteIdentifier(schemaName), pq.QuoteIdentifier(n))
 	if _, err := txn.Exec(sql); err != nil {
 		return errwrap.Wrapf("Error updating schema OWNER: {{err}}", err)
 	}
@@ -416,6 +528,40 @@ func setSchemaPolicy(txn *sql.Tx, d *schema.ResourceData) error {
 	return nil
 }
 
+// setSchemaRevokePublic revokes the privileges of PUBLIC on the schema, or
+// restores the ones PostgreSQL grants by default: USAGE, and CREATE before
+// PostgreSQL 15, on the public schema and none on the other schemas.  The
+// privileges of the policy of PUBLIC, if any, are granted again after they
+// are revoked.
+func setSchemaRevokePublic(c *Client, txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(schemaRevokePublicAttr) {
+		return nil
+	}
+
+	schemaName := d.Get(schemaNameAttr).(string)
+
+	var queries []string
+	switch {
+	case d.Get(schemaRevokePublicAttr).(bool):
+		queries = append(queries, fmt.Sprintf("REVOKE ALL ON SCHEMA %s FROM PUBLIC", pq.QuoteIdentifier(schemaName)))
+		queries = append(queries, schemaPublicPolicy(d).Grants(schemaName)...)
+	case schemaName != "public":
+		return nil
+	case c.featureSupported(featurePublicSchemaCreate):
+		queries = append(queries, "GRANT USAGE, CREATE ON SCHEMA public TO PUBLIC")
+	default:
+		queries = append(queries, "GRANT USAGE ON SCHEMA public TO PUBLIC")
+	}
+
+	for _, query := range queries {
+		if _, err := txn.Exec(query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error updating privileges of PUBLIC on schema %s: {{err}}", schemaName), err)
+		}
+	}
+
+	return nil
+}
+
 // schemaChangedPolicies walks old and new to create a set of queries that can
 // be executed to enact each type of state change (roles that have been dropped
 // from the policy, added to a policy, have updated privilges, or are
@@ -483,6 +629,18 @@ func schemaPolicyToHCL(s *acl.Schema) map[string]interface{} {
 	}
 }
 
+// schemaPublicPolicy returns the privileges of the policies of PUBLIC, i.e.
+// with an empty role.
+func schemaPublicPolicy(d *schema.ResourceData) acl.Schema {
+	var publicPolicy acl.Schema
+	for _, policyRaw := range d.Get(schemaPolicyAttr).(*schema.Set).List() {
+		if policy := schemaPolicyToACL(policyRaw.(map[string]interface{})); policy.Role == "" {
+			publicPolicy = publicPolicy.Merge(policy)
+		}
+	}
+	return publicPolicy
+}
+
 func schemaPolicyToACL(policyMap map[string]interface{}) acl.Schema {
 	var rolePolicy acl.Schema
 
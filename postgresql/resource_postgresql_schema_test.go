@@ -3,6 +3,7 @@ package postgresql
 import (
 	"database/sql"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/hashicorp/errwrap"
@@ -162,6 +163,464 @@ func TestAccPostgresqlSchema_AddPolicy(t *testing.T) {
 	})
 }
 
+func TestAccPostgresqlSchema_Rename(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaRename1,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.rename", "tf_tests_rename_before"),
+					resource.TestCheckResourceAttr("postgresql_schema.rename", "owner", "tf_tests_rename_owner1"),
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+						_, err := client.DB().Exec("CREATE TABLE tf_tests_rename_before.rename_table (val text)")
+						return err
+					},
+				),
+			},
+			{
+				Config: testAccPostgresqlSchemaRename2,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.rename", "tf_tests_rename_after"),
+					resource.TestCheckResourceAttr("postgresql_schema.rename", "id", "tf_tests_rename_after"),
+					resource.TestCheckResourceAttr("postgresql_schema.rename", "owner", "tf_tests_rename_owner2"),
+					func(*terraform.State) error {
+						client := testAccProvider.Meta().(*Client)
+
+						// The table must have survived the rename.
+						var tableName string
+						err := client.DB().QueryRow("SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = $1", "tf_tests_rename_after").Scan(&tableName)
+						if err != nil {
+							return fmt.Errorf("could not find the table in the renamed schema: %v", err)
+						}
+
+						// Drop the table so the (non cascading) schema destroy can succeed.
+						_, err = client.DB().Exec("DROP TABLE tf_tests_rename_after.rename_table")
+						return err
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_DefaultOwner(t *testing.T) {
+	config := getTestConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaDefaultOwner,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.default_owner", "tf_tests_default_owner"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_default_owner", config.Username),
+					resource.TestCheckResourceAttr("postgresql_schema.default_owner", "owner", config.Username),
+				),
+			},
+			// The owner read back does not show as a diff.
+			{
+				Config:   testAccPostgresqlSchemaDefaultOwner,
+				PlanOnly: true,
+			},
+			// Setting an owner explicitly still changes it.
+			{
+				Config: testAccPostgresqlSchemaDefaultOwnerSet,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaOwner("tf_tests_default_owner", "tf_tests_default_owner_role"),
+					resource.TestCheckResourceAttr("postgresql_schema.default_owner", "owner", "tf_tests_default_owner_role"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_ProviderDefaultOwner(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, false, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	_, roleName := getTestDBNames(dbSuffix)
+
+	testAccPostgresqlProviderDefaultOwnerConfig := fmt.Sprintf(`
+provider "postgresql" {
+  default_owner = "%s"
+}
+
+resource "postgresql_schema" "provider_default_owner" {
+  name = "tf_tests_provider_default_owner"
+}
+
+resource "postgresql_schema" "owner" {
+  name  = "tf_tests_provider_owner"
+  owner = "%s"
+}
+
+resource "postgresql_database" "provider_default_owner" {
+  name = "tf_tests_provider_default_owner_db"
+}
+`, roleName, config.Username)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlProviderDefaultOwnerConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaOwner("tf_tests_provider_default_owner", roleName),
+					resource.TestCheckResourceAttr("postgresql_schema.provider_default_owner", "owner", roleName),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_provider_owner", config.Username),
+					resource.TestCheckResourceAttr("postgresql_schema.owner", "owner", config.Username),
+					resource.TestCheckResourceAttr("postgresql_database.provider_default_owner", "owner", roleName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_ChangeOwner(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaChangeOwner, "owner1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.change_owner", "tf_tests_change_owner"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_change_owner", "tf_tests_schema_owner1"),
+					resource.TestCheckResourceAttr("postgresql_schema.change_owner", "owner", "tf_tests_schema_owner1"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaChangeOwner, "owner2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaOwner("tf_tests_change_owner", "tf_tests_schema_owner2"),
+					resource.TestCheckResourceAttr("postgresql_schema.change_owner", "owner", "tf_tests_schema_owner2"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaChangeOwner, "owner1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaOwner("tf_tests_change_owner", "tf_tests_schema_owner1"),
+					resource.TestCheckResourceAttr("postgresql_schema.change_owner", "owner", "tf_tests_schema_owner1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_AssumeRole(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, false, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	_, roleName := getTestDBNames(dbSuffix)
+
+	// The assumed role needs to be able to create schemas.
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT CREATE ON DATABASE postgres TO %s", roleName))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("REVOKE CREATE ON DATABASE postgres FROM %s", roleName))
+
+	testAccPostgresqlSchemaAssumeRoleConfig := fmt.Sprintf(`
+provider "postgresql" {
+  assume_role = "%s"
+}
+
+resource "postgresql_schema" "assume_role" {
+  name = "tf_tests_assume_role"
+}
+`, roleName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaAssumeRoleConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.assume_role", "tf_tests_assume_role"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_assume_role", roleName),
+					resource.TestCheckResourceAttr("postgresql_schema.assume_role", "owner", roleName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_CreateAuthorization(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, false, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	_, roleName := getTestDBNames(dbSuffix)
+	ownerName := fmt.Sprintf("%s_owner", roleName)
+
+	// The connection user is neither a superuser nor the owner, only a member
+	// of the owner role able to create schemas.
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("CREATE ROLE %s", ownerName))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP ROLE %s", ownerName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT %s TO %s", ownerName, roleName))
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT CREATE ON DATABASE postgres TO %s", roleName))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("REVOKE CREATE ON DATABASE postgres FROM %s", roleName))
+
+	testAccPostgresqlSchemaCreateAuthorizationConfig := fmt.Sprintf(`
+provider "postgresql" {
+  username = "%s"
+  password = "%s"
+}
+
+resource "postgresql_schema" "authorization" {
+  name  = "tf_tests_authorization"
+  owner = "%s"
+}
+`, roleName, testRolePassword, ownerName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaCreateAuthorizationConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.authorization", "tf_tests_authorization"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_authorization", ownerName),
+					resource.TestCheckResourceAttr("postgresql_schema.authorization", "owner", ownerName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_ConnectionOverride(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, false, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	_, roleName := getTestDBNames(dbSuffix)
+
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf("GRANT CREATE ON DATABASE postgres TO %s", roleName))
+	defer dbExecute(t, config.connStr("postgres"), fmt.Sprintf("REVOKE CREATE ON DATABASE postgres FROM %s", roleName))
+
+	// The schema is created by the overriding user, which owns it.
+	testAccPostgresqlSchemaConnectionOverrideConfig := fmt.Sprintf(`
+resource "postgresql_schema" "connection_override" {
+  name = "tf_tests_connection_override"
+
+  connection_override {
+    username = "%s"
+    password = "%s"
+  }
+}
+`, roleName, testRolePassword)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlSchemaConnectionOverrideConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.connection_override", "tf_tests_connection_override"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_connection_override", roleName),
+					resource.TestCheckResourceAttr("postgresql_schema.connection_override", "owner", roleName),
+					resource.TestCheckResourceAttr("postgresql_schema.connection_override", "connection_override.0.username", roleName),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_RevokePublic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublic, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.revoke_public", "tf_tests_revoke_public"),
+					testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", false),
+					resource.TestCheckResourceAttr("postgresql_schema.revoke_public", "revoke_public", "false"),
+				),
+			},
+			{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					if _, err := client.DB().Exec("GRANT USAGE ON SCHEMA tf_tests_revoke_public TO PUBLIC"); err != nil {
+						t.Fatalf("could not grant usage to PUBLIC: %v", err)
+					}
+				},
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublic, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", false),
+					resource.TestCheckResourceAttr("postgresql_schema.revoke_public", "revoke_public", "true"),
+				),
+			},
+			// PUBLIC gets privileges back outside of Terraform, they are
+			// detected and revoked again.
+			{
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					if _, err := client.DB().Exec("GRANT USAGE ON SCHEMA tf_tests_revoke_public TO PUBLIC"); err != nil {
+						t.Fatalf("could not grant usage to PUBLIC: %v", err)
+					}
+				},
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublic, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", false),
+					resource.TestCheckResourceAttr("postgresql_schema.revoke_public", "revoke_public", "true"),
+				),
+			},
+		},
+	})
+}
+
+// The policy of PUBLIC is granted after its privileges are revoked.
+func TestAccPostgresqlSchema_RevokePublicWithPublicPolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublicPolicy, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.revoke_public", "tf_tests_revoke_public"),
+					testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", true),
+					resource.TestCheckResourceAttr("postgresql_schema.revoke_public", "revoke_public", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublicPolicy, false),
+				Check:  testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", true),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSchemaRevokePublicPolicy, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaPublicAccess("tf_tests_revoke_public", true),
+					resource.TestCheckResourceAttr("postgresql_schema.revoke_public", "revoke_public", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSchema_IfNotExistsAdoption(t *testing.T) {
+	if os.Getenv(resource.TestEnvVar) == "" {
+		t.Skipf("Acceptance tests skipped unless env '%s' set", resource.TestEnvVar)
+	}
+
+	// The privileges of this role on the existing schema are kept on adoption.
+	config := getTestConfig(t)
+	dbExecute(t, config.connStr("postgres"), "CREATE ROLE tf_tests_adopted_stale")
+	defer dbExecute(t, config.connStr("postgres"), "DROP ROLE IF EXISTS tf_tests_adopted_stale")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSchemaDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The schema already exists, owned by the connection user.
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*Client)
+					for _, sql := range []string{
+						"CREATE SCHEMA tf_tests_adopted",
+						"GRANT USAGE ON SCHEMA tf_tests_adopted TO tf_tests_adopted_stale",
+					} {
+						if _, err := client.DB().Exec(sql); err != nil {
+							t.Fatalf("could not prepare the schema: %v", err)
+						}
+					}
+				},
+				Config: testAccPostgresqlSchemaIfNotExistsAdoption,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSchemaExists("postgresql_schema.adopted", "tf_tests_adopted"),
+					testAccCheckPostgresqlSchemaOwner("tf_tests_adopted", "tf_tests_adopted_owner"),
+					testAccCheckPostgresqlSchemaUsage("tf_tests_adopted", "tf_tests_adopted_user", true),
+					testAccCheckPostgresqlSchemaUsage("tf_tests_adopted", "tf_tests_adopted_stale", true),
+					resource.TestCheckResourceAttr("postgresql_schema.adopted", "owner", "tf_tests_adopted_owner"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckPostgresqlSchemaUsage checks whether the role has the USAGE
+// privilege on the schema.
+func testAccCheckPostgresqlSchemaUsage(schemaName, roleName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var usage bool
+		err := client.DB().QueryRow("SELECT has_schema_privilege($1, $2, 'USAGE')", roleName, schemaName).Scan(&usage)
+		if err != nil {
+			return fmt.Errorf("Error reading privileges of schema %s: %s", schemaName, err)
+		}
+
+		if usage != expected {
+			return fmt.Errorf("Expected role %s to have USAGE on schema %s: %t, got %t", roleName, schemaName, expected, usage)
+		}
+
+		return nil
+	}
+}
+
+// testAccCheckPostgresqlSchemaPublicAccess checks whether PUBLIC has
+// privileges on the schema.
+func testAccCheckPostgresqlSchemaPublicAccess(schemaName string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var publicAccess bool
+		err := client.DB().QueryRow(
+			"SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_namespace n, "+
+				"aclexplode(COALESCE(n.nspacl, acldefault('n', n.nspowner))) a "+
+				"WHERE n.nspname = $1 AND a.grantee = 0)", schemaName,
+		).Scan(&publicAccess)
+		if err != nil {
+			return fmt.Errorf("Error reading privileges of schema %s: %s", schemaName, err)
+		}
+
+		if publicAccess != expected {
+			return fmt.Errorf("Expected PUBLIC to have privileges on schema %s: %t, got %t", schemaName, expected, publicAccess)
+		}
+
+		return nil
+	}
+}
+
+func testAccCheckPostgresqlSchemaOwner(schemaName, expectedOwner string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		var owner string
+		err := client.DB().QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(nspowner) FROM pg_catalog.pg_namespace WHERE nspname = $1", schemaName,
+		).Scan(&owner)
+		if err != nil {
+			return fmt.Errorf("Error reading owner of schema %s: %s", schemaName, err)
+		}
+
+		if owner != expectedOwner {
+			return fmt.Errorf("Wrong owner for schema %s expected %s got %s", schemaName, expectedOwner, owner)
+		}
+
+		return nil
+	}
+}
+
 func testAccCheckPostgresqlSchemaDestroy(s *terraform.State) error {
 	client := testAccProvider.Meta().(*Client)
 
@@ -413,3 +872,109 @@ resource "postgresql_schema" "test4" {
   }
 }
 `
+
+const testAccPostgresqlSchemaRename1 = `
+resource "postgresql_role" "owner1" {
+  name = "tf_tests_rename_owner1"
+}
+
+resource "postgresql_role" "owner2" {
+  name = "tf_tests_rename_owner2"
+}
+
+resource "postgresql_schema" "rename" {
+  name = "tf_tests_rename_before"
+  owner = "${postgresql_role.owner1.name}"
+}
+`
+
+const testAccPostgresqlSchemaRename2 = `
+resource "postgresql_role" "owner1" {
+  name = "tf_tests_rename_owner1"
+}
+
+resource "postgresql_role" "owner2" {
+  name = "tf_tests_rename_owner2"
+}
+
+resource "postgresql_schema" "rename" {
+  name = "tf_tests_rename_after"
+  owner = "${postgresql_role.owner2.name}"
+}
+`
+
+const testAccPostgresqlSchemaChangeOwner = `
+resource "postgresql_role" "owner1" {
+  name = "tf_tests_schema_owner1"
+}
+
+resource "postgresql_role" "owner2" {
+  name = "tf_tests_schema_owner2"
+}
+
+resource "postgresql_schema" "change_owner" {
+  name = "tf_tests_change_owner"
+  owner = "${postgresql_role.%s.name}"
+}
+`
+
+const testAccPostgresqlSchemaDefaultOwner = `
+resource "postgresql_role" "default_owner" {
+  name = "tf_tests_default_owner_role"
+}
+
+resource "postgresql_schema" "default_owner" {
+  name = "tf_tests_default_owner"
+}
+`
+
+const testAccPostgresqlSchemaDefaultOwnerSet = `
+resource "postgresql_role" "default_owner" {
+  name = "tf_tests_default_owner_role"
+}
+
+resource "postgresql_schema" "default_owner" {
+  name  = "tf_tests_default_owner"
+  owner = "${postgresql_role.default_owner.name}"
+}
+`
+
+const testAccPostgresqlSchemaRevokePublic = `
+resource "postgresql_schema" "revoke_public" {
+  name          = "tf_tests_revoke_public"
+  revoke_public = %t
+}
+`
+
+const testAccPostgresqlSchemaRevokePublicPolicy = `
+resource "postgresql_schema" "revoke_public" {
+  name          = "tf_tests_revoke_public"
+  revoke_public = %t
+
+  policy {
+    usage = true
+    role  = ""
+  }
+}
+`
+
+const testAccPostgresqlSchemaIfNotExistsAdoption = `
+resource "postgresql_role" "owner" {
+  name = "tf_tests_adopted_owner"
+}
+
+resource "postgresql_role" "user" {
+  name = "tf_tests_adopted_user"
+}
+
+resource "postgresql_schema" "adopted" {
+  name          = "tf_tests_adopted"
+  owner         = "${postgresql_role.owner.name}"
+  if_not_exists = true
+
+  policy {
+    usage = true
+    role  = "${postgresql_role.user.name}"
+  }
+}
+`
@@ -0,0 +1,217 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	serverNameAttr    = "name"
+	serverFDWNameAttr = "fdw_name"
+	serverOptionsAttr = "options"
+	serverVersionAttr = "version"
+)
+
+func resourcePostgreSQLServer() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLServerCreate,
+		Read:   resourcePostgreSQLServerRead,
+		Update: resourcePostgreSQLServerUpdate,
+		Delete: resourcePostgreSQLServerDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			serverNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the foreign server",
+			},
+			serverFDWNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the foreign-data wrapper that manages the server",
+			},
+			serverOptionsAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The options of the foreign server, as accepted by its foreign-data wrapper",
+			},
+			serverVersionAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The version of the foreign server",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLServerCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	serverName := d.Get(serverNameAttr).(string)
+
+	b := bytes.NewBufferString("CREATE SERVER ")
+	fmt.Fprint(b, pq.QuoteIdentifier(serverName))
+
+	if v, ok := d.GetOk(serverVersionAttr); ok {
+		fmt.Fprintf(b, " VERSION '%s'", pqQuoteLiteral(v.(string)))
+	}
+
+	fmt.Fprint(b, " FOREIGN DATA WRAPPER ", pq.QuoteIdentifier(d.Get(serverFDWNameAttr).(string)))
+
+	options := d.Get(serverOptionsAttr).(map[string]interface{})
+	if len(options) > 0 {
+		fmt.Fprint(b, " OPTIONS (", createOptions(options), ")")
+	}
+
+	logSQL(b.String())
+	if _, err := c.DB().Exec(b.String()); err != nil {
+		return errwrap.Wrapf("Error creating foreign server: {{err}}", redactError(err, sensitiveOptionValues(options)...))
+	}
+
+	d.SetId(serverName)
+
+	return resourcePostgreSQLServerReadImpl(d, meta)
+}
+
+func resourcePostgreSQLServerRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return resourcePostgreSQLServerReadImpl(d, meta)
+}
+
+func resourcePostgreSQLServerReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	serverID := d.Id()
+	var serverName, fdwName, serverVersion string
+	var serverOptions []string
+	query := `SELECT s.srvname, w.fdwname, COALESCE(s.srvversion, ''), COALESCE(s.srvoptions, '{}'::text[]) ` +
+		`FROM pg_catalog.pg_foreign_server s ` +
+		`JOIN pg_catalog.pg_foreign_data_wrapper w ON w.oid = s.srvfdw ` +
+		`WHERE s.srvname = $1`
+	err := c.DB().QueryRow(query, serverID).Scan(&serverName, &fdwName, &serverVersion, pq.Array(&serverOptions))
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL foreign server (%s) not found", serverID)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading foreign server: {{err}}", err)
+	}
+
+	options, err := parseOptions(serverOptions)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error reading foreign server %s: {{err}}", serverName), err)
+	}
+
+	d.Set(serverNameAttr, serverName)
+	d.Set(serverFDWNameAttr, fdwName)
+	d.Set(serverVersionAttr, serverVersion)
+	d.Set(serverOptionsAttr, options)
+	d.SetId(serverName)
+
+	return nil
+}
+
+func resourcePostgreSQLServerUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if err := setServerName(c.DB(), d); err != nil {
+		return err
+	}
+
+	if err := setServerVersion(c.DB(), d); err != nil {
+		return err
+	}
+
+	if err := setServerOptions(c.DB(), d); err != nil {
+		return err
+	}
+
+	return resourcePostgreSQLServerReadImpl(d, meta)
+}
+
+func resourcePostgreSQLServerDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	sql := fmt.Sprintf("DROP SERVER %s", pq.QuoteIdentifier(d.Id()))
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf("Error deleting foreign server: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func setServerName(db *sql.DB, d *schema.ResourceData) error {
+	if !d.HasChange(serverNameAttr) {
+		return nil
+	}
+
+	o, n := d.GetChange(serverNameAttr)
+	sql := fmt.Sprintf("ALTER SERVER %s RENAME TO %s", pq.QuoteIdentifier(o.(string)), pq.QuoteIdentifier(n.(string)))
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating foreign server name: {{err}}", err)
+	}
+	d.SetId(n.(string))
+
+	return nil
+}
+
+func setServerVersion(db *sql.DB, d *schema.ResourceData) error {
+	if !d.HasChange(serverVersionAttr) {
+		return nil
+	}
+
+	sql := fmt.Sprintf(
+		"ALTER SERVER %s VERSION '%s'",
+		pq.QuoteIdentifier(d.Id()), pqQuoteLiteral(d.Get(serverVersionAttr).(string)),
+	)
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating foreign server version: {{err}}", err)
+	}
+
+	return nil
+}
+
+func setServerOptions(db *sql.DB, d *schema.ResourceData) error {
+	if !d.HasChange(serverOptionsAttr) {
+		return nil
+	}
+
+	o, n := d.GetChange(serverOptionsAttr)
+	opts := alterOptions(o.(map[string]interface{}), n.(map[string]interface{}))
+	if len(opts) == 0 {
+		return nil
+	}
+
+	sql := fmt.Sprintf("ALTER SERVER %s OPTIONS (%s)", pq.QuoteIdentifier(d.Id()), strings.Join(opts, ", "))
+	logSQL(sql)
+	if _, err := db.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating foreign server options: {{err}}", redactError(err, sensitiveOptionValues(n.(map[string]interface{}))...))
+	}
+
+	return nil
+}
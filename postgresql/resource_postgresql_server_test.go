@@ -0,0 +1,128 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlServer_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccPostgresqlServerConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlServerExists("tf_tests_server"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "name", "tf_tests_server"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "fdw_name", "postgres_fdw"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "version", "9.6"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.host", "foo"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.dbname", "foodb"),
+				),
+			},
+			{
+				Config: testAccPostgresqlServerUpdateConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlServerExists("tf_tests_server2"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "name", "tf_tests_server2"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "version", "10"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.host", "bar"),
+					resource.TestCheckResourceAttr("postgresql_server.myserver", "options.port", "5433"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlServerDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_server" {
+			continue
+		}
+
+		exists, err := checkServerExists(client, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error checking foreign server %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Foreign server still exists after destroy")
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckPostgresqlServerExists(serverName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		exists, err := checkServerExists(client, serverName)
+		if err != nil {
+			return fmt.Errorf("Error checking foreign server %s", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("Foreign server not found")
+		}
+
+		return nil
+	}
+}
+
+func checkServerExists(client *Client, serverName string) (bool, error) {
+	var _rez int
+	err := client.DB().QueryRow("SELECT 1 FROM pg_catalog.pg_foreign_server WHERE srvname = $1", serverName).Scan(&_rez)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading info about foreign server: %s", err)
+	}
+
+	return true, nil
+}
+
+var testAccPostgresqlServerConfig = `
+resource "postgresql_extension" "postgres_fdw" {
+  name = "postgres_fdw"
+}
+
+resource "postgresql_server" "myserver" {
+  name     = "tf_tests_server"
+  fdw_name = "${postgresql_extension.postgres_fdw.name}"
+  version  = "9.6"
+
+  options {
+    host   = "foo"
+    dbname = "foodb"
+  }
+}
+`
+
+var testAccPostgresqlServerUpdateConfig = `
+resource "postgresql_extension" "postgres_fdw" {
+  name = "postgres_fdw"
+}
+
+resource "postgresql_server" "myserver" {
+  name     = "tf_tests_server2"
+  fdw_name = "${postgresql_extension.postgres_fdw.name}"
+  version  = "10"
+
+  options {
+    host = "bar"
+    port = "5433"
+  }
+}
+`
@@ -0,0 +1,240 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	settingNameAttr           = "name"
+	settingValueAttr          = "value"
+	settingReloadAttr         = "reload"
+	settingCurrentValueAttr   = "current_value"
+	settingDefaultValueAttr   = "default_value"
+	settingContextAttr        = "context"
+	settingPendingRestartAttr = "pending_restart"
+)
+
+// settingNameRe matches the name of a run-time parameter, optionally prefixed
+// by the name of the extension which defines it (e.g. auto_explain.log_min_duration).
+var settingNameRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+func resourcePostgreSQLSetting() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLSettingCreate,
+		Read:   resourcePostgreSQLSettingRead,
+		Update: resourcePostgreSQLSettingUpdate,
+		Delete: resourcePostgreSQLSettingDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			settingNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSettingName,
+				Description:  "The name of the run-time parameter",
+			},
+			settingValueAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The value of the run-time parameter, as written in postgresql.conf",
+			},
+			settingReloadAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Reload the server configuration after changing the parameter",
+			},
+			settingCurrentValueAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value of the run-time parameter currently in effect",
+			},
+			settingDefaultValueAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value of the run-time parameter when it is not set",
+			},
+			settingContextAttr: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the run-time parameter can be changed, postmaster ones require a server restart",
+			},
+			settingPendingRestartAttr: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the server needs to be restarted to apply the value of the run-time parameter",
+			},
+		},
+	}
+}
+
+func validateSettingName(v interface{}, key string) (warnings []string, errors []error) {
+	if !settingNameRe.MatchString(v.(string)) {
+		errors = append(errors, fmt.Errorf("%s must be the lower case name of a run-time parameter, got: %q", key, v.(string)))
+	}
+	return
+}
+
+func resourcePostgreSQLSettingCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureAlterSystem) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support ALTER SYSTEM", c.version.String())
+	}
+
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	settingName := d.Get(settingNameAttr).(string)
+
+	if err := setSetting(c, d); err != nil {
+		return err
+	}
+
+	d.SetId(settingName)
+
+	return readSetting(c, d)
+}
+
+func resourcePostgreSQLSettingRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readSetting(c, d)
+}
+
+func readSetting(c *Client, d *schema.ResourceData) error {
+	settingID := d.Id()
+
+	var settingName, currentValue, defaultValue, settingContext string
+	query := `SELECT name, setting, COALESCE(boot_val, ''), context FROM pg_catalog.pg_settings WHERE name = $1`
+	err := c.DB().QueryRow(query, settingID).Scan(&settingName, &currentValue, &defaultValue, &settingContext)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL setting (%s) not found", settingID)
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading setting: {{err}}", err)
+	}
+
+	// Before PostgreSQL 9.5 the content of postgresql.auto.conf cannot be
+	// read: the configured value is kept as is and a postmaster setting is
+	// always considered pending a restart.
+	pendingRestart := settingContext == "postmaster"
+	if c.featureSupported(featureFileSettings) {
+		var value string
+		var applied bool
+		query := `SELECT setting, applied FROM pg_catalog.pg_file_settings ` +
+			`WHERE name = $1 AND sourcefile LIKE '%postgresql.auto.conf' ` +
+			`ORDER BY seqno DESC LIMIT 1`
+		err := c.DB().QueryRow(query, settingID).Scan(&value, &applied)
+		switch {
+		case err == sql.ErrNoRows:
+			log.Printf("[WARN] PostgreSQL setting (%s) not set with ALTER SYSTEM", settingID)
+			d.SetId("")
+			return nil
+		case err != nil:
+			return errwrap.Wrapf("Error reading setting: {{err}}", err)
+		}
+
+		// pg_settings.pending_restart is only updated once the configuration
+		// is reloaded, while pg_file_settings is computed from the file when
+		// queried: a postmaster setting which differs from the running value
+		// is not applied.
+		if err := c.DB().QueryRow("SELECT pending_restart FROM pg_catalog.pg_settings WHERE name = $1", settingID).Scan(&pendingRestart); err != nil {
+			return errwrap.Wrapf("Error reading setting: {{err}}", err)
+		}
+		pendingRestart = pendingRestart || (settingContext == "postmaster" && !applied)
+
+		d.Set(settingValueAttr, value)
+	}
+
+	if pendingRestart {
+		log.Printf("[WARN] PostgreSQL setting %s is only applied when the server is restarted", settingID)
+	}
+
+	d.Set(settingPendingRestartAttr, pendingRestart)
+	d.Set(settingContextAttr, settingContext)
+	d.Set(settingNameAttr, settingName)
+	d.Set(settingCurrentValueAttr, currentValue)
+	d.Set(settingDefaultValueAttr, defaultValue)
+	d.SetId(settingName)
+
+	return nil
+}
+
+func resourcePostgreSQLSettingUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if d.HasChange(settingValueAttr) {
+		if err := setSetting(c, d); err != nil {
+			return err
+		}
+	}
+
+	return readSetting(c, d)
+}
+
+func resourcePostgreSQLSettingDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	settingName := d.Get(settingNameAttr).(string)
+
+	// ALTER SYSTEM cannot be executed inside a transaction block.
+	sql := fmt.Sprintf("ALTER SYSTEM RESET %s", settingName)
+	if err := execNonTransactional(context.Background(), c, "", sql); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error resetting setting %s: {{err}}", settingName), err)
+	}
+
+	if err := reloadSettings(c, d); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// setSetting writes the value of the setting in postgresql.auto.conf and
+// reloads the configuration if requested.
+func setSetting(c *Client, d *schema.ResourceData) error {
+	settingName := d.Get(settingNameAttr).(string)
+	value := d.Get(settingValueAttr).(string)
+
+	// The name is safe to use unquoted, see validateSettingName.
+	// ALTER SYSTEM cannot be executed inside a transaction block.
+	query := fmt.Sprintf("ALTER SYSTEM SET %s = '%s'", settingName, pqQuoteLiteral(value))
+	if err := execNonTransactional(context.Background(), c, "", query); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("Error setting %s: {{err}}", settingName), err)
+	}
+
+	return reloadSettings(c, d)
+}
+
+func reloadSettings(c *Client, d *schema.ResourceData) error {
+	if !d.Get(settingReloadAttr).(bool) {
+		return nil
+	}
+
+	if _, err := c.DB().Exec("SELECT pg_catalog.pg_reload_conf()"); err != nil {
+		return errwrap.Wrapf("Error reloading the server configuration: {{err}}", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlSetting_Reloadable(t *testing.T) {
+	testCheckCompatibleVersion(t, featureFileSettings)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSettingDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSettingConfig, "1000"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSettingValue("log_min_duration_statement", "1000"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "name", "log_min_duration_statement"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "value", "1000"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "reload", "true"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "default_value", "-1"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "context", "superuser"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "pending_restart", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlSettingConfig, "2000"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSettingValue("log_min_duration_statement", "2000"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "value", "2000"),
+				),
+			},
+			{
+				ResourceName:            "postgresql_setting.setting",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"reload"},
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlSetting_RestartRequired(t *testing.T) {
+	testCheckCompatibleVersion(t, featureFileSettings)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlSettingDestroy,
+		Steps: []resource.TestStep{
+			{
+				// The test server is never restarted so the new value of
+				// shared_buffers stays pending.
+				Config: testAccPostgresqlSettingRestartConfig,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlSettingValue("shared_buffers", "129MB"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "value", "129MB"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "context", "postmaster"),
+					resource.TestCheckResourceAttr("postgresql_setting.setting", "pending_restart", "true"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlSettingDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_setting" {
+			continue
+		}
+
+		value, err := getSettingFileValue(client, rs.Primary.ID)
+		if err != nil {
+			return fmt.Errorf("Error checking setting %s", err)
+		}
+
+		if value != "" {
+			return fmt.Errorf("Setting %s still set in postgresql.auto.conf after destroy", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckPostgresqlSettingValue(settingName, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		value, err := getSettingFileValue(client, settingName)
+		if err != nil {
+			return fmt.Errorf("Error checking setting %s", err)
+		}
+
+		if value != expected {
+			return fmt.Errorf("Expected setting %s to be %q in postgresql.auto.conf, got %q", settingName, expected, value)
+		}
+
+		return nil
+	}
+}
+
+// getSettingFileValue returns the value of the setting in postgresql.auto.conf
+// or an empty string if it is not set.
+func getSettingFileValue(client *Client, settingName string) (string, error) {
+	var value string
+	query := `SELECT setting FROM pg_catalog.pg_file_settings ` +
+		`WHERE name = $1 AND sourcefile LIKE '%postgresql.auto.conf' ` +
+		`ORDER BY seqno DESC LIMIT 1`
+	err := client.DB().QueryRow(query, settingName).Scan(&value)
+	switch {
+	case err == sql.ErrNoRows:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("Error reading info about setting: %s", err)
+	}
+
+	return value, nil
+}
+
+var testAccPostgresqlSettingConfig = `
+resource "postgresql_setting" "setting" {
+  name  = "log_min_duration_statement"
+  value = "%s"
+}
+`
+
+var testAccPostgresqlSettingRestartConfig = `
+resource "postgresql_setting" "setting" {
+  name  = "shared_buffers"
+  value = "129MB"
+}
+`
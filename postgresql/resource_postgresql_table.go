@@ -0,0 +1,714 @@
+package postgresql
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	tableNameAttr       = "name"
+	tableDatabaseAttr   = "database"
+	tableSchemaAttr     = "schema"
+	tableOwnerAttr      = "owner"
+	tableTablespaceAttr = "tablespace"
+	tableColumnAttr     = "column"
+	tablePrimaryKeyAttr = "primary_key"
+
+	// Column block options
+	tableColumnNameAttr     = "name"
+	tableColumnTypeAttr     = "type"
+	tableColumnNullableAttr = "nullable"
+	tableColumnDefaultAttr  = "default"
+)
+
+func resourcePostgreSQLTable() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLTableCreate,
+		Read:   resourcePostgreSQLTableRead,
+		Update: resourcePostgreSQLTableUpdate,
+		Delete: resourcePostgreSQLTableDelete,
+
+		Schema: map[string]*schema.Schema{
+			tableNameAttr: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateIdentifier,
+				Description:  "The name of the table",
+			},
+			tableDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database to create the table in",
+			},
+			tableSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema to create the table in",
+			},
+			tableOwnerAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The role owning the table, the connection user if not set",
+			},
+			tableTablespaceAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+				Description: "The tablespace of the table, the default tablespace of the database if not set",
+			},
+			tableColumnAttr: {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						tableColumnNameAttr: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateIdentifier,
+							Description:  "The name of the column",
+						},
+						tableColumnTypeAttr: {
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressColumnTypeDiff,
+							Description:      "The data type of the column",
+						},
+						tableColumnNullableAttr: {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Whether the column accepts NULL values",
+						},
+						tableColumnDefaultAttr: {
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressColumnDefaultDiff,
+							Description:      "The default value expression of the column",
+						},
+					},
+				},
+				Description: "The columns of the table, in the order they are created",
+			},
+			tablePrimaryKeyAttr: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The ordered list of the columns of the primary key of the table",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLTableCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(tableDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if err := checkTablespaceExists(txn, d.Get(tableTablespaceAttr).(string)); err != nil {
+		return err
+	}
+
+	if _, err := txn.Exec(createTableSQL(d)); err != nil {
+		return errwrap.Wrapf("Error creating table: {{err}}", err)
+	}
+
+	if err := setTableOwner(txn, d); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId(generateTableID(d))
+
+	return readTable(c, d)
+}
+
+func resourcePostgreSQLTableRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readTable(c, d)
+}
+
+func readTable(c *Client, d *schema.ResourceData) error {
+	txn, err := startTransaction(c, d.Get(tableDatabaseAttr).(string))
+	switch {
+	case isDatabaseNotFoundError(err):
+		log.Printf("[WARN] PostgreSQL database of table (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return err
+	}
+	defer txn.Rollback()
+
+	pgSchema := d.Get(tableSchemaAttr).(string)
+	name := d.Get(tableNameAttr).(string)
+
+	// reltablespace is 0 for the tables in the default tablespace of the
+	// database.
+	var owner, tablespace string
+	query := `SELECT pg_catalog.pg_get_userbyid(c.relowner), COALESCE(t.spcname, dt.spcname) ` +
+		`FROM pg_catalog.pg_class c ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`LEFT JOIN pg_catalog.pg_tablespace t ON t.oid = c.reltablespace ` +
+		`JOIN pg_catalog.pg_database d ON d.datname = pg_catalog.current_database() ` +
+		`JOIN pg_catalog.pg_tablespace dt ON dt.oid = d.dattablespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p')`
+	err = txn.QueryRow(query, pgSchema, name).Scan(&owner, &tablespace)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL table (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading table: {{err}}", err)
+	}
+
+	primaryKey, err := readTablePrimaryKey(txn, pgSchema, name)
+	if err != nil {
+		return err
+	}
+
+	columns, err := readTableColumns(txn, d, primaryKey)
+	if err != nil {
+		return err
+	}
+
+	d.Set(tableOwnerAttr, owner)
+	d.Set(tableTablespaceAttr, tablespace)
+	d.Set(tableColumnAttr, columns)
+	d.Set(tablePrimaryKeyAttr, primaryKey)
+	d.SetId(generateTableID(d))
+
+	return nil
+}
+
+// readTableColumns reads the columns of the table from the catalog.  The
+// columns cannot be reordered in PostgreSQL, so they are listed in the
+// configured order, followed by the columns added outside of Terraform.  The
+// types and defaults equivalent to the configured ones are kept as configured.
+// The columns of the primary key are always NOT NULL, their nullability is
+// kept as configured, and so is the default of the serial columns, which is
+// the nextval() of their sequence.
+func readTableColumns(txn *sql.Tx, d *schema.ResourceData, primaryKey []string) ([]interface{}, error) {
+	configured := make(map[string]map[string]interface{})
+	order := make(map[string]int)
+	for i, raw := range d.Get(tableColumnAttr).([]interface{}) {
+		column := raw.(map[string]interface{})
+		configured[column[tableColumnNameAttr].(string)] = column
+		order[column[tableColumnNameAttr].(string)] = i
+	}
+
+	rows, err := txn.Query(
+		`SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod), NOT a.attnotnull, `+
+			`COALESCE(pg_catalog.pg_get_expr(ad.adbin, ad.adrelid), '') `+
+			`FROM pg_catalog.pg_attribute a `+
+			`LEFT JOIN pg_catalog.pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum `+
+			`WHERE a.attrelid = $1::regclass AND a.attnum > 0 AND NOT a.attisdropped `+
+			`ORDER BY a.attnum`,
+		tableName(d),
+	)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading the columns of table: {{err}}", err)
+	}
+	defer rows.Close()
+
+	columns := make([]interface{}, 0)
+	added := make([]interface{}, 0)
+	for rows.Next() {
+		var name, dataType, columnDefault string
+		var nullable bool
+		if err := rows.Scan(&name, &dataType, &nullable, &columnDefault); err != nil {
+			return nil, errwrap.Wrapf("Error scanning the columns of table: {{err}}", err)
+		}
+
+		column := map[string]interface{}{
+			tableColumnNameAttr:     name,
+			tableColumnTypeAttr:     dataType,
+			tableColumnNullableAttr: nullable,
+			tableColumnDefaultAttr:  columnDefault,
+		}
+
+		conf, ok := configured[name]
+		if !ok {
+			added = append(added, column)
+			continue
+		}
+		if sliceContainsStr(primaryKey, name) {
+			column[tableColumnNullableAttr] = conf[tableColumnNullableAttr].(bool)
+		}
+		if t := conf[tableColumnTypeAttr].(string); suppressColumnTypeDiff("", dataType, t, d) {
+			column[tableColumnTypeAttr] = t
+		}
+		if v := conf[tableColumnDefaultAttr].(string); isSerialColumnType(conf[tableColumnTypeAttr].(string)) ||
+			suppressColumnDefaultDiff("", columnDefault, v, d) {
+			column[tableColumnDefaultAttr] = v
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading the columns of table: {{err}}", err)
+	}
+
+	sort.SliceStable(columns, func(i, j int) bool {
+		return order[columns[i].(map[string]interface{})[tableColumnNameAttr].(string)] <
+			order[columns[j].(map[string]interface{})[tableColumnNameAttr].(string)]
+	})
+
+	return append(columns, added...), nil
+}
+
+// readTablePrimaryKey returns the ordered columns of the primary key of the
+// table, empty if it has none.
+func readTablePrimaryKey(txn *sql.Tx, pgSchema, name string) ([]string, error) {
+	rows, err := txn.Query(
+		`SELECT k.column_name `+
+			`FROM information_schema.table_constraints t `+
+			`JOIN information_schema.key_column_usage k `+
+			`ON k.constraint_schema = t.constraint_schema AND k.constraint_name = t.constraint_name `+
+			`WHERE t.table_schema = $1 AND t.table_name = $2 AND t.constraint_type = 'PRIMARY KEY' `+
+			`ORDER BY k.ordinal_position`,
+		pgSchema, name,
+	)
+	if err != nil {
+		return nil, errwrap.Wrapf("Error reading the primary key of table: {{err}}", err)
+	}
+	defer rows.Close()
+
+	primaryKey := []string{}
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, errwrap.Wrapf("Error scanning the primary key of table: {{err}}", err)
+		}
+		primaryKey = append(primaryKey, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errwrap.Wrapf("Error reading the primary key of table: {{err}}", err)
+	}
+
+	return primaryKey, nil
+}
+
+func resourcePostgreSQLTableUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(tableDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if err := setTableColumns(txn, d); err != nil {
+		return err
+	}
+
+	if err := setTableOwner(txn, d); err != nil {
+		return err
+	}
+
+	if err := setTableTablespace(txn, d); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	return readTable(c, d)
+}
+
+// setTableColumns alters the columns of the table in place, so that its rows
+// are kept: the removed columns are dropped, the new ones added at the end of
+// the table, and the type, nullability and default of the others altered.
+// The type of a column is only widened (see columnTypeWidened): no USING
+// expression is ever guessed, and any other type change is refused.
+func setTableColumns(txn *sql.Tx, d *schema.ResourceData) error {
+	if !d.HasChange(tableColumnAttr) {
+		return nil
+	}
+
+	oraw, nraw := d.GetChange(tableColumnAttr)
+	oldColumns := make(map[string]map[string]interface{})
+	for _, raw := range oraw.([]interface{}) {
+		column := raw.(map[string]interface{})
+		oldColumns[column[tableColumnNameAttr].(string)] = column
+	}
+	newColumns := make(map[string]bool)
+	for _, raw := range nraw.([]interface{}) {
+		newColumns[raw.(map[string]interface{})[tableColumnNameAttr].(string)] = true
+	}
+
+	// A column replaced by a new one at the same position is most likely
+	// renamed, which would drop the column and its data: this is refused
+	// rather than guessed.
+	nlist := nraw.([]interface{})
+	statements := []string{}
+	for i, raw := range oraw.([]interface{}) {
+		name := raw.(map[string]interface{})[tableColumnNameAttr].(string)
+		if !newColumns[name] {
+			if i < len(nlist) {
+				newName := nlist[i].(map[string]interface{})[tableColumnNameAttr].(string)
+				if _, ok := oldColumns[newName]; !ok {
+					d.Set(tableColumnAttr, oraw)
+					return fmt.Errorf(
+						"Error altering table %s, column %s is replaced by column %s: columns are not renamed in place, remove the old column and add the new one in separate changes to drop its data, or rename it outside of Terraform",
+						d.Id(), name, newName,
+					)
+				}
+			}
+			statements = append(statements, fmt.Sprintf("DROP COLUMN %s", pq.QuoteIdentifier(name)))
+		}
+	}
+
+	for _, raw := range nlist {
+		column := raw.(map[string]interface{})
+		name := column[tableColumnNameAttr].(string)
+		quoted := pq.QuoteIdentifier(name)
+
+		old, ok := oldColumns[name]
+		if !ok {
+			statements = append(statements, "ADD COLUMN "+columnDefinition(column))
+			continue
+		}
+
+		// The normalized type is applied, as the serial types are only
+		// accepted by CREATE TABLE and ADD COLUMN.
+		oldType, newType := normalizeColumnType(old[tableColumnTypeAttr].(string)), normalizeColumnType(column[tableColumnTypeAttr].(string))
+		if newType != oldType {
+			if !columnTypeWidened(oldType, newType) {
+				d.Set(tableColumnAttr, oraw)
+				return fmt.Errorf(
+					"Error altering table %s, the type of column %s can only be widened in place (e.g. int to bigint, varchar(n) to a larger varchar or text, or a larger numeric precision), not changed from %s to %s: recreate the table (terraform taint) to change it",
+					d.Id(), name, oldType, newType,
+				)
+			}
+			statements = append(statements, fmt.Sprintf("ALTER COLUMN %s TYPE %s", quoted, newType))
+		}
+		if nullable := column[tableColumnNullableAttr].(bool); nullable != old[tableColumnNullableAttr].(bool) {
+			if nullable {
+				statements = append(statements, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", quoted))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", quoted))
+			}
+		}
+		if v := column[tableColumnDefaultAttr].(string); !suppressColumnDefaultDiff("", old[tableColumnDefaultAttr].(string), v, d) {
+			if v == "" {
+				statements = append(statements, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", quoted))
+			} else {
+				statements = append(statements, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", quoted, v))
+			}
+		}
+	}
+
+	for _, statement := range statements {
+		sql := fmt.Sprintf("ALTER TABLE %s %s", tableName(d), statement)
+		if _, err := txn.Exec(sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error altering table %s: {{err}}", d.Id()), err)
+		}
+	}
+
+	return nil
+}
+
+func resourcePostgreSQLTableDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(tableDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	sql := fmt.Sprintf("DROP TABLE %s", tableName(d))
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error deleting table: {{err}}", err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// createTableSQL returns the CREATE TABLE statement of the configured columns
+// and primary key.
+func createTableSQL(d *schema.ResourceData) string {
+	definitions := []string{}
+	for _, raw := range d.Get(tableColumnAttr).([]interface{}) {
+		definitions = append(definitions, columnDefinition(raw.(map[string]interface{})))
+	}
+
+	if primaryKey := d.Get(tablePrimaryKeyAttr).([]interface{}); len(primaryKey) > 0 {
+		columns := make([]string, 0, len(primaryKey))
+		for _, column := range primaryKey {
+			columns = append(columns, pq.QuoteIdentifier(column.(string)))
+		}
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(columns, ", ")))
+	}
+
+	b := bytes.NewBufferString("CREATE TABLE ")
+	fmt.Fprintf(b, "%s (%s)", tableName(d), strings.Join(definitions, ", "))
+	if v, ok := d.GetOk(tableTablespaceAttr); ok {
+		fmt.Fprint(b, " TABLESPACE ", pq.QuoteIdentifier(v.(string)))
+	}
+	return b.String()
+}
+
+// columnDefinition returns the definition of a column in CREATE TABLE or
+// ALTER TABLE ... ADD COLUMN.
+func columnDefinition(column map[string]interface{}) string {
+	b := bytes.NewBufferString(pq.QuoteIdentifier(column[tableColumnNameAttr].(string)))
+	fmt.Fprint(b, " ", column[tableColumnTypeAttr].(string))
+	if !column[tableColumnNullableAttr].(bool) {
+		fmt.Fprint(b, " NOT NULL")
+	}
+	if v := column[tableColumnDefaultAttr].(string); v != "" {
+		fmt.Fprint(b, " DEFAULT ", v)
+	}
+	return b.String()
+}
+
+// columnTypeAliases maps the alternative names of the data types to the name
+// used by PostgreSQL in format_type().
+var columnTypeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"int2":        "smallint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"int8":        "bigint",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"bool":        "boolean",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"float8":      "double precision",
+	"float4":      "real",
+	"decimal":     "numeric",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// normalizeColumnType returns the name of a data type as stored by
+// PostgreSQL, e.g. character varying(64) for VARCHAR(64).
+func normalizeColumnType(dataType string) string {
+	t := strings.ToLower(dataType)
+
+	// Split the array suffix, e.g. [], and the type modifier, e.g. (64),
+	// which may be followed by the rest of the name, e.g. timestamp(3)
+	// with time zone.
+	array := ""
+	if i := strings.Index(t, "["); i != -1 {
+		array = strings.Replace(t[i:], " ", "", -1)
+		t = t[:i]
+	}
+	modifier := ""
+	if i, j := strings.Index(t, "("), strings.Index(t, ")"); i != -1 && j > i {
+		modifier = strings.Replace(t[i:j+1], " ", "", -1)
+		t = t[:i] + " " + t[j+1:]
+	}
+	t = strings.Join(strings.Fields(t), " ")
+	if alias, ok := columnTypeAliases[t]; ok {
+		t = alias
+	}
+
+	switch {
+	case t == "float":
+		// The precision of float is in binary digits, float(1) to
+		// float(24) are stored as real.
+		t = "double precision"
+		if p, err := strconv.Atoi(strings.Trim(modifier, "()")); err == nil && p <= 24 {
+			t = "real"
+		}
+		modifier = ""
+	case modifier != "" && (strings.HasPrefix(t, "timestamp ") || strings.HasPrefix(t, "time ")):
+		// The precision goes before the time zone.
+		i := strings.Index(t, " ")
+		return t[:i] + modifier + t[i:] + array
+	}
+	return t + modifier + array
+}
+
+// columnIntegerTypeSizes ranks the integer types by size.
+var columnIntegerTypeSizes = map[string]int{
+	"smallint": 2,
+	"integer":  4,
+	"bigint":   8,
+}
+
+// columnTypeWidened returns true if the column type can be changed from
+// oldType to newType, both normalized, without losing any value: a larger
+// integer type, a larger or unlimited character varying, or text, and a
+// larger numeric precision with the same scale.
+func columnTypeWidened(oldType, newType string) bool {
+	if oldSize, ok := columnIntegerTypeSizes[oldType]; ok {
+		return columnIntegerTypeSizes[newType] > oldSize
+	}
+
+	if oldType == "character varying" {
+		return newType == "text"
+	}
+	if oldLength, ok := columnTypeModifier(oldType, "character varying"); ok && len(oldLength) == 1 {
+		if newType == "text" || newType == "character varying" {
+			return true
+		}
+		newLength, ok := columnTypeModifier(newType, "character varying")
+		return ok && len(newLength) == 1 && newLength[0] > oldLength[0]
+	}
+
+	if oldNumeric, ok := columnTypeModifier(oldType, "numeric"); ok && len(oldNumeric) > 0 {
+		if newType == "numeric" {
+			return true
+		}
+		newNumeric, ok := columnTypeModifier(newType, "numeric")
+		if !ok || len(newNumeric) == 0 {
+			return false
+		}
+		// numeric(p) is numeric(p,0).
+		oldNumeric, newNumeric = append(oldNumeric, 0)[:2], append(newNumeric, 0)[:2]
+		return newNumeric[0] > oldNumeric[0] && newNumeric[1] == oldNumeric[1]
+	}
+
+	return false
+}
+
+// columnTypeModifier returns the integers of the type modifier of dataType,
+// e.g. [10 2] for numeric(10,2), if it is the given type.
+func columnTypeModifier(dataType, name string) ([]int, bool) {
+	if !strings.HasPrefix(dataType, name+"(") || !strings.HasSuffix(dataType, ")") {
+		return nil, false
+	}
+
+	var values []int
+	for _, v := range strings.Split(dataType[len(name)+1:len(dataType)-1], ",") {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, false
+		}
+		values = append(values, i)
+	}
+	return values, true
+}
+
+// isSerialColumnType returns true if the type is one of the serial types,
+// whose default is the nextval() of the sequence created with the column.
+func isSerialColumnType(dataType string) bool {
+	switch strings.ToLower(strings.TrimSpace(dataType)) {
+	case "serial", "serial4", "smallserial", "serial2", "bigserial", "serial8":
+		return true
+	}
+	return false
+}
+
+// suppressColumnTypeDiff suppresses the diff between a data type and the name
+// stored by PostgreSQL.
+func suppressColumnTypeDiff(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeColumnType(old) == normalizeColumnType(new)
+}
+
+// suppressColumnDefaultDiff suppresses the diff between a default expression
+// and the one stored by PostgreSQL, which casts the constants to the type of
+// the column (e.g. 'a' is stored as 'a'::text).
+func suppressColumnDefaultDiff(k, old, new string, d *schema.ResourceData) bool {
+	return old == new || (new != "" && strings.HasPrefix(old, new+"::"))
+}
+
+// setTableOwner changes the owner of the table, which must be an existing
+// role, so that the grants and default privileges of the owner apply to it.
+func setTableOwner(txn *sql.Tx, d *schema.ResourceData) error {
+	owner := d.Get(tableOwnerAttr).(string)
+	if owner == "" || !d.HasChange(tableOwnerAttr) {
+		return nil
+	}
+
+	exists, err := roleExists(txn, owner)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("Error updating table OWNER: role %q does not exist", owner)
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s OWNER TO %s", tableName(d), pq.QuoteIdentifier(owner))
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating table OWNER: {{err}}", err)
+	}
+
+	return nil
+}
+
+// setTableTablespace moves the table to another tablespace, which rewrites
+// the table and locks it meanwhile.
+func setTableTablespace(txn *sql.Tx, d *schema.ResourceData) error {
+	tablespace := d.Get(tableTablespaceAttr).(string)
+	if tablespace == "" || !d.HasChange(tableTablespaceAttr) {
+		return nil
+	}
+
+	if err := checkTablespaceExists(txn, tablespace); err != nil {
+		return err
+	}
+
+	sql := fmt.Sprintf("ALTER TABLE %s SET TABLESPACE %s", tableName(d), pq.QuoteIdentifier(tablespace))
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error updating table TABLESPACE: {{err}}", err)
+	}
+
+	return nil
+}
+
+// tableName returns the quoted schema qualified name of the table.
+func tableName(d *schema.ResourceData) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		pq.QuoteIdentifier(d.Get(tableSchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(tableNameAttr).(string)),
+	)
+}
+
+func generateTableID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(tableDatabaseAttr).(string),
+		d.Get(tableSchemaAttr).(string),
+		d.Get(tableNameAttr).(string),
+	}, ".")
+}
@@ -0,0 +1,186 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	tableRLSDatabaseAttr = "database"
+	tableRLSSchemaAttr   = "schema"
+	tableRLSTableAttr    = "table"
+	tableRLSEnabledAttr  = "enabled"
+	tableRLSForceAttr    = "force"
+)
+
+func resourcePostgreSQLTableRLS() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLTableRLSCreate,
+		// As create only runs ALTER TABLE statements we can use it to update too
+		Update: resourcePostgreSQLTableRLSCreate,
+		Read:   resourcePostgreSQLTableRLSRead,
+		Delete: resourcePostgreSQLTableRLSDelete,
+
+		Schema: map[string]*schema.Schema{
+			tableRLSDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database of the table",
+			},
+			tableRLSSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema of the table",
+			},
+			tableRLSTableAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The table to manage the row-level security of",
+			},
+			tableRLSEnabledAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Enable the row-level security on the table",
+			},
+			tableRLSForceAttr: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Apply the row-level security to the table owner too",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLTableRLSCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	if !c.featureSupported(featureRLS) {
+		return fmt.Errorf("PostgreSQL client is talking with a server (%q) that does not support row-level security", c.version.String())
+	}
+
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if err := setTableRLS(c, d, d.Get(tableRLSEnabledAttr).(bool), d.Get(tableRLSForceAttr).(bool)); err != nil {
+		return err
+	}
+
+	d.SetId(generateTableRLSID(d))
+
+	return readTableRLS(c, d)
+}
+
+func resourcePostgreSQLTableRLSRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readTableRLS(c, d)
+}
+
+func readTableRLS(c *Client, d *schema.ResourceData) error {
+	txn, err := startTransaction(c, d.Get(tableRLSDatabaseAttr).(string))
+	switch {
+	case isDatabaseNotFoundError(err):
+		log.Printf("[WARN] PostgreSQL database of table (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return err
+	}
+	defer txn.Rollback()
+
+	var enabled, force bool
+	query := `SELECT c.relrowsecurity, c.relforcerowsecurity ` +
+		`FROM pg_catalog.pg_class c ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'r'`
+	err = txn.QueryRow(query, d.Get(tableRLSSchemaAttr).(string), d.Get(tableRLSTableAttr).(string)).Scan(&enabled, &force)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL table (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading table row-level security: {{err}}", err)
+	}
+
+	d.Set(tableRLSEnabledAttr, enabled)
+	d.Set(tableRLSForceAttr, force)
+	d.SetId(generateTableRLSID(d))
+
+	return nil
+}
+
+func resourcePostgreSQLTableRLSDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	// Restore the defaults of a table.
+	if err := setTableRLS(c, d, false, false); err != nil {
+		return err
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func setTableRLS(c *Client, d *schema.ResourceData, enabled, force bool) error {
+	tableName := fmt.Sprintf(
+		"%s.%s",
+		pq.QuoteIdentifier(d.Get(tableRLSSchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(tableRLSTableAttr).(string)),
+	)
+
+	enable := "DISABLE"
+	if enabled {
+		enable = "ENABLE"
+	}
+	forceStr := "NO FORCE"
+	if force {
+		forceStr = "FORCE"
+	}
+
+	txn, err := startTransaction(c, d.Get(tableRLSDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	for _, query := range []string{
+		fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY", tableName, enable),
+		fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY", tableName, forceStr),
+	} {
+		if _, err := txn.Exec(query); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error updating table %s row-level security: {{err}}", tableName), err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	return nil
+}
+
+func generateTableRLSID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(tableRLSDatabaseAttr).(string),
+		d.Get(tableRLSSchemaAttr).(string),
+		d.Get(tableRLSTableAttr).(string),
+	}, ".")
+}
@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlTableRLS(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, true)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testTableRLSConfig = `
+	resource "postgresql_table_rls" "test" {
+		database = "%s"
+		table    = "test_table"
+		enabled  = %t
+		force    = %t
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableRLS(dbName, false, false),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableRLSConfig, dbName, true, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableRLS(dbName, true, false),
+					resource.TestCheckResourceAttr("postgresql_table_rls.test", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_table_rls.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("postgresql_table_rls.test", "force", "false"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTableRLSConfig, dbName, true, true),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableRLS(dbName, true, true),
+					resource.TestCheckResourceAttr("postgresql_table_rls.test", "force", "true"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTableRLSConfig, dbName, false, false),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableRLS(dbName, false, false),
+					resource.TestCheckResourceAttr("postgresql_table_rls.test", "enabled", "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckTableRLS(dbName string, expectedEnabled, expectedForce bool) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var enabled, force bool
+		err = txn.QueryRow(
+			"SELECT relrowsecurity, relforcerowsecurity FROM pg_catalog.pg_class WHERE relname = 'test_table'",
+		).Scan(&enabled, &force)
+		if err != nil {
+			return fmt.Errorf("could not read test_table row-level security: %v", err)
+		}
+
+		if enabled != expectedEnabled || force != expectedForce {
+			return fmt.Errorf(
+				"expected test_table row-level security enabled=%t force=%t, got enabled=%t force=%t",
+				expectedEnabled, expectedForce, enabled, force,
+			)
+		}
+		return nil
+	}
+}
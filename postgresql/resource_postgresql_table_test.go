@@ -0,0 +1,519 @@
+package postgresql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlTable_Basic(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testTableConfig = `
+	resource "postgresql_table" "test" {
+		name        = "test_tf_table"
+		database    = "%s"
+		owner       = "%s"
+		primary_key = ["id"]
+
+		column {
+			name = "id"
+			type = "int"
+		}
+
+		column {
+			name     = "label"
+			type     = "varchar(64)"
+			nullable = false
+			default  = "'none'"
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, config.Username),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableColumns(dbName, []string{"id", "label"}),
+					resource.TestCheckResourceAttr("postgresql_table.test", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.#", "2"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.0.type", "int"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.1.nullable", "false"),
+					resource.TestCheckResourceAttr("postgresql_table.test", "primary_key.0", "id"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check:  resource.TestCheckResourceAttr("postgresql_table.test", "owner", roleName),
+			},
+			// A column added outside of Terraform is dropped.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "ALTER TABLE test_tf_table ADD COLUMN extra text")
+				},
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check:  testAccCheckTableColumns(dbName, []string{"id", "label"}),
+			},
+			// A table dropped outside of Terraform is recreated.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "DROP TABLE test_tf_table")
+				},
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check:  testAccCheckTableColumns(dbName, []string{"id", "label"}),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlTable_AlterColumns(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testTableConfig = `
+	resource "postgresql_table" "test" {
+		name        = "test_tf_table"
+		database    = "%s"
+		primary_key = ["id"]
+
+		column {
+			name = "id"
+			type = "%s"
+		}
+
+		%s
+	}
+	`
+
+	labelColumn := `
+		column {
+			name     = "label"
+			type     = "text"
+			nullable = false
+			default  = "'none'"
+		}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, "int", ""),
+				Check:  testAccCheckTableColumns(dbName, []string{"id"}),
+			},
+			// Add a column to the populated table.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "INSERT INTO test_tf_table (id) VALUES (1), (2)")
+				},
+				Config: fmt.Sprintf(testTableConfig, dbName, "int", labelColumn),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableColumns(dbName, []string{"id", "label"}),
+					testAccCheckTableRows(dbName, "SELECT count(*) FROM test_tf_table WHERE label = 'none'", 2),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.1.default", "'none'"),
+				),
+			},
+			// Change the type of a column.
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, "bigint", labelColumn),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableRows(dbName, "SELECT count(*) FROM test_tf_table WHERE pg_typeof(id) = 'bigint'::regtype", 2),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.0.type", "bigint"),
+				),
+			},
+			// A renamed column is not dropped with its data.
+			{
+				Config:      fmt.Sprintf(testTableConfig, dbName, "bigint", strings.Replace(labelColumn, `"label"`, `"title"`, 1)),
+				ExpectError: regexp.MustCompile("columns are not renamed in place"),
+			},
+			// Drop a column.
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, "bigint", ""),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableColumns(dbName, []string{"id"}),
+					testAccCheckTableRows(dbName, "SELECT count(*) FROM test_tf_table", 2),
+				),
+			},
+			// A type which is not widened is not changed, even if
+			// PostgreSQL could convert it.
+			{
+				Config:      fmt.Sprintf(testTableConfig, dbName, "int", ""),
+				ExpectError: regexp.MustCompile("can only be widened in place"),
+			},
+			{
+				Config:      fmt.Sprintf(testTableConfig, dbName, "inet", ""),
+				ExpectError: regexp.MustCompile("can only be widened in place"),
+			},
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, "bigint", ""),
+				Check:  resource.TestCheckResourceAttr("postgresql_table.test", "column.0.type", "bigint"),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlTable_WidenSerial(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testTableConfig = `
+	resource "postgresql_table" "test" {
+		name        = "test_tf_table"
+		database    = "%s"
+		primary_key = ["id"]
+
+		column {
+			name = "id"
+			type = "%s"
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, "serial"),
+			},
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), "INSERT INTO test_tf_table DEFAULT VALUES")
+				},
+				Config: fmt.Sprintf(testTableConfig, dbName, "bigserial"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableRows(dbName, "SELECT count(*) FROM test_tf_table WHERE pg_typeof(id) = 'bigint'::regtype", 1),
+					resource.TestCheckResourceAttr("postgresql_table.test", "column.0.type", "bigserial"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlTable_Owner(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, true, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, roleName := getTestDBNames(dbSuffix)
+
+	var testTableConfig = `
+	resource "postgresql_table" "test" {
+		name     = "test_tf_table"
+		database = "%s"
+		owner    = "%s"
+
+		column {
+			name = "id"
+			type = "int"
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableOwner(dbName, roleName),
+					resource.TestCheckResourceAttr("postgresql_table.test", "owner", roleName),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, config.Username),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableOwner(dbName, config.Username),
+					resource.TestCheckResourceAttr("postgresql_table.test", "owner", config.Username),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check:  testAccCheckTableOwner(dbName, roleName),
+			},
+			// The owner changed outside of Terraform is restored.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr(dbName), fmt.Sprintf("ALTER TABLE test_tf_table OWNER TO %s", config.Username))
+				},
+				Config: fmt.Sprintf(testTableConfig, dbName, roleName),
+				Check:  testAccCheckTableOwner(dbName, roleName),
+			},
+			{
+				Config:      fmt.Sprintf(testTableConfig, dbName, "tf_tests_missing_role"),
+				ExpectError: regexp.MustCompile(`role "tf_tests_missing_role" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlTable_Tablespace(t *testing.T) {
+	tablespace, teardownTablespace := setupTestTablespace(t)
+	defer teardownTablespace()
+
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testTableConfig = `
+	resource "postgresql_table" "test" {
+		name     = "test_tf_table"
+		database = "%s"
+		%s
+
+		column {
+			name = "id"
+			type = "int"
+		}
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, ""),
+				Check:  resource.TestCheckResourceAttr("postgresql_table.test", "tablespace", "pg_default"),
+			},
+			{
+				Config: fmt.Sprintf(testTableConfig, dbName, fmt.Sprintf("tablespace = %q", tablespace)),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckTableTablespace(dbName, tablespace),
+					resource.TestCheckResourceAttr("postgresql_table.test", "tablespace", tablespace),
+				),
+			},
+			{
+				Config:      fmt.Sprintf(testTableConfig, dbName, `tablespace = "tf_tests_missing_ts"`),
+				ExpectError: regexp.MustCompile(`tablespace "tf_tests_missing_ts" does not exist`),
+			},
+		},
+	})
+}
+
+func TestNormalizeColumnType(t *testing.T) {
+	for _, tt := range []struct {
+		dataType string
+		expected string
+	}{
+		{"int", "integer"},
+		{"INT4", "integer"},
+		{"serial", "integer"},
+		{"bigserial", "bigint"},
+		{"text", "text"},
+		{"varchar(64)", "character varying(64)"},
+		{"VARCHAR (64)", "character varying(64)"},
+		{"numeric(10, 2)", "numeric(10,2)"},
+		{"decimal(10,2)", "numeric(10,2)"},
+		{"timestamptz", "timestamp with time zone"},
+		{"int[]", "integer[]"},
+		{"double precision", "double precision"},
+		{"timestamp(3)", "timestamp(3) without time zone"},
+		{"timestamptz(3)", "timestamp(3) with time zone"},
+		{"timestamp (3) with time zone", "timestamp(3) with time zone"},
+		{"time(0)", "time(0) without time zone"},
+		{"timetz(6)[]", "time(6) with time zone[]"},
+		{"float", "double precision"},
+		{"float(24)", "real"},
+		{"float(25)", "double precision"},
+	} {
+		if got := normalizeColumnType(tt.dataType); got != tt.expected {
+			t.Errorf("normalizeColumnType(%q): expected %q, got %q", tt.dataType, tt.expected, got)
+		}
+	}
+}
+
+func TestColumnTypeWidened(t *testing.T) {
+	for _, tt := range []struct {
+		oldType  string
+		newType  string
+		expected bool
+	}{
+		{"smallint", "integer", true},
+		{"smallint", "bigint", true},
+		{"integer", "bigint", true},
+		{"bigint", "integer", false},
+		{"integer", "numeric", false},
+		{"character varying(64)", "character varying(128)", true},
+		{"character varying(64)", "character varying(32)", false},
+		{"character varying(64)", "character varying", true},
+		{"character varying(64)", "text", true},
+		{"character varying", "text", true},
+		{"text", "character varying(64)", false},
+		{"numeric(10,2)", "numeric(12,2)", true},
+		{"numeric(10,2)", "numeric(12,3)", false},
+		{"numeric(10,2)", "numeric(8,2)", false},
+		{"numeric(10)", "numeric(12,0)", true},
+		{"numeric(10,2)", "numeric", true},
+		{"integer", "inet", false},
+		{"integer[]", "bigint[]", false},
+	} {
+		if got := columnTypeWidened(tt.oldType, tt.newType); got != tt.expected {
+			t.Errorf("columnTypeWidened(%q, %q): expected %t, got %t", tt.oldType, tt.newType, tt.expected, got)
+		}
+	}
+}
+
+// testAccCheckTableOwner checks the owner of the test table in pg_class.
+func testAccCheckTableOwner(dbName, expected string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var owner string
+		err = txn.QueryRow(
+			"SELECT pg_catalog.pg_get_userbyid(relowner) FROM pg_catalog.pg_class WHERE oid = 'test_tf_table'::regclass",
+		).Scan(&owner)
+		if err != nil {
+			return fmt.Errorf("could not read the owner of the test table: %v", err)
+		}
+
+		if owner != expected {
+			return fmt.Errorf("expected the table to be owned by %s, got %s", expected, owner)
+		}
+		return nil
+	}
+}
+
+// testAccCheckTableTablespace checks the tablespace of the test table in
+// pg_class.
+func testAccCheckTableTablespace(dbName, expected string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var tablespace string
+		err = txn.QueryRow(
+			"SELECT t.spcname FROM pg_catalog.pg_class c " +
+				"JOIN pg_catalog.pg_tablespace t ON t.oid = c.reltablespace " +
+				"WHERE c.oid = 'test_tf_table'::regclass",
+		).Scan(&tablespace)
+		if err != nil {
+			return fmt.Errorf("could not read the tablespace of the test table: %v", err)
+		}
+
+		if tablespace != expected {
+			return fmt.Errorf("expected the table to be in tablespace %s, got %s", expected, tablespace)
+		}
+		return nil
+	}
+}
+
+// testAccCheckTableRows checks the count returned by the query on the test
+// table.
+func testAccCheckTableRows(dbName, query string, expected int) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var count int
+		if err := txn.QueryRow(query).Scan(&count); err != nil {
+			return fmt.Errorf("could not count the rows of the test table: %v", err)
+		}
+
+		if count != expected {
+			return fmt.Errorf("expected %d rows, got %d", expected, count)
+		}
+		return nil
+	}
+}
+
+// testAccCheckTableColumns checks the test table exists with the expected
+// columns.
+func testAccCheckTableColumns(dbName string, expected []string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		rows, err := txn.Query(
+			"SELECT column_name FROM information_schema.columns WHERE table_name = 'test_tf_table' ORDER BY ordinal_position",
+		)
+		if err != nil {
+			return fmt.Errorf("could not read the columns of the test table: %v", err)
+		}
+		defer rows.Close()
+
+		columns := []string{}
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				return fmt.Errorf("could not scan the columns of the test table: %v", err)
+			}
+			columns = append(columns, column)
+		}
+
+		if !reflect.DeepEqual(columns, expected) {
+			return fmt.Errorf("expected the columns %v, got %v", expected, columns)
+		}
+		return nil
+	}
+}
+
+func testAccCheckTableDestroy(dbName string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var exists bool
+		err = txn.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_tables WHERE tablename = 'test_tf_table')").Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("could not check test_tf_table table: %v", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Table still exists after destroy")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,268 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	typeNameAttr     = "name"
+	typeDatabaseAttr = "database"
+	typeSchemaAttr   = "schema"
+	typeValuesAttr   = "values"
+
+	// typeDefaultTimeout is the default timeout of the update of a type,
+	// which adds its values.
+	typeDefaultTimeout = 5 * time.Minute
+)
+
+func resourcePostgreSQLType() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLTypeCreate,
+		Read:   resourcePostgreSQLTypeRead,
+		Update: resourcePostgreSQLTypeUpdate,
+		Delete: resourcePostgreSQLTypeDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Update: schema.DefaultTimeout(typeDefaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			typeNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the enum type",
+			},
+			typeDatabaseAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The database to create the type in",
+			},
+			typeSchemaAttr: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     "public",
+				Description: "The schema to create the type in",
+			},
+			typeValuesAttr: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The ordered list of the values of the enum type",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLTypeCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(typeDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	if err := createEnumType(txn, d); err != nil {
+		return err
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId(generateTypeID(d))
+
+	return readEnumType(c, d)
+}
+
+func resourcePostgreSQLTypeRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return readEnumType(c, d)
+}
+
+func readEnumType(c *Client, d *schema.ResourceData) error {
+	txn, err := startTransaction(c, d.Get(typeDatabaseAttr).(string))
+	switch {
+	case isDatabaseNotFoundError(err):
+		log.Printf("[WARN] PostgreSQL database of type (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return err
+	}
+	defer txn.Rollback()
+
+	var values []string
+	query := `SELECT ARRAY(` +
+		`SELECT e.enumlabel::text FROM pg_catalog.pg_enum e WHERE e.enumtypid = t.oid ORDER BY e.enumsortorder` +
+		`)::text[] ` +
+		`FROM pg_catalog.pg_type t ` +
+		`JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace ` +
+		`WHERE n.nspname = $1 AND t.typname = $2 AND t.typtype = 'e'`
+	err = txn.QueryRow(query, d.Get(typeSchemaAttr).(string), d.Get(typeNameAttr).(string)).Scan(pq.Array(&values))
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL type (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading type: {{err}}", err)
+	}
+
+	d.Set(typeValuesAttr, values)
+	d.SetId(generateTypeID(d))
+
+	return nil
+}
+
+func resourcePostgreSQLTypeUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	ctx, cancel := operationContext(c, d, schema.TimeoutUpdate)
+	defer cancel()
+
+	if err := setEnumTypeValues(ctx, c, d); err != nil {
+		return operationError(ctx, schema.TimeoutUpdate, err)
+	}
+
+	return readEnumType(c, d)
+}
+
+func resourcePostgreSQLTypeDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	txn, err := startTransaction(c, d.Get(typeDatabaseAttr).(string))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback()
+
+	sql := fmt.Sprintf("DROP TYPE %s", typeName(d))
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error deleting type: {{err}}", err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return errwrap.Wrapf("could not commit transaction: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func createEnumType(txn *sql.Tx, d *schema.ResourceData) error {
+	values := d.Get(typeValuesAttr).([]interface{})
+	quotedValues := make([]string, 0, len(values))
+	for _, value := range values {
+		quotedValues = append(quotedValues, fmt.Sprintf("'%s'", pqQuoteLiteral(value.(string))))
+	}
+
+	sql := fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", typeName(d), strings.Join(quotedValues, ", "))
+	if _, err := txn.Exec(sql); err != nil {
+		return errwrap.Wrapf("Error creating type: {{err}}", err)
+	}
+
+	return nil
+}
+
+// setEnumTypeValues adds the new values of the enum type.  As PostgreSQL can
+// only add values to an enum type, removing or reordering values fails: the
+// type has to be recreated, which the columns using it prevent.
+func setEnumTypeValues(ctx context.Context, c *Client, d *schema.ResourceData) error {
+	if !d.HasChange(typeValuesAttr) {
+		return nil
+	}
+
+	o, n := d.GetChange(typeValuesAttr)
+	oldValues := o.([]interface{})
+	newValues := n.([]interface{})
+
+	if !isSubsequence(oldValues, newValues) {
+		// Keep the current values in the state, so the change is planned
+		// again.
+		d.Set(typeValuesAttr, oldValues)
+		return fmt.Errorf(
+			"Error updating type %s: PostgreSQL cannot remove or reorder the values of an enum type, "+
+				"recreate the type (terraform taint) to change them, which requires that no column uses it",
+			d.Id(),
+		)
+	}
+
+	existing := make(map[string]bool, len(oldValues))
+	for _, value := range oldValues {
+		existing[value.(string)] = true
+	}
+
+	for i, value := range newValues {
+		if existing[value.(string)] {
+			continue
+		}
+
+		var position string
+		if i == 0 {
+			position = fmt.Sprintf("BEFORE '%s'", pqQuoteLiteral(oldValues[0].(string)))
+		} else {
+			position = fmt.Sprintf("AFTER '%s'", pqQuoteLiteral(newValues[i-1].(string)))
+		}
+
+		sql := fmt.Sprintf("ALTER TYPE %s ADD VALUE '%s' %s", typeName(d), pqQuoteLiteral(value.(string)), position)
+		// ALTER TYPE ... ADD VALUE cannot be executed inside a transaction
+		// block before PostgreSQL 12.
+		if err := execNonTransactional(ctx, c, d.Get(typeDatabaseAttr).(string), sql); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("Error adding value %q to type: {{err}}", value), err)
+		}
+	}
+
+	return nil
+}
+
+// isSubsequence returns true if all the elements of sub are in list in the same order.
+func isSubsequence(sub, list []interface{}) bool {
+	i := 0
+	for _, elem := range list {
+		if i < len(sub) && sub[i] == elem {
+			i++
+		}
+	}
+	return i == len(sub)
+}
+
+// typeName returns the quoted schema qualified name of the type.
+func typeName(d *schema.ResourceData) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		pq.QuoteIdentifier(d.Get(typeSchemaAttr).(string)),
+		pq.QuoteIdentifier(d.Get(typeNameAttr).(string)),
+	)
+}
+
+func generateTypeID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(typeDatabaseAttr).(string),
+		d.Get(typeSchemaAttr).(string),
+		d.Get(typeNameAttr).(string),
+	}, ".")
+}
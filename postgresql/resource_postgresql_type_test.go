@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/lib/pq"
+)
+
+func TestAccPostgresqlType_Enum(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	dbName, _ := getTestDBNames(dbSuffix)
+
+	var testTypeConfig = `
+	resource "postgresql_type" "test" {
+		name     = "test_enum"
+		database = "%s"
+		values   = [%s]
+	}
+	`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckEnumTypeDestroy(dbName),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testTypeConfig, dbName, `"b", "d"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckEnumTypeValues(dbName, []string{"b", "d"}),
+					resource.TestCheckResourceAttr("postgresql_type.test", "schema", "public"),
+					resource.TestCheckResourceAttr("postgresql_type.test", "values.#", "2"),
+				),
+			},
+			// Values are added in place at their position.
+			{
+				Config: fmt.Sprintf(testTypeConfig, dbName, `"a", "b", "c", "d", "e"`),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckEnumTypeValues(dbName, []string{"a", "b", "c", "d", "e"}),
+					resource.TestCheckResourceAttr("postgresql_type.test", "values.#", "5"),
+				),
+			},
+			// Removing or reordering values fails, the type is kept.
+			{
+				Config:      fmt.Sprintf(testTypeConfig, dbName, `"e", "a"`),
+				ExpectError: regexp.MustCompile("cannot remove or reorder the values of an enum type"),
+			},
+			{
+				Config: fmt.Sprintf(testTypeConfig, dbName, `"a", "b", "c", "d", "e"`),
+				Check:  testAccCheckEnumTypeValues(dbName, []string{"a", "b", "c", "d", "e"}),
+			},
+		},
+	})
+}
+
+func TestAccPostgresqlType_DatabaseDropped(t *testing.T) {
+	dbSuffix, teardown := setupTestDatabase(t, true, false, false)
+	defer teardown()
+
+	config := getTestConfig(t)
+	dbName, _ := getTestDBNames(dbSuffix)
+	typeConfig := fmt.Sprintf(`
+	resource "postgresql_type" "test" {
+		name     = "test_enum"
+		database = "%s"
+		values   = ["a", "b"]
+	}
+	`, dbName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: typeConfig,
+				Check:  testAccCheckEnumTypeValues(dbName, []string{"a", "b"}),
+			},
+			// The type is removed from the state, and planned to be
+			// created again, once its database is dropped out-of-band.
+			{
+				PreConfig: func() {
+					dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP DATABASE %s", dbName))
+				},
+				Config:             typeConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func testAccCheckEnumTypeValues(dbName string, expected []string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var values []string
+		err = txn.QueryRow(
+			"SELECT enum_range(NULL::test_enum)::text[]",
+		).Scan(pq.Array(&values))
+		if err != nil {
+			return fmt.Errorf("could not read test_enum values: %v", err)
+		}
+
+		if !reflect.DeepEqual(values, expected) {
+			return fmt.Errorf("expected test_enum values %v, got %v", expected, values)
+		}
+		return nil
+	}
+}
+
+func testAccCheckEnumTypeDestroy(dbName string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		txn, err := startTransaction(client, dbName)
+		if err != nil {
+			return err
+		}
+		defer txn.Rollback()
+
+		var exists bool
+		err = txn.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_type WHERE typname = 'test_enum')").Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("could not check test_enum type: %v", err)
+		}
+
+		if exists {
+			return fmt.Errorf("Type still exists after destroy")
+		}
+		return nil
+	}
+}
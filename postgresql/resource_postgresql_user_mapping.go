@@ -0,0 +1,184 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/lib/pq"
+)
+
+const (
+	userMappingServerNameAttr = "server_name"
+	userMappingUserNameAttr   = "user_name"
+	userMappingOptionsAttr    = "options"
+
+	// userMappingPasswordOption is the user mapping option not read back from
+	// the catalogs.
+	userMappingPasswordOption = "password"
+)
+
+func resourcePostgreSQLUserMapping() *schema.Resource {
+	return &schema.Resource{
+		Create: resourcePostgreSQLUserMappingCreate,
+		Read:   resourcePostgreSQLUserMappingRead,
+		Update: resourcePostgreSQLUserMappingUpdate,
+		Delete: resourcePostgreSQLUserMappingDelete,
+
+		Schema: map[string]*schema.Schema{
+			userMappingServerNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the foreign server of the user mapping",
+			},
+			userMappingUserNameAttr: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The name of the role mapped to the foreign server (or PUBLIC)",
+			},
+			userMappingOptionsAttr: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The options of the user mapping, usually the user and password on the foreign server",
+			},
+		},
+	}
+}
+
+func resourcePostgreSQLUserMappingCreate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	sql := fmt.Sprintf(
+		"CREATE USER MAPPING FOR %s SERVER %s",
+		quoteRoleName(d.Get(userMappingUserNameAttr).(string)),
+		pq.QuoteIdentifier(d.Get(userMappingServerNameAttr).(string)),
+	)
+	options := d.Get(userMappingOptionsAttr).(map[string]interface{})
+	if len(options) > 0 {
+		sql += fmt.Sprintf(" OPTIONS (%s)", createOptions(options))
+	}
+
+	logSQL(sql)
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf("Error creating user mapping: {{err}}", redactError(err, sensitiveOptionValues(options)...))
+	}
+
+	d.SetId(generateUserMappingID(d))
+
+	return resourcePostgreSQLUserMappingReadImpl(d, meta)
+}
+
+func resourcePostgreSQLUserMappingRead(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.RLock()
+	defer c.catalogLock.RUnlock()
+
+	return resourcePostgreSQLUserMappingReadImpl(d, meta)
+}
+
+func resourcePostgreSQLUserMappingReadImpl(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+
+	// The PUBLIC user mapping is named "public" in pg_user_mappings.
+	userName := d.Get(userMappingUserNameAttr).(string)
+	if isPublicRole(userName) {
+		userName = "public"
+	}
+
+	// umoptions is NULL if the connection user is not allowed to see the
+	// options, in which case the configured options are kept.
+	var umOptions []string
+	var optionsVisible bool
+	query := `SELECT COALESCE(umoptions, '{}'::text[]), umoptions IS NOT NULL ` +
+		`FROM pg_catalog.pg_user_mappings ` +
+		`WHERE srvname = $1 AND usename = $2`
+	err := c.DB().QueryRow(query, d.Get(userMappingServerNameAttr).(string), userName).Scan(pq.Array(&umOptions), &optionsVisible)
+	switch {
+	case err == sql.ErrNoRows:
+		log.Printf("[WARN] PostgreSQL user mapping (%s) not found", d.Id())
+		d.SetId("")
+		return nil
+	case err != nil:
+		return errwrap.Wrapf("Error reading user mapping: {{err}}", err)
+	}
+
+	if optionsVisible {
+		options, err := parseOptions(umOptions)
+		if err != nil {
+			return errwrap.Wrapf("Error reading user mapping: {{err}}", err)
+		}
+
+		// The password is kept as configured, it is not read back to not
+		// store it from the catalogs.
+		delete(options, userMappingPasswordOption)
+		if password, ok := d.Get(userMappingOptionsAttr).(map[string]interface{})[userMappingPasswordOption]; ok {
+			options[userMappingPasswordOption] = password
+		}
+
+		d.Set(userMappingOptionsAttr, options)
+	}
+
+	d.SetId(generateUserMappingID(d))
+
+	return nil
+}
+
+func resourcePostgreSQLUserMappingUpdate(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	if d.HasChange(userMappingOptionsAttr) {
+		o, n := d.GetChange(userMappingOptionsAttr)
+		opts := alterOptions(o.(map[string]interface{}), n.(map[string]interface{}))
+		if len(opts) > 0 {
+			sql := fmt.Sprintf(
+				"ALTER USER MAPPING FOR %s SERVER %s OPTIONS (%s)",
+				quoteRoleName(d.Get(userMappingUserNameAttr).(string)),
+				pq.QuoteIdentifier(d.Get(userMappingServerNameAttr).(string)),
+				strings.Join(opts, ", "),
+			)
+			logSQL(sql)
+			if _, err := c.DB().Exec(sql); err != nil {
+				return errwrap.Wrapf("Error updating user mapping options: {{err}}", redactError(err, sensitiveOptionValues(n.(map[string]interface{}))...))
+			}
+		}
+	}
+
+	return resourcePostgreSQLUserMappingReadImpl(d, meta)
+}
+
+func resourcePostgreSQLUserMappingDelete(d *schema.ResourceData, meta interface{}) error {
+	c := meta.(*Client)
+	c.catalogLock.Lock()
+	defer c.catalogLock.Unlock()
+
+	sql := fmt.Sprintf(
+		"DROP USER MAPPING FOR %s SERVER %s",
+		quoteRoleName(d.Get(userMappingUserNameAttr).(string)),
+		pq.QuoteIdentifier(d.Get(userMappingServerNameAttr).(string)),
+	)
+	if _, err := c.DB().Exec(sql); err != nil {
+		return errwrap.Wrapf("Error deleting user mapping: {{err}}", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+func generateUserMappingID(d *schema.ResourceData) string {
+	return strings.Join([]string{
+		d.Get(userMappingUserNameAttr).(string),
+		d.Get(userMappingServerNameAttr).(string),
+	}, ".")
+}
@@ -0,0 +1,123 @@
+package postgresql
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestAccPostgresqlUserMapping_Basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckPostgresqlUserMappingDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testAccPostgresqlUserMappingConfig, "remote", "secret"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlUserMappingExists("tf_tests_user_mapping_role"),
+					testAccCheckPostgresqlUserMappingExists("public"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.mapping", "options.%", "2"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.mapping", "options.user", "remote"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.mapping", "options.password", "secret"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.public", "options.%", "0"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(testAccPostgresqlUserMappingConfig, "remote2", "secret2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckPostgresqlUserMappingExists("tf_tests_user_mapping_role"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.mapping", "options.user", "remote2"),
+					resource.TestCheckResourceAttr("postgresql_user_mapping.mapping", "options.password", "secret2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckPostgresqlUserMappingDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*Client)
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "postgresql_user_mapping" {
+			continue
+		}
+
+		exists, err := checkUserMappingExists(client, rs.Primary.Attributes["user_name"])
+		if err != nil {
+			return fmt.Errorf("Error checking user mapping %s", err)
+		}
+
+		if exists {
+			return fmt.Errorf("User mapping still exists after destroy")
+		}
+	}
+
+	return nil
+}
+
+func testAccCheckPostgresqlUserMappingExists(userName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*Client)
+
+		exists, err := checkUserMappingExists(client, userName)
+		if err != nil {
+			return fmt.Errorf("Error checking user mapping %s", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("User mapping not found")
+		}
+
+		return nil
+	}
+}
+
+func checkUserMappingExists(client *Client, userName string) (bool, error) {
+	var _rez int
+	err := client.DB().QueryRow(
+		"SELECT 1 FROM pg_catalog.pg_user_mappings WHERE srvname = 'tf_tests_mapping_server' AND lower(usename) = lower($1)",
+		userName,
+	).Scan(&_rez)
+	switch {
+	case err == sql.ErrNoRows:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("Error reading info about user mapping: %s", err)
+	}
+
+	return true, nil
+}
+
+var testAccPostgresqlUserMappingConfig = `
+resource "postgresql_extension" "postgres_fdw" {
+  name = "postgres_fdw"
+}
+
+resource "postgresql_server" "mapping_server" {
+  name     = "tf_tests_mapping_server"
+  fdw_name = "${postgresql_extension.postgres_fdw.name}"
+}
+
+resource "postgresql_role" "mapping_role" {
+  name = "tf_tests_user_mapping_role"
+}
+
+resource "postgresql_user_mapping" "mapping" {
+  server_name = "${postgresql_server.mapping_server.name}"
+  user_name   = "${postgresql_role.mapping_role.name}"
+
+  options {
+    user     = "%s"
+    password = "%s"
+  }
+}
+
+resource "postgresql_user_mapping" "public" {
+  server_name = "${postgresql_server.mapping_server.name}"
+  user_name   = "PUBLIC"
+}
+`
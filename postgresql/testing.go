@@ -42,6 +42,20 @@ func getTestConfig(t *testing.T) Config {
 	}
 }
 
+// testCheckCompatibleVersion skips the test if the PostgreSQL server does not
+// support the feature.
+func testCheckCompatibleVersion(t *testing.T, feature featureName) {
+	config := getTestConfig(t)
+	client, err := config.NewClient("postgres")
+	if err != nil {
+		t.Fatalf("could not connect to the PostgreSQL server: %v", err)
+	}
+
+	if !client.featureSupported(feature) {
+		t.Skipf("Skip test: feature %v not supported by PostgreSQL %s", feature, client.version)
+	}
+}
+
 // dbExecute is a test helper to create a pool, execute one query then close the pool
 func dbExecute(t *testing.T, dsn, query string, args ...interface{}) {
 	db, err := sql.Open("postgres", dsn)
@@ -93,6 +107,28 @@ func setupTestDatabase(t *testing.T, createDB, createRole, createTable bool) (st
 	}
 }
 
+// setupTestTablespace creates a tablespace in the directory of the server
+// named by the PGTABLESPACELOCATION env variable, which has to exist and be
+// empty, and provides the teardown function to drop it.  The test is skipped
+// if the variable is not set.
+func setupTestTablespace(t *testing.T) (string, func()) {
+	location := os.Getenv("PGTABLESPACELOCATION")
+	if location == "" {
+		t.Skip("Skip test: PGTABLESPACELOCATION not set")
+	}
+
+	config := getTestConfig(t)
+	tablespace := fmt.Sprintf("tf_tests_ts_%d", time.Now().UnixNano())
+
+	dbExecute(t, config.connStr("postgres"), fmt.Sprintf(
+		"CREATE TABLESPACE %s LOCATION '%s'", tablespace, pqQuoteLiteral(location),
+	))
+
+	return tablespace, func() {
+		dbExecute(t, config.connStr("postgres"), fmt.Sprintf("DROP TABLESPACE IF EXISTS %s", tablespace))
+	}
+}
+
 func testCheckTablePrivileges(t *testing.T, dbSuffix string, allowedPrivileges []string) error {
 	config := getTestConfig(t)
 	dbName, roleName := getTestDBNames(dbSuffix)